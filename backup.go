@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-blog/config"
+	"go-blog/pkg/content"
+	"go-blog/pkg/database"
+	service "go-blog/services"
+)
+
+const exportUsage = `Usage: blog-server export [-o file] [-include-passwords]
+       blog-server export -format hugo [-o dir]
+
+将全站数据导出为 zip 归档 (默认 blog-backup-<时间>.zip，-o - 输出到标准输出)，
+或将文章导出为 Hugo 兼容的 Markdown 文件 (默认写入 ./hugo-export/content/posts)
+`
+
+const importUsage = `Usage: blog-server import [-strategy skip|overwrite|rename] [-dry-run] [-json] <file>
+
+从 zip 归档导入全站数据
+`
+
+const importContentUsage = `Usage: blog-server import-content [-strategy skip|overwrite|rename] [-author username] [-json] <path>...
+
+导入 Hexo / Hugo 的 Markdown 文件 (目录下的 .md 文件) 或 WordPress 导出文件 (.xml)
+`
+
+// runBackup 执行 export / import / import-content 子命令，返回进程退出码
+func runBackup(command string, args []string) int {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	output := flags.String("o", "", "输出文件 (-format hugo 时为输出目录)")
+	format := flags.String("format", "backup", "导出格式: backup, hugo")
+	author := flags.String("author", "", "作者不存在时使用的用户名 (默认为管理员)")
+	includePasswords := flags.Bool("include-passwords", false, "包含用户密码哈希")
+	strategy := flags.String("strategy", service.ConflictSkip, "冲突处理策略: skip, overwrite, rename")
+	dryRun := flags.Bool("dry-run", false, "只校验并统计，不写入数据")
+	asJSON := flags.Bool("json", false, "以 JSON 输出导入结果")
+	flags.Usage = func() {
+		switch command {
+		case "export":
+			fmt.Fprint(os.Stderr, exportUsage)
+		case "import":
+			fmt.Fprint(os.Stderr, importUsage)
+		default:
+			fmt.Fprint(os.Stderr, importContentUsage)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	valid := false
+	switch command {
+	case "export":
+		valid = flags.NArg() == 0 && (*format == "backup" || *format == "hugo")
+	case "import":
+		valid = flags.NArg() == 1
+	case "import-content":
+		valid = flags.NArg() > 0
+	}
+	if !valid {
+		flags.Usage()
+		return 2
+	}
+
+	db, err := database.Open(&config.AppConfig.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to connect the database: %v\n", err)
+		return 1
+	}
+	if err := initStorage(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to init storage: %v\n", err)
+		return 1
+	}
+	switch {
+	case command == "export" && *format == "hugo":
+		return runExportHugo(service.NewContentImportService(db), *output)
+	case command == "export":
+		return runExport(service.NewBackupService(db), *output, *includePasswords)
+	case command == "import":
+		return runImport(service.NewBackupService(db), flags.Arg(0), &service.ImportOptions{Strategy: *strategy, DryRun: *dryRun}, *asJSON)
+	default:
+		return runImportContent(service.NewContentImportService(db), flags.Args(), &service.ContentImportOptions{Strategy: *strategy, Author: *author}, *asJSON)
+	}
+}
+
+func runExport(backupService service.IBackupService, output string, includePasswords bool) int {
+	if output == "" {
+		output = fmt.Sprintf("blog-backup-%s.zip", time.Now().Format("20060102-150405"))
+	}
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to create %s: %v\n", output, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := backupService.Export(w, &service.ExportOptions{IncludePasswords: includePasswords}); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Export failed: %v\n", err)
+		if output != "-" {
+			os.Remove(output)
+		}
+		return 1
+	}
+	if output != "-" {
+		fmt.Fprintf(os.Stderr, "✅ Exported to %s\n", output)
+	}
+	return 0
+}
+
+func runImport(backupService service.IBackupService, path string, opts *service.ImportOptions, asJSON bool) int {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to open %s: %v\n", path, err)
+		return 1
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to open %s: %v\n", path, err)
+		return 1
+	}
+
+	report, err := backupService.Import(f, info.Size(), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Import failed: %v\n", err)
+		return 1
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return 0
+	}
+
+	resources := make([]string, 0, len(report.Resources))
+	for name := range report.Resources {
+		resources = append(resources, name)
+	}
+	sort.Strings(resources)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tCREATED\tUPDATED\tRENAMED\tSKIPPED")
+	for _, name := range resources {
+		c := report.Resources[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", name, c.Created, c.Updated, c.Renamed, c.Skipped)
+	}
+	w.Flush()
+	for _, warning := range report.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if report.DryRun {
+		fmt.Println("✅ Dry run finished, no changes were made")
+	} else {
+		fmt.Println("✅ Import finished")
+	}
+	return 0
+}
+
+func runExportHugo(contentImportService service.IContentImportService, output string) int {
+	if output == "" {
+		output = "hugo-export"
+	}
+	n, err := contentImportService.ExportHugo(func(name string, data []byte) error {
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("unsafe export path %q", name)
+		}
+		path := filepath.Join(output, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Export failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Exported %d posts to %s\n", n, filepath.Join(output, filepath.FromSlash(content.HugoDir)))
+	return 0
+}
+
+func runImportContent(contentImportService service.IContentImportService, paths []string, opts *service.ContentImportOptions, asJSON bool) int {
+	files, err := collectContentFiles(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	report, err := contentImportService.ImportFiles(files, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Import failed: %v\n", err)
+		return 1
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESULT\tSLUG\tSOURCE\tMESSAGE")
+	for _, item := range report.Items {
+		message := item.Error
+		if message == "" {
+			message = strings.Join(item.Warnings, "; ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Result, item.Slug, item.Source, message)
+	}
+	w.Flush()
+	fmt.Printf("✅ %d created, %d updated, %d renamed, %d skipped, %d failed; %d categories and %d tags created\n",
+		report.Counts[service.ImportResultCreated], report.Counts[service.ImportResultUpdated], report.Counts[service.ImportResultRenamed],
+		report.Counts[service.ImportResultSkipped], report.Counts[service.ImportResultFailed],
+		len(report.CategoriesCreated), len(report.TagsCreated))
+	if report.Counts[service.ImportResultFailed] > 0 {
+		return 1
+	}
+	return 0
+}
+
+// collectContentFiles 读取待导入的文件，目录下递归查找 Markdown 与 XML 文件 (跳过隐藏目录与 node_modules)
+func collectContentFiles(paths []string) ([]service.ContentFile, error) {
+	var files []service.ContentFile
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			data, err := os.ReadFile(root)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, service.ContentFile{Name: filepath.Base(root), Data: data})
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".md", ".markdown", ".xml":
+			default:
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, service.ContentFile{Name: filepath.ToSlash(rel), Data: data})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -8,11 +8,13 @@ import (
 )
 
 type ServerConfig struct {
-	Port int `mapstructure:"port"`
+	Port    int    `mapstructure:"port"`
+	BaseURL string `mapstructure:"base_url"` // 站点对外访问地址，如 https://example.com (为空时根据请求推断)
 }
 
 // DatabaseConfig 存储数据库连接信息
 type DatabaseConfig struct {
+	Driver    string `mapstructure:"driver"` // mysql | sqlite | postgres，默认 mysql
 	Host      string `mapstructure:"host"`
 	Port      int    `mapstructure:"port"`
 	User      string `mapstructure:"user"`
@@ -21,20 +23,176 @@ type DatabaseConfig struct {
 	Charset   string `mapstructure:"charset"`
 	ParseTime bool   `mapstructure:"parseTime"`
 	Loc       string `mapstructure:"loc"`
+	Path      string `mapstructure:"path"`    // SQLite 数据库文件路径
+	SSLMode   string `mapstructure:"sslmode"` // PostgreSQL SSL 模式，默认 disable
+
+	Pool     DatabasePoolConfig      `mapstructure:"pool"`
+	Connect  DatabaseConnectConfig   `mapstructure:"connect"`
+	Replicas []DatabaseReplicaConfig `mapstructure:"replicas"` // 只读副本，公开的读接口优先使用
+
+	SkipMigrate bool `mapstructure:"skip_migrate"` // 启动时不自动执行迁移 (存在待执行的迁移时拒绝启动，需先执行 migrate up)
+}
+
+// DatabasePoolConfig 数据库连接池，为 0 时使用默认值
+type DatabasePoolConfig struct {
+	MaxOpenConns           int `mapstructure:"max_open_conns"`             // 最大打开连接数
+	MaxIdleConns           int `mapstructure:"max_idle_conns"`             // 最大空闲连接数
+	ConnMaxLifetimeMinutes int `mapstructure:"conn_max_lifetime_minutes"`  // 连接最长存活时间
+	ConnMaxIdleTimeMinutes int `mapstructure:"conn_max_idle_time_minutes"` // 连接最长空闲时间
+}
+
+// DatabaseConnectConfig 启动时连接数据库失败的重试策略 (指数退避)
+type DatabaseConnectConfig struct {
+	Retries           int `mapstructure:"retries"`             // 重试次数，为 0 时不重试
+	MaxBackoffSeconds int `mapstructure:"max_backoff_seconds"` // 两次重试的最长间隔
+}
+
+// DatabaseReplicaConfig 只读副本，未配置的字段沿用主库配置
+type DatabaseReplicaConfig struct {
+	Name     string `mapstructure:"name"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	Path     string `mapstructure:"path"` // SQLite 副本文件
 }
 
 type JWTConfig struct {
-	Algorithm      string `mapstructure:"algorithm"`
+	Algorithm           string            `mapstructure:"algorithm"`
+	Secret              string            `mapstructure:"secret"`
+	PrivateKeyPath      string            `mapstructure:"private_key_path"`
+	PublicKeyPath       string            `mapstructure:"public_key_path"`
+	Keys                []JWTKeyConfig    `mapstructure:"keys"`                  // 多密钥 (配置后忽略上面的单密钥配置)
+	Rotation            JWTRotationConfig `mapstructure:"rotation"`              // 自动轮换
+	AccessExpireMinutes int               `mapstructure:"access_expire_minutes"` // 访问令牌有效期
+	RefreshExpireHours  int               `mapstructure:"refresh_expire_hours"`  // 刷新令牌有效期
+}
+
+// JWTKeyConfig JWT 签名密钥，列表中最后一个带私钥的密钥用于签名
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"` // HS256 | RS256 | ES256 | EdDSA
 	Secret         string `mapstructure:"secret"`
 	PrivateKeyPath string `mapstructure:"private_key_path"`
-	PublicKeyPath  string `mapstructure:"public_key_path"`
-	ExpireHours    int    `mapstructure:"expire_hours"`
+	PublicKeyPath  string `mapstructure:"public_key_path"` // 仅配置公钥时只用于验证
+}
+
+// JWTRotationConfig JWT 密钥自动轮换
+type JWTRotationConfig struct {
+	Dir         string `mapstructure:"dir"`          // 密钥目录，为空表示不启用
+	Algorithm   string `mapstructure:"algorithm"`    // RS256 | ES256 | EdDSA
+	RotateHours int    `mapstructure:"rotate_hours"` // 轮换周期
+}
+
+// RobotsConfig robots.txt 抓取规则
+type RobotsConfig struct {
+	Disallow []string `mapstructure:"disallow"`
+}
+
+// PostConfig 文章配置
+type PostConfig struct {
+	MaxRevisions int `mapstructure:"max_revisions"` // 每篇文章保留的修订数
+}
+
+// AdminConfig 初始管理员 (仅在用户表为空时使用)，密码与密码哈希均为空时随机生成并打印到日志
+type AdminConfig struct {
+	Username     string `mapstructure:"username"`
+	Email        string `mapstructure:"email"`
+	Password     string `mapstructure:"password"`
+	PasswordHash string `mapstructure:"password_hash"` // bcrypt 哈希，优先于 password
+}
+
+// LoginConfig 登录防爆破配置
+type LoginConfig struct {
+	FreeAttempts int `mapstructure:"free_attempts"` // 连续失败超过该次数后开始指数退避
+	MaxFailures  int `mapstructure:"max_failures"`  // 达到该失败次数后锁定
+	LockMinutes  int `mapstructure:"lock_minutes"`  // 锁定时长
+}
+
+// LoginKeyConfig 登录密码加密密钥配置
+type LoginKeyConfig struct {
+	Dir            string `mapstructure:"dir"`             // 密钥持久化目录，多实例部署时应共享
+	PrivateKey     string `mapstructure:"private_key"`     // 固定私钥 (PEM)，设置后不再轮换
+	Bits           int    `mapstructure:"bits"`            // 密钥长度
+	RotateHours    int    `mapstructure:"rotate_hours"`    // 轮换周期，0 表示不轮换
+	LegacyPKCS1v15 bool   `mapstructure:"legacy_pkcs1v15"` // 兼容使用 PKCS#1 v1.5 加密的旧前端
+}
+
+// OAuthConfig 第三方登录配置
+type OAuthConfig struct {
+	Providers []OAuthProviderConfig `mapstructure:"providers"`
+}
+
+// OAuthProviderConfig 第三方登录提供方 (GitHub 或任意 OIDC 提供方)
+type OAuthProviderConfig struct {
+	Name         string   `mapstructure:"name"`
+	Type         string   `mapstructure:"type"` // github | oidc
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"` // 也可通过 OAUTH_<NAME>_CLIENT_SECRET 环境变量设置
+	Issuer       string   `mapstructure:"issuer"`        // OIDC 签发方 (自动发现端点)
+	AuthURL      string   `mapstructure:"auth_url"`
+	TokenURL     string   `mapstructure:"token_url"`
+	UserInfoURL  string   `mapstructure:"userinfo_url"`
+	RedirectURL  string   `mapstructure:"redirect_url"`
+	Scopes       []string `mapstructure:"scopes"`
+	AutoCreate   bool     `mapstructure:"auto_create"`  // 未绑定的身份自动创建用户
+	DefaultRole  string   `mapstructure:"default_role"` // 自动创建用户的角色
+}
+
+// SearchConfig 全文搜索配置
+type SearchConfig struct {
+	Backend string `mapstructure:"backend"` // memory (内存倒排索引) | mysql (FULLTEXT ngram)
+}
+
+// MediaConfig 媒体上传与存储配置
+type MediaConfig struct {
+	MaxSizeMB      int          `mapstructure:"max_size_mb"`
+	ThumbnailWidth int          `mapstructure:"thumbnail_width"`
+	MaxMegapixels  int          `mapstructure:"max_megapixels"` // 单张图片最大像素数 (百万)
+	Storage        string       `mapstructure:"storage"`        // local | s3
+	Local          LocalStorage `mapstructure:"local"`
+	S3             S3Storage    `mapstructure:"s3"`
+}
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 保留天数
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 超过天数后自动彻底删除，0 表示不自动清理
+}
+
+type LocalStorage struct {
+	Dir       string `mapstructure:"dir"`
+	URLPrefix string `mapstructure:"url_prefix"`
+}
+
+type S3Storage struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PublicURL string `mapstructure:"public_url"`
+	PathStyle bool   `mapstructure:"path_style"`
 }
 
 type Config struct {
 	Server   ServerConfig   `mapstructure:"server"`
 	Database DatabaseConfig `mapstructure:"database"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
+	Admin    AdminConfig    `mapstructure:"admin"`
+	Login    LoginConfig    `mapstructure:"login"`
+	LoginKey LoginKeyConfig `mapstructure:"login_key"`
+	OAuth    OAuthConfig    `mapstructure:"oauth"`
+	Robots   RobotsConfig   `mapstructure:"robots"`
+	Post     PostConfig     `mapstructure:"post"`
+	Search   SearchConfig   `mapstructure:"search"`
+	Media    MediaConfig    `mapstructure:"media"`
+	Audit    AuditConfig    `mapstructure:"audit"`
+	Trash    TrashConfig    `mapstructure:"trash"`
 }
 
 var AppConfig Config
@@ -67,6 +225,21 @@ func InitConfig() {
 	if publicKey := viper.GetString("JWT_PUBLIC_KEY_PATH"); publicKey != "" {
 		AppConfig.JWT.PublicKeyPath = publicKey
 	}
+	if key := viper.GetString("LOGIN_KEY_PRIVATE_KEY"); key != "" {
+		AppConfig.LoginKey.PrivateKey = key
+	}
+	for i, p := range AppConfig.OAuth.Providers {
+		key := "OAUTH_" + strings.ToUpper(strings.ReplaceAll(p.Name, "-", "_")) + "_CLIENT_SECRET"
+		if secret := viper.GetString(key); secret != "" {
+			AppConfig.OAuth.Providers[i].ClientSecret = secret
+		}
+	}
+	if pw := viper.GetString("ADMIN_PASSWORD"); pw != "" {
+		AppConfig.Admin.Password = pw
+	}
+	if hash := viper.GetString("ADMIN_PASSWORD_HASH"); hash != "" {
+		AppConfig.Admin.PasswordHash = hash
+	}
 
 	log.Println("✅ Configuration file loaded successfully!")
 }
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/rbac"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APITokenController struct {
+	APITokenService service.IAPITokenService
+}
+
+func NewAPITokenController(apiTokenService service.IAPITokenService) *APITokenController {
+	return &APITokenController{APITokenService: apiTokenService}
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=3650"` // 0 表示永不过期
+}
+
+// GetScopes 获取当前用户角色可授予的 scope
+func (ac *APITokenController) GetScopes(c *gin.Context) {
+	role := c.GetString("role")
+	list := make([]string, 0)
+	for _, scope := range rbac.Scopes() {
+		if rbac.RoleHasScope(role, scope) {
+			list = append(list, scope)
+		}
+	}
+
+	response.Success(c, list)
+}
+
+// GetTokens 获取当前用户的个人访问令牌
+func (ac *APITokenController) GetTokens(c *gin.Context) {
+	list, err := ac.APITokenService.GetTokens(c.GetString("userID"))
+	if err != nil {
+		logger.Log.Errorf("GetTokens service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch tokens: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// CreateToken 创建个人访问令牌，明文令牌仅返回一次
+func (ac *APITokenController) CreateToken(c *gin.Context) {
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("CreateToken bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	token, raw, err := ac.APITokenService.CreateToken(c.GetString("userID"), req.Name, req.Scopes, expiresIn)
+	if err != nil {
+		logger.Log.Warnf("CreateToken service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to create token: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{"token": raw, "info": token})
+}
+
+// RevokeToken 吊销个人访问令牌
+func (ac *APITokenController) RevokeToken(c *gin.Context) {
+	if err := ac.APITokenService.RevokeToken(c.GetString("userID"), c.Param("id")); err != nil {
+		logger.Log.Warnf("RevokeToken service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	AuditService service.IAuditService
+}
+
+func NewAuditController(auditService service.IAuditService) *AuditController {
+	return &AuditController{AuditService: auditService}
+}
+
+type AuditLogListRequest struct {
+	Page         int        `form:"page,default=1"`
+	PageSize     int        `form:"page_size,default=20"`
+	ActorID      string     `form:"actor_id"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resource_type"`
+	ResourceID   string     `form:"resource_id"`
+	Since        *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until        *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// GetAuditLogs 获取审计日志 (管理员)
+func (ac *AuditController) GetAuditLogs(c *gin.Context) {
+	var req AuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetAuditLogs bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := ac.AuditService.GetLogs(&service.AuditLogListReq{
+		Page:         req.Page,
+		PageSize:     req.PageSize,
+		ActorID:      req.ActorID,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Since:        req.Since,
+		Until:        req.Until,
+	})
+	if err != nil {
+		logger.Log.Errorf("GetAuditLogs service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch audit logs: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackupController struct {
+	BackupService service.IBackupService
+}
+
+func NewBackupController(backupService service.IBackupService) *BackupController {
+	return &BackupController{BackupService: backupService}
+}
+
+type ExportRequest struct {
+	IncludePasswords bool `form:"include_passwords"`
+}
+
+type ImportRequest struct {
+	Strategy string `form:"strategy,default=skip" binding:"oneof=skip overwrite rename"`
+	DryRun   bool   `form:"dry_run"`
+}
+
+// Export 导出全站数据 (zip 归档，流式下载)
+func (bc *BackupController) Export(c *gin.Context) {
+	var req ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("Export bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="blog-backup-%s.zip"`, time.Now().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+	// 响应头已发送，导出失败时只能中断响应
+	if err := bc.BackupService.Export(c.Writer, &service.ExportOptions{IncludePasswords: req.IncludePasswords}); err != nil {
+		logger.Log.Errorf("Export service error: %v", err)
+		c.Abort()
+	}
+}
+
+// Import 导入全站数据 (multipart 字段名: file)
+func (bc *BackupController) Import(c *gin.Context) {
+	var req ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Log.Warnf("Import bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Log.Warnf("Import bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("File is required: %v", err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Log.Errorf("Import open file failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	report, err := bc.BackupService.Import(file, fileHeader.Size, &service.ImportOptions{
+		Strategy:        req.Strategy,
+		DryRun:          req.DryRun,
+		DefaultAuthorID: c.GetString("userID"),
+	})
+	if err != nil {
+		logger.Log.Errorf("Import service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to import: %v", err))
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -47,7 +47,7 @@ func (cc *CategoryController) CreateCategory(c *gin.Context) {
 	category, err := cc.CategoryService.CreateCategory(req.Name, req.Slug)
 	if err != nil {
 		logger.Log.Errorf("CreateCategory service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		response.Error(c, saveErrorStatus(err), err.Error())
 		return
 	}
 
@@ -66,7 +66,7 @@ func (cc *CategoryController) UpdateCategory(c *gin.Context) {
 
 	if err := cc.CategoryService.UpdateCategory(id, req.Name, req.Slug); err != nil {
 		logger.Log.Errorf("UpdateCategory service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update category: %v", err))
+		response.Error(c, saveErrorStatus(err), fmt.Sprintf("Failed to update category: %v", err))
 		return
 	}
 
@@ -84,3 +84,37 @@ func (cc *CategoryController) DeleteCategory(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// GetCategoryTrash 获取回收站中的分类
+func (cc *CategoryController) GetCategoryTrash(c *gin.Context) {
+	list, err := cc.CategoryService.GetTrash()
+	if err != nil {
+		logger.Log.Errorf("GetCategoryTrash service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch trash: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// RestoreCategory 从回收站恢复分类
+func (cc *CategoryController) RestoreCategory(c *gin.Context) {
+	if err := cc.CategoryService.RestoreCategory(c.Param("id")); err != nil {
+		logger.Log.Warnf("RestoreCategory service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to restore category: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// PurgeCategory 彻底删除回收站中的分类
+func (cc *CategoryController) PurgeCategory(c *gin.Context) {
+	if err := cc.CategoryService.PurgeCategory(c.Param("id")); err != nil {
+		logger.Log.Warnf("PurgeCategory service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to purge category: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	CommentService service.ICommentService
+}
+
+func NewCommentController(commentService service.ICommentService) *CommentController {
+	return &CommentController{CommentService: commentService}
+}
+
+type CreateCommentRequest struct {
+	ParentID    string `json:"parent_id"`
+	AuthorName  string `json:"author_name" binding:"required,max=50"`
+	AuthorEmail string `json:"author_email" binding:"required,email,max=100"`
+	AuthorURL   string `json:"author_url" binding:"omitempty,url,max=255"`
+	Content     string `json:"content" binding:"required,max=5000"`
+}
+
+type CommentListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+	PostID   string `form:"post_id"`
+	Status   string `form:"status"`
+}
+
+// CreateComment 发表评论
+func (cc *CommentController) CreateComment(c *gin.Context) {
+	slug := c.Param("slug")
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("CreateComment bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	comment := &model.Comment{
+		AuthorName:  req.AuthorName,
+		AuthorEmail: req.AuthorEmail,
+		AuthorURL:   req.AuthorURL,
+		Content:     req.Content,
+		IP:          c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+	}
+	if req.ParentID != "" {
+		comment.ParentID = &req.ParentID
+	}
+
+	if err := cc.CommentService.CreateComment(slug, comment); err != nil {
+		logger.Log.Errorf("CreateComment service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to create comment: %v", err))
+		return
+	}
+
+	// 评论需审核后才会公开展示
+	response.Success(c, gin.H{"id": comment.ID, "status": comment.Status})
+}
+
+// GetPostComments 获取文章已审核评论
+func (cc *CommentController) GetPostComments(c *gin.Context) {
+	slug := c.Param("slug")
+	list, err := cc.CommentService.GetApprovedComments(slug)
+	if err != nil {
+		logger.Log.Warnf("GetPostComments service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// GetCommentList 获取评论列表 (后台审核)
+func (cc *CommentController) GetCommentList(c *gin.Context) {
+	var req CommentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetCommentList bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := cc.CommentService.GetCommentList(&service.CommentListReq{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		PostID:   req.PostID,
+		Status:   req.Status,
+	})
+	if err != nil {
+		logger.Log.Errorf("GetCommentList service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch comments: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
+
+// ApproveComment 审核通过
+func (cc *CommentController) ApproveComment(c *gin.Context) {
+	cc.updateStatus(c, model.CommentStatusApproved)
+}
+
+// RejectComment 审核拒绝
+func (cc *CommentController) RejectComment(c *gin.Context) {
+	cc.updateStatus(c, model.CommentStatusRejected)
+}
+
+// SpamComment 标记为垃圾评论
+func (cc *CommentController) SpamComment(c *gin.Context) {
+	cc.updateStatus(c, model.CommentStatusSpam)
+}
+
+func (cc *CommentController) updateStatus(c *gin.Context, status string) {
+	id := c.Param("id")
+	if err := cc.CommentService.UpdateCommentStatus(id, status); err != nil {
+		logger.Log.Errorf("UpdateCommentStatus service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update comment: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DeleteComment 删除评论
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	id := c.Param("id")
+	if err := cc.CommentService.DeleteComment(id); err != nil {
+		logger.Log.Errorf("DeleteComment service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to delete comment: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"archive/zip"
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContentImportController struct {
+	ContentImportService service.IContentImportService
+}
+
+func NewContentImportController(contentImportService service.IContentImportService) *ContentImportController {
+	return &ContentImportController{ContentImportService: contentImportService}
+}
+
+type ContentImportRequest struct {
+	Strategy string `form:"strategy,default=skip" binding:"oneof=skip overwrite rename"`
+}
+
+// ImportContent 导入 Hexo / Hugo Markdown 文件或 WordPress 导出文件 (multipart 字段名: files，可多个)
+func (cc *ContentImportController) ImportContent(c *gin.Context) {
+	var req ContentImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logger.Log.Warnf("ImportContent bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid parameters: %v", err))
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		logger.Log.Warnf("ImportContent bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, "Files are required")
+		return
+	}
+
+	files := make([]service.ContentFile, 0, len(form.File["files"]))
+	for _, fileHeader := range form.File["files"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			logger.Log.Errorf("ImportContent open file failed: %v", err)
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			logger.Log.Errorf("ImportContent read file failed: %v", err)
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		files = append(files, service.ContentFile{Name: fileHeader.Filename, Data: data})
+	}
+
+	report, err := cc.ContentImportService.ImportFiles(files, &service.ContentImportOptions{
+		Strategy: req.Strategy,
+		AuthorID: c.GetString("userID"),
+	})
+	if err != nil {
+		logger.Log.Errorf("ImportContent service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to import: %v", err))
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// ExportHugo 导出 Hugo 兼容的 Markdown 文件 (zip 归档，流式下载)
+func (cc *ContentImportController) ExportHugo(c *gin.Context) {
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="hugo-export-%s.zip"`, time.Now().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	_, err := cc.ContentImportService.ExportHugo(func(name string, data []byte) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	// 响应头已发送，导出失败时只能中断响应
+	if err != nil {
+		logger.Log.Errorf("ExportHugo service error: %v", err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/config"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedController struct {
+	FeedService service.IFeedService
+}
+
+func NewFeedController(feedService service.IFeedService) *FeedController {
+	return &FeedController{FeedService: feedService}
+}
+
+const (
+	formatRSS  = "rss"
+	formatAtom = "atom"
+)
+
+// RSS 全站 RSS 2.0 订阅
+func (fc *FeedController) RSS(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{}, formatRSS)
+}
+
+// Atom 全站 Atom 订阅
+func (fc *FeedController) Atom(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{}, formatAtom)
+}
+
+// CategoryRSS 分类 RSS 2.0 订阅
+func (fc *FeedController) CategoryRSS(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{CategorySlug: c.Param("slug")}, formatRSS)
+}
+
+// CategoryAtom 分类 Atom 订阅
+func (fc *FeedController) CategoryAtom(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{CategorySlug: c.Param("slug")}, formatAtom)
+}
+
+// TagRSS 标签 RSS 2.0 订阅
+func (fc *FeedController) TagRSS(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{TagSlug: c.Param("slug")}, formatRSS)
+}
+
+// TagAtom 标签 Atom 订阅
+func (fc *FeedController) TagAtom(c *gin.Context) {
+	fc.serve(c, &service.FeedReq{TagSlug: c.Param("slug")}, formatAtom)
+}
+
+func (fc *FeedController) serve(c *gin.Context, req *service.FeedReq, format string) {
+	req.BaseURL = siteBaseURL(c)
+	req.FeedURL = req.BaseURL + c.Request.URL.Path
+
+	ch, err := fc.FeedService.GetFeed(req)
+	if err != nil {
+		logger.Log.Warnf("GetFeed service error: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Failed to build feed: %v", err))
+		return
+	}
+
+	// 条件请求：内容未变化时返回 304
+	if !ch.Updated.IsZero() {
+		lastModified := ch.Updated.UTC().Truncate(time.Second)
+		if notModifiedSince(c, lastModified) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == formatAtom {
+		body, err = ch.Atom()
+		contentType = "application/atom+xml; charset=utf-8"
+	} else {
+		body, err = ch.RSS()
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+	if err != nil {
+		logger.Log.Errorf("Render feed failed: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to render feed: %v", err))
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModifiedSince 判断 If-Modified-Since 是否不早于 lastModified
+func notModifiedSince(c *gin.Context, lastModified time.Time) bool {
+	ims := c.GetHeader("If-Modified-Since")
+	if ims == "" {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(t)
+}
+
+// siteBaseURL 获取站点根地址，优先使用配置，否则根据请求推断
+func siteBaseURL(c *gin.Context) string {
+	if base := config.AppConfig.Server.BaseURL; base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
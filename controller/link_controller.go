@@ -100,3 +100,37 @@ func (lc *LinkController) DeleteLink(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// GetLinkTrash 获取回收站中的链接
+func (lc *LinkController) GetLinkTrash(c *gin.Context) {
+	list, err := lc.LinkService.GetTrash()
+	if err != nil {
+		logger.Log.Errorf("GetLinkTrash service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch trash: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// RestoreLink 从回收站恢复链接
+func (lc *LinkController) RestoreLink(c *gin.Context) {
+	if err := lc.LinkService.RestoreLink(c.Param("id")); err != nil {
+		logger.Log.Warnf("RestoreLink service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to restore link: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// PurgeLink 彻底删除回收站中的链接
+func (lc *LinkController) PurgeLink(c *gin.Context) {
+	if err := lc.LinkService.PurgeLink(c.Param("id")); err != nil {
+		logger.Log.Warnf("PurgeLink service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to purge link: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaController struct {
+	MediaService service.IMediaService
+}
+
+func NewMediaController(mediaService service.IMediaService) *MediaController {
+	return &MediaController{MediaService: mediaService}
+}
+
+type MediaListRequest struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=20"`
+}
+
+// UploadMedia 上传图片 (multipart 字段名: file)
+func (mc *MediaController) UploadMedia(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Log.Warnf("UploadMedia bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("File is required: %v", err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Log.Errorf("UploadMedia open file failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	media, err := mc.MediaService.UploadMedia(fileHeader.Filename, file, c.GetString("userID"))
+	if err != nil {
+		logger.Log.Errorf("UploadMedia service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to upload media: %v", err))
+		return
+	}
+
+	response.Success(c, media)
+}
+
+// GetMediaList 获取媒体列表
+func (mc *MediaController) GetMediaList(c *gin.Context) {
+	var req MediaListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetMediaList bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := mc.MediaService.GetMediaList(req.Page, req.PageSize)
+	if err != nil {
+		logger.Log.Errorf("GetMediaList service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch media: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
+
+// DeleteMedia 删除媒体文件
+func (mc *MediaController) DeleteMedia(c *gin.Context) {
+	id := c.Param("id")
+	if err := mc.MediaService.DeleteMedia(id); err != nil {
+		logger.Log.Errorf("DeleteMedia service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to delete media: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
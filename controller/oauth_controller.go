@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"fmt"
+	jwtpkg "go-blog/pkg/jwt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookie 将授权请求绑定到发起登录的浏览器，防止登录 CSRF
+const (
+	oauthStateCookie = "oauth_state"
+	oauthCookiePath  = "/api/auth/oauth"
+)
+
+type OAuthController struct {
+	OAuthService   service.IOAuthService
+	SessionService service.ISessionService
+}
+
+func NewOAuthController(oauthService service.IOAuthService, sessionService service.ISessionService) *OAuthController {
+	return &OAuthController{OAuthService: oauthService, SessionService: sessionService}
+}
+
+// GetProviders 获取可用的第三方登录提供方
+func (oc *OAuthController) GetProviders(c *gin.Context) {
+	response.Success(c, oc.OAuthService.GetProviders())
+}
+
+// Login 跳转到第三方授权页 (redirect 为登录完成后跳转的前端路径)
+func (oc *OAuthController) Login(c *gin.Context) {
+	provider := c.Param("provider")
+	start, err := oc.OAuthService.StartLogin(provider, oauthCallbackURL(c, provider), c.Query("redirect"), "")
+	if err != nil {
+		logger.Log.Warnf("OAuth login service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to start login: %v", err))
+		return
+	}
+
+	setOAuthStateCookie(c, start.State)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// Link 为当前用户绑定第三方身份，返回授权地址由前端跳转
+func (oc *OAuthController) Link(c *gin.Context) {
+	provider := c.Param("provider")
+	start, err := oc.OAuthService.StartLogin(provider, oauthCallbackURL(c, provider), c.Query("redirect"), c.GetString("userID"))
+	if err != nil {
+		logger.Log.Warnf("OAuth link service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to start login: %v", err))
+		return
+	}
+
+	setOAuthStateCookie(c, start.State)
+	response.Success(c, start)
+}
+
+// Callback 第三方授权回调：签发与密码登录相同的令牌 (开启两步验证时返回挑战令牌)
+// 发起登录时指定了 redirect 则跳转到该地址，结果放在 URL fragment 中；否则返回 JSON
+func (oc *OAuthController) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oauthStateCookie)
+	c.SetCookie(oauthStateCookie, "", -1, oauthCookiePath, "", false, true)
+
+	if e := c.Query("error"); e != "" {
+		logger.Log.Warnf("OAuth callback error from provider: %s %s", e, c.Query("error_description"))
+		response.Error(c, http.StatusUnauthorized, fmt.Sprintf("Authorization failed: %s", e))
+		return
+	}
+	if state == "" || cookie != state {
+		response.Error(c, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	user, redirect, err := oc.OAuthService.Callback(provider, state, c.Query("code"))
+	if err != nil {
+		logger.Log.Warnf("OAuth callback service error: provider=%s: %v", provider, err)
+		oauthResult(c, redirect, http.StatusUnauthorized, nil, fmt.Sprintf("Login failed: %v", err))
+		return
+	}
+
+	if user.TOTPEnabled {
+		challenge, err := jwtpkg.GenerateChallengeToken(user.ID)
+		if err != nil {
+			logger.Log.Errorf("Generate challenge token failed: %v", err)
+			oauthResult(c, redirect, http.StatusInternalServerError, nil, "Failed to generate token")
+			return
+		}
+		oauthResult(c, redirect, http.StatusOK, map[string]string{
+			"two_factor_required": "true",
+			"challenge_token":     challenge,
+			"expires_in":          strconv.FormatInt(int64(jwtpkg.ChallengeTTL.Seconds()), 10),
+		}, "")
+		return
+	}
+
+	tokens, err := oc.SessionService.CreateSession(user, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logger.Log.Errorf("Generate token failed: %v", err)
+		oauthResult(c, redirect, http.StatusInternalServerError, nil, "Failed to generate token")
+		return
+	}
+	oauthResult(c, redirect, http.StatusOK, map[string]string{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    strconv.FormatInt(tokens.ExpiresIn, 10),
+	}, "")
+}
+
+// GetIdentities 获取当前用户绑定的第三方身份
+func (oc *OAuthController) GetIdentities(c *gin.Context) {
+	list, err := oc.OAuthService.GetIdentities(c.GetString("userID"))
+	if err != nil {
+		logger.Log.Errorf("GetIdentities service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch identities: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Unlink 解除绑定第三方身份
+func (oc *OAuthController) Unlink(c *gin.Context) {
+	if err := oc.OAuthService.UnlinkIdentity(c.GetString("userID"), c.Param("id")); err != nil {
+		logger.Log.Warnf("UnlinkIdentity service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// oauthCallbackURL 提交给提供方的回调地址
+func oauthCallbackURL(c *gin.Context, provider string) string {
+	return siteBaseURL(c) + oauthCookiePath + "/" + url.PathEscape(provider) + "/callback"
+}
+
+func setOAuthStateCookie(c *gin.Context, state string) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	// 回调是从提供方跳转回来的顶层 GET 请求，需使用 Lax
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, 10*60, oauthCookiePath, "", secure, true)
+}
+
+// oauthResult 有 redirect 时跳转到前端并通过 fragment 传递结果 (不会发送到服务器日志)，否则返回 JSON
+func oauthResult(c *gin.Context, redirect string, status int, data map[string]string, errMsg string) {
+	if redirect == "" {
+		if errMsg != "" {
+			response.Error(c, status, errMsg)
+			return
+		}
+		response.Success(c, data)
+		return
+	}
+
+	values := url.Values{}
+	for k, v := range data {
+		values.Set(k, v)
+	}
+	if errMsg != "" {
+		values.Set("error", errMsg)
+	}
+	c.Redirect(http.StatusFound, redirect+"#"+values.Encode())
+}
@@ -2,11 +2,14 @@ package controller
 
 import (
 	"fmt"
+	"go-blog/middleware"
 	"go-blog/model"
 	"go-blog/pkg/logger"
+	"go-blog/pkg/rbac"
 	"go-blog/pkg/response"
 	service "go-blog/services"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,34 +23,44 @@ func NewPostController(postService service.IPostService) *PostController {
 }
 
 type CreatePostRequest struct {
-	Title       string   `json:"title" binding:"required"`
-	Content     string   `json:"content" binding:"required"`
-	Summary     string   `json:"summary"`
-	Slug        string   `json:"slug" binding:"required"`
-	Cover       string   `json:"cover"`
-	CategoryID  string   `json:"category_id" binding:"required"`
-	TagIDs      []string `json:"tag_ids"`
-	IsPublished *bool    `json:"is_published"`
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required"`
+	Summary     string     `json:"summary"`
+	Slug        string     `json:"slug" binding:"required"`
+	Cover       string     `json:"cover"`
+	CategoryID  string     `json:"category_id" binding:"required"`
+	TagIDs      []string   `json:"tag_ids"`
+	IsPublished *bool      `json:"is_published"`
+	Status      string     `json:"status"`     // draft / scheduled / published / archived，优先于 is_published
+	PublishAt   *time.Time `json:"publish_at"` // 定时发布时间 (RFC3339)
 }
 
 type UpdatePostRequest struct {
-	Title       string   `json:"title"`
-	Content     string   `json:"content"`
-	Summary     *string  `json:"summary"` // 使用指针，允许清空
-	Slug        string   `json:"slug"`
-	Cover       *string  `json:"cover"` // 使用指针，允许清空
-	CategoryID  string   `json:"category_id"`
-	TagIDs      []string `json:"tag_ids"` // 空数组，表示清空标签
-	IsPublished *bool    `json:"is_published"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Summary     *string    `json:"summary"` // 使用指针，允许清空
+	Slug        string     `json:"slug"`
+	Cover       *string    `json:"cover"` // 使用指针，允许清空
+	CategoryID  string     `json:"category_id"`
+	TagIDs      []string   `json:"tag_ids"` // 空数组，表示清空标签
+	IsPublished *bool      `json:"is_published"`
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publish_at"`
 }
 
 type PostListRequest struct {
-	Page        int    `form:"page,default=1"`
-	PageSize    int    `form:"page_size,default=10"`
-	Keyword     string `form:"keyword"`
-	CategoryID  string `form:"category_id"`
-	TagID       string `form:"tag_id"`
-	IsPublished *bool  `form:"is_published"`
+	Page       int    `form:"page,default=1"`
+	PageSize   int    `form:"page_size,default=10"`
+	Keyword    string `form:"keyword"`
+	CategoryID string `form:"category_id"`
+	TagID      string `form:"tag_id"`
+}
+
+// ManagePostListRequest 后台文章列表，可按状态与作者筛选
+type ManagePostListRequest struct {
+	PostListRequest
+	Status   string `form:"status" binding:"omitempty,oneof=draft scheduled published archived"`
+	AuthorID string `form:"author_id"`
 }
 
 // CreatePost 创建文章
@@ -76,11 +89,13 @@ func (pc *PostController) CreatePost(c *gin.Context) {
 		CategoryID:  req.CategoryID,
 		AuthorID:    userID,
 		IsPublished: &isPublished,
+		Status:      req.Status,
+		PublishAt:   req.PublishAt,
 	}
 
 	if err := pc.PostService.CreatePost(post, req.TagIDs); err != nil {
 		logger.Log.Errorf("CreatePost service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to create post: %v", err))
+		response.Error(c, saveErrorStatus(err), fmt.Sprintf("Failed to create post: %v", err))
 		return
 	}
 
@@ -106,9 +121,7 @@ func (pc *PostController) UpdatePost(c *gin.Context) {
 
 	// 鉴权
 	currentUserID := c.GetString("userID")
-	currentUserRole := c.GetString("role")
-
-	if post.AuthorID != currentUserID && currentUserRole != "admin" {
+	if !canManagePost(c, post) {
 		logger.Log.Errorf("Permission denied")
 		response.Error(c, http.StatusForbidden, "Permission denied")
 		return
@@ -133,20 +146,27 @@ func (pc *PostController) UpdatePost(c *gin.Context) {
 	if req.Cover != nil {
 		post.Cover = *req.Cover
 	}
-	if req.IsPublished != nil {
+	if req.PublishAt != nil {
+		post.PublishAt = req.PublishAt
+	}
+	if req.Status != "" {
+		post.Status = req.Status
+	} else if req.IsPublished != nil {
+		// 仅传 is_published 时由其推导状态
+		post.Status = ""
 		post.IsPublished = req.IsPublished
 	}
 
-	if err := pc.PostService.UpdatePost(post, req.TagIDs); err != nil {
+	if err := pc.PostService.UpdatePost(post, req.TagIDs, currentUserID); err != nil {
 		logger.Log.Warnf("UpdatePost service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update post: %v", err))
+		response.Error(c, saveErrorStatus(err), fmt.Sprintf("Failed to update post: %v", err))
 		return
 	}
 
 	response.Success(c, nil)
 }
 
-// GetPostList 获取文章列表
+// GetPostList 获取文章列表 (公开接口只返回已发布的文章)
 func (pc *PostController) GetPostList(c *gin.Context) {
 	var req PostListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -156,12 +176,12 @@ func (pc *PostController) GetPostList(c *gin.Context) {
 	}
 
 	serviceReq := &service.PostListReq{
-		Page:        req.Page,
-		PageSize:    req.PageSize,
-		KeyWord:     req.Keyword,
-		CategoryID:  req.CategoryID,
-		TagID:       req.TagID,
-		IsPublished: req.IsPublished,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		KeyWord:    req.Keyword,
+		CategoryID: req.CategoryID,
+		TagID:      req.TagID,
+		Status:     model.PostStatusPublished,
 	}
 
 	posts, total, err := pc.PostService.GetPostList(serviceReq)
@@ -179,6 +199,45 @@ func (pc *PostController) GetPostList(c *gin.Context) {
 	})
 }
 
+// GetManagedPostList 后台文章列表 (含草稿、定时发布与已归档的文章；无 post:manage 权限时只返回自己的文章)
+func (pc *PostController) GetManagedPostList(c *gin.Context) {
+	var req ManagePostListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetManagedPostList bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	authorID := req.AuthorID
+	if !middleware.HasPermission(c, rbac.PostManage) {
+		authorID = c.GetString("userID")
+	}
+	serviceReq := &service.PostListReq{
+		Page:             req.Page,
+		PageSize:         req.PageSize,
+		KeyWord:          req.Keyword,
+		CategoryID:       req.CategoryID,
+		TagID:            req.TagID,
+		Status:           req.Status,
+		AuthorID:         authorID,
+		IncludeScheduled: true,
+	}
+
+	posts, total, err := pc.PostService.GetPostList(serviceReq)
+	if err != nil {
+		logger.Log.Errorf("GetManagedPostList service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch posts: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  posts,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
+
 // GetPostDetail 获取详情
 func (pc *PostController) GetPostDetail(c *gin.Context) {
 	slug := c.Param("slug") // 使用 slug 获取
@@ -200,6 +259,20 @@ func (pc *PostController) GetPostDetail(c *gin.Context) {
 // DeletePost 删除文章
 func (pc *PostController) DeletePost(c *gin.Context) {
 	id := c.Param("id")
+	post, err := pc.PostService.GetPostByID(id)
+	if err != nil {
+		logger.Log.Warnf("DeletePost post not found: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Post not found: %v", err))
+		return
+	}
+
+	// 鉴权
+	if !canManagePost(c, post) {
+		logger.Log.Errorf("Permission denied")
+		response.Error(c, http.StatusForbidden, "Permission denied")
+		return
+	}
+
 	if err := pc.PostService.DeletePost(id); err != nil {
 		logger.Log.Errorf("DeletePost service error: %v", err)
 		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to delete post: %v", err))
@@ -208,3 +281,66 @@ func (pc *PostController) DeletePost(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// GetPostTrash 获取回收站中的文章
+func (pc *PostController) GetPostTrash(c *gin.Context) {
+	list, err := pc.PostService.GetTrash()
+	if err != nil {
+		logger.Log.Errorf("GetPostTrash service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch trash: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// RestorePost 从回收站恢复文章
+func (pc *PostController) RestorePost(c *gin.Context) {
+	id := c.Param("id")
+	post, err := pc.PostService.GetTrashedPost(id)
+	if err != nil {
+		logger.Log.Warnf("RestorePost post not found: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Post not found: %v", err))
+		return
+	}
+	if !canManagePost(c, post) {
+		response.Error(c, http.StatusForbidden, "Permission denied")
+		return
+	}
+
+	if err := pc.PostService.RestorePost(id); err != nil {
+		logger.Log.Warnf("RestorePost service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to restore post: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// PurgePost 彻底删除回收站中的文章
+func (pc *PostController) PurgePost(c *gin.Context) {
+	id := c.Param("id")
+	post, err := pc.PostService.GetTrashedPost(id)
+	if err != nil {
+		logger.Log.Warnf("PurgePost post not found: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Post not found: %v", err))
+		return
+	}
+	if !canManagePost(c, post) {
+		response.Error(c, http.StatusForbidden, "Permission denied")
+		return
+	}
+
+	if err := pc.PostService.PurgePost(id); err != nil {
+		logger.Log.Errorf("PurgePost service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to purge post: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// canManagePost 作者可管理自己的文章，拥有 post:manage 权限的角色可管理全部文章
+func canManagePost(c *gin.Context, post *model.Post) bool {
+	return post.AuthorID == c.GetString("userID") || middleware.HasPermission(c, rbac.PostManage)
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionController struct {
+	RevisionService service.IRevisionService
+	PostService     service.IPostService
+}
+
+func NewRevisionController(revisionService service.IRevisionService, postService service.IPostService) *RevisionController {
+	return &RevisionController{RevisionService: revisionService, PostService: postService}
+}
+
+type RevisionDiffRequest struct {
+	From int `form:"from" binding:"min=0"`
+	To   int `form:"to" binding:"min=0"` // 0 表示文章当前内容
+}
+
+// postID 修订路由与 /api/posts/:slug 共用路由树，参数名为 slug，值为文章 ID
+func postID(c *gin.Context) string {
+	return c.Param("slug")
+}
+
+// GetRevisions 获取文章修订列表
+func (rc *RevisionController) GetRevisions(c *gin.Context) {
+	list, err := rc.RevisionService.GetRevisions(postID(c))
+	if err != nil {
+		logger.Log.Warnf("GetRevisions service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// GetRevision 获取单个修订详情
+func (rc *RevisionController) GetRevision(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	rev, err := rc.RevisionService.GetRevision(postID(c), version)
+	if err != nil {
+		logger.Log.Warnf("GetRevision service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, rev)
+}
+
+// DiffRevisions 比较两个修订
+func (rc *RevisionController) DiffRevisions(c *gin.Context) {
+	var req RevisionDiffRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("DiffRevisions bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	result, err := rc.RevisionService.DiffRevisions(postID(c), req.From, req.To)
+	if err != nil {
+		logger.Log.Warnf("DiffRevisions service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// RestoreRevision 恢复到指定修订
+func (rc *RevisionController) RestoreRevision(c *gin.Context) {
+	id := postID(c)
+	version, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	post, err := rc.PostService.GetPostByID(id)
+	if err != nil {
+		logger.Log.Warnf("RestoreRevision post not found: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Post not found: %v", err))
+		return
+	}
+
+	// 鉴权：与更新文章一致
+	if !canManagePost(c, post) {
+		logger.Log.Errorf("Permission denied")
+		response.Error(c, http.StatusForbidden, "Permission denied")
+		return
+	}
+
+	if err := rc.RevisionService.RestoreRevision(id, version, c.GetString("userID")); err != nil {
+		logger.Log.Warnf("RestoreRevision service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to restore revision: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchController struct {
+	SearchService service.ISearchService
+}
+
+func NewSearchController(searchService service.ISearchService) *SearchController {
+	return &SearchController{SearchService: searchService}
+}
+
+type SearchRequest struct {
+	Query    string `form:"q" binding:"required"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=10"`
+}
+
+// Search 全文搜索
+func (sc *SearchController) Search(c *gin.Context) {
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("Search bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := sc.SearchService.Search(&service.SearchReq{
+		Query:    req.Query,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		logger.Log.Errorf("Search service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to search: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/config"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapController struct {
+	SitemapService service.ISitemapService
+}
+
+func NewSitemapController(sitemapService service.ISitemapService) *SitemapController {
+	return &SitemapController{SitemapService: sitemapService}
+}
+
+// Sitemap 获取 sitemap 入口 (URL 过多时为 sitemap index)
+func (sc *SitemapController) Sitemap(c *gin.Context) {
+	sc.serve(c, 0)
+}
+
+// SitemapPage 获取拆分后的子 sitemap，如 /sitemaps/1.xml
+func (sc *SitemapController) SitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || page < 1 {
+		response.Error(c, http.StatusNotFound, "Sitemap not found")
+		return
+	}
+	sc.serve(c, page)
+}
+
+func (sc *SitemapController) serve(c *gin.Context, page int) {
+	body, err := sc.SitemapService.GetSitemap(siteBaseURL(c), page)
+	if err != nil {
+		logger.Log.Warnf("GetSitemap service error: %v", err)
+		response.Error(c, http.StatusNotFound, fmt.Sprintf("Failed to build sitemap: %v", err))
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// Robots 获取 robots.txt
+func (sc *SitemapController) Robots(c *gin.Context) {
+	robots := sc.SitemapService.GetRobots(siteBaseURL(c), config.AppConfig.Robots.Disallow)
+	c.String(http.StatusOK, robots)
+}
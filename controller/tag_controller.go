@@ -47,7 +47,7 @@ func (tc *TagController) CreateTag(c *gin.Context) {
 	tag, err := tc.TagService.CreateTag(req.Name, req.Slug)
 	if err != nil {
 		logger.Log.Errorf("CreateTag service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		response.Error(c, saveErrorStatus(err), err.Error())
 		return
 	}
 
@@ -66,7 +66,7 @@ func (tc *TagController) UpdateTag(c *gin.Context) {
 
 	if err := tc.TagService.UpdateTag(id, req.Name, req.Slug); err != nil {
 		logger.Log.Errorf("UpdateTag service error: %v", err)
-		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update tag: %v", err))
+		response.Error(c, saveErrorStatus(err), fmt.Sprintf("Failed to update tag: %v", err))
 		return
 	}
 
@@ -84,3 +84,37 @@ func (tc *TagController) DeleteTag(c *gin.Context) {
 
 	response.Success(c, nil)
 }
+
+// GetTagTrash 获取回收站中的标签
+func (tc *TagController) GetTagTrash(c *gin.Context) {
+	list, err := tc.TagService.GetTrash()
+	if err != nil {
+		logger.Log.Errorf("GetTagTrash service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch trash: %v", err))
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// RestoreTag 从回收站恢复标签
+func (tc *TagController) RestoreTag(c *gin.Context) {
+	if err := tc.TagService.RestoreTag(c.Param("id")); err != nil {
+		logger.Log.Warnf("RestoreTag service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to restore tag: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// PurgeTag 彻底删除回收站中的标签
+func (tc *TagController) PurgeTag(c *gin.Context) {
+	if err := tc.TagService.PurgeTag(c.Param("id")); err != nil {
+		logger.Log.Warnf("PurgeTag service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to purge tag: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"errors"
+	service "go-blog/services"
+	"net/http"
+)
+
+// saveErrorStatus 保存失败的状态码：唯一字段被回收站中的记录占用时返回 409
+func saveErrorStatus(err error) int {
+	if errors.Is(err, service.ErrTrashConflict) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/response"
+	service "go-blog/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorController struct {
+	TwoFactorService service.ITwoFactorService
+}
+
+func NewTwoFactorController(twoFactorService service.ITwoFactorService) *TwoFactorController {
+	return &TwoFactorController{TwoFactorService: twoFactorService}
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Setup 生成两步验证密钥与 otpauth 地址
+func (tc *TwoFactorController) Setup(c *gin.Context) {
+	setup, err := tc.TwoFactorService.Setup(c.GetString("userID"))
+	if err != nil {
+		logger.Log.Warnf("TwoFactor setup service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to setup two-factor authentication: %v", err))
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// Enable 校验验证码并启用两步验证，返回恢复码
+func (tc *TwoFactorController) Enable(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("TwoFactor enable bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := tc.TwoFactorService.Enable(c.GetString("userID"), req.Code)
+	if err != nil {
+		logger.Log.Warnf("TwoFactor enable service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to enable two-factor authentication: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// Disable 校验验证码或恢复码后关闭两步验证
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("TwoFactor disable bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := tc.TwoFactorService.Disable(c.GetString("userID"), req.Code); err != nil {
+		logger.Log.Warnf("TwoFactor disable service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to disable two-factor authentication: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("RegenerateRecoveryCodes bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := tc.TwoFactorService.RegenerateRecoveryCodes(c.GetString("userID"), req.Code)
+	if err != nil {
+		logger.Log.Warnf("RegenerateRecoveryCodes service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to regenerate recovery codes: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
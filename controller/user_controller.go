@@ -2,11 +2,16 @@ package controller
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"go-blog/model"
 	"go-blog/pkg/crypto"
 	jwtpkg "go-blog/pkg/jwt"
 	"go-blog/pkg/logger"
+	"go-blog/pkg/rbac"
 	"go-blog/pkg/response"
 	service "go-blog/services"
 
@@ -14,17 +19,38 @@ import (
 )
 
 type UserController struct {
-	UserService service.IUserService
+	UserService       service.IUserService
+	SessionService    service.ISessionService
+	LoginGuardService service.ILoginGuardService
+	TwoFactorService  service.ITwoFactorService
 }
 
 // NewUserController 接口一个接口类型
-func NewUserController(userService service.IUserService) *UserController {
-	return &UserController{UserService: userService}
+func NewUserController(userService service.IUserService, sessionService service.ISessionService, loginGuardService service.ILoginGuardService, twoFactorService service.ITwoFactorService) *UserController {
+	return &UserController{
+		UserService:       userService,
+		SessionService:    sessionService,
+		LoginGuardService: loginGuardService,
+		TwoFactorService:  twoFactorService,
+	}
 }
 
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	KID      string `json:"kid"` // 加密所用公钥的 kid，为空时尝试当前与上一个密钥
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // 验证器中的 6 位验证码或恢复码
+}
+
+// tooManyAttempts 返回 429 并通过 Retry-After 告知需等待的秒数
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	response.Error(c, http.StatusTooManyRequests, fmt.Sprintf("Too many failed login attempts, retry after %d seconds", seconds))
 }
 
 // GetPublicKey 获取公钥接口
@@ -36,7 +62,7 @@ func (uc *UserController) GetPublicKey(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, gin.H{"public_key": pubKey})
+	response.Success(c, pubKey)
 }
 
 // Login 登录接口
@@ -48,8 +74,19 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
+	// 防爆破：处于退避 / 锁定期间直接拒绝
+	ip := c.ClientIP()
+	wait, err := uc.LoginGuardService.Check(req.Username, ip)
+	if err != nil {
+		logger.Log.Errorf("Login guard check failed: %v", err)
+	}
+	if wait > 0 {
+		tooManyAttempts(c, wait)
+		return
+	}
+
 	// 解密传入的 RSA 加密后的 Base64 字符串
-	plainPassword, err := crypto.Decrypt(req.Password)
+	plainPassword, err := crypto.Decrypt(req.Password, req.KID)
 	if err != nil {
 		logger.Log.Warnf("Decrypt password failed: %v", err)
 		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid password encryption: %v", err))
@@ -59,12 +96,84 @@ func (uc *UserController) Login(c *gin.Context) {
 	// 传入解密后的 plainPassword
 	user, err := uc.UserService.AuthenticateUser(req.Username, plainPassword)
 	if err != nil {
-		logger.Log.Errorf("Login service failed: %v", err)
+		logger.Log.Warnf("Login failed: username=%s ip=%s: %v", req.Username, ip, err)
+		if _, gerr := uc.LoginGuardService.RecordFailure(req.Username, ip, c.Request.UserAgent(), err.Error()); gerr != nil {
+			logger.Log.Errorf("Record login failure failed: %v", gerr)
+		}
 		response.Error(c, http.StatusUnauthorized, fmt.Sprintf("Invalid username or password: %v", err))
 		return
 	}
 
-	token, err := jwtpkg.GenerateToken(user.ID, user.Username)
+	// 已开启两步验证：返回短期挑战令牌，通过 /login/2fa 提交验证码后再签发正式令牌
+	if user.TOTPEnabled {
+		challenge, err := jwtpkg.GenerateChallengeToken(user.ID)
+		if err != nil {
+			logger.Log.Errorf("Generate challenge token failed: %v", err)
+			response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to genreate token: %v", err))
+			return
+		}
+		response.Success(c, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+			"expires_in":          int64(jwtpkg.ChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	uc.completeLogin(c, user)
+}
+
+// LoginTwoFactor 两步验证登录：使用挑战令牌与验证码换取正式令牌
+func (uc *UserController) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("LoginTwoFactor bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid input: %v", err))
+		return
+	}
+
+	userID, err := jwtpkg.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		logger.Log.Warnf("Parse challenge token failed: %v", err)
+		response.Error(c, http.StatusUnauthorized, "Invalid or expired challenge token")
+		return
+	}
+	user, err := uc.UserService.GetUserByID(userID)
+	if err != nil || user.Disabled {
+		response.Error(c, http.StatusUnauthorized, "Invalid or expired challenge token")
+		return
+	}
+
+	// 验证码同样计入登录失败次数，防止在挑战令牌有效期内穷举
+	ip := c.ClientIP()
+	wait, err := uc.LoginGuardService.Check(user.Username, ip)
+	if err != nil {
+		logger.Log.Errorf("Login guard check failed: %v", err)
+	}
+	if wait > 0 {
+		tooManyAttempts(c, wait)
+		return
+	}
+
+	if err := uc.TwoFactorService.Verify(user.ID, req.Code); err != nil {
+		logger.Log.Warnf("Two-factor login failed: username=%s ip=%s: %v", user.Username, ip, err)
+		if _, gerr := uc.LoginGuardService.RecordFailure(user.Username, ip, c.Request.UserAgent(), "2fa: "+err.Error()); gerr != nil {
+			logger.Log.Errorf("Record login failure failed: %v", gerr)
+		}
+		response.Error(c, http.StatusUnauthorized, fmt.Sprintf("Invalid verification code: %v", err))
+		return
+	}
+
+	uc.completeLogin(c, user)
+}
+
+// completeLogin 重置失败计数，创建会话并返回令牌
+func (uc *UserController) completeLogin(c *gin.Context, user *model.User) {
+	if err := uc.LoginGuardService.RecordSuccess(user.Username); err != nil {
+		logger.Log.Errorf("Reset login guard failed: %v", err)
+	}
+
+	tokens, err := uc.SessionService.CreateSession(user, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		logger.Log.Errorf("Generate token failed: %v", err)
 		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to genreate token: %v", err))
@@ -72,26 +181,110 @@ func (uc *UserController) Login(c *gin.Context) {
 	}
 
 	response.Success(c, gin.H{
-		"token": token,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 		"user": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
 			"role":     user.Role,
+			// 为 true 时需先调用修改密码接口
+			"must_change_password": user.MustChangePassword,
 		},
 	})
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+func (uc *UserController) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("Refresh bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := uc.SessionService.RefreshSession(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		logger.Log.Warnf("Refresh service failed: %v", err)
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// Logout 退出登录 (吊销当前会话)
+func (uc *UserController) Logout(c *gin.Context) {
+	if err := uc.SessionService.RevokeSession(c.GetString("userID"), c.GetString("sessionID")); err != nil {
+		logger.Log.Errorf("Logout service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to logout: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// LogoutAll 退出所有设备 (吊销当前用户的全部会话)
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	if err := uc.SessionService.RevokeAllSessions(c.GetString("userID")); err != nil {
+		logger.Log.Errorf("LogoutAll service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to logout: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 // GetProfile 方法获取用户信息
 func (uc *UserController) GetProfile(c *gin.Context) {
-	username := c.GetString("username")
-	userID := c.GetString("userID")
+	user, err := uc.UserService.GetUserByID(c.GetString("userID"))
+	if err != nil {
+		logger.Log.Warnf("GetProfile service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
 
 	response.Success(c, gin.H{
-		"user_id":  userID,
-		"username": username,
+		"user":        user,
+		"permissions": rbac.Permissions(user.Role),
 	})
 }
 
+type UpdateProfileRequest struct {
+	Email       *string `json:"email" binding:"omitempty,email,max=100"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=50"`
+	Avatar      *string `json:"avatar" binding:"omitempty,max=255"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+}
+
+// UpdateProfile 更新个人资料
+func (uc *UserController) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("UpdateProfile bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile := &service.UserProfile{
+		Email:       req.Email,
+		DisplayName: req.DisplayName,
+		Avatar:      req.Avatar,
+		Bio:         req.Bio,
+	}
+	if err := uc.UserService.UpdateProfile(c.GetString("userID"), profile); err != nil {
+		logger.Log.Errorf("UpdateProfile service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to update profile: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
@@ -116,3 +309,233 @@ func (uc *UserController) ChangePassword(c *gin.Context) {
 
 	response.Success(c, gin.H{"message": "Password updated successfully"})
 }
+
+type UserListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+	Keyword  string `form:"keyword"`
+	Role     string `form:"role"`
+}
+
+type CreateUserRequest struct {
+	Username    string `json:"username" binding:"required,min=3,max=50"`
+	Password    string `json:"password" binding:"required,min=6"`
+	Email       string `json:"email" binding:"omitempty,email,max=100"`
+	Role        string `json:"role" binding:"required"`
+	DisplayName string `json:"display_name" binding:"max=50"`
+}
+
+type UpdateUserRequest struct {
+	UpdateProfileRequest
+	Role *string `json:"role"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// GetUserList 获取用户列表 (管理员)
+func (uc *UserController) GetUserList(c *gin.Context) {
+	var req UserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetUserList bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := uc.UserService.GetUserList(&service.UserListReq{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Keyword:  req.Keyword,
+		Role:     req.Role,
+	})
+	if err != nil {
+		logger.Log.Errorf("GetUserList service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch users: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
+
+// GetUser 获取用户详情 (管理员)
+func (uc *UserController) GetUser(c *gin.Context) {
+	user, err := uc.UserService.GetUserByID(c.Param("id"))
+	if err != nil {
+		logger.Log.Warnf("GetUser service error: %v", err)
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.Success(c, user)
+}
+
+// CreateUser 创建用户 (管理员)
+func (uc *UserController) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("CreateUser bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user := &model.User{
+		Username:    req.Username,
+		Email:       req.Email,
+		Role:        req.Role,
+		DisplayName: req.DisplayName,
+	}
+	if err := uc.UserService.CreateUser(user, req.Password); err != nil {
+		logger.Log.Warnf("CreateUser service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to create user: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{"id": user.ID})
+}
+
+// UpdateUser 更新用户资料与角色 (管理员)
+func (uc *UserController) UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("UpdateUser bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Role != nil {
+		if err := uc.UserService.SetUserRole(id, *req.Role); err != nil {
+			logger.Log.Warnf("SetUserRole service error: %v", err)
+			response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to update user: %v", err))
+			return
+		}
+	}
+
+	profile := &service.UserProfile{
+		Email:       req.Email,
+		DisplayName: req.DisplayName,
+		Avatar:      req.Avatar,
+		Bio:         req.Bio,
+	}
+	if err := uc.UserService.UpdateProfile(id, profile); err != nil {
+		logger.Log.Warnf("UpdateUser service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to update user: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ResetPassword 重置用户密码 (管理员)
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Warnf("ResetPassword bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := uc.UserService.ResetPassword(c.Param("id"), req.Password); err != nil {
+		logger.Log.Warnf("ResetPassword service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to reset password: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DisableUser 禁用用户 (管理员)
+func (uc *UserController) DisableUser(c *gin.Context) {
+	uc.setDisabled(c, true)
+}
+
+// EnableUser 启用用户 (管理员)
+func (uc *UserController) EnableUser(c *gin.Context) {
+	uc.setDisabled(c, false)
+}
+
+func (uc *UserController) setDisabled(c *gin.Context, disabled bool) {
+	id := c.Param("id")
+	if disabled && id == c.GetString("userID") {
+		response.Error(c, http.StatusBadRequest, "Cannot disable yourself")
+		return
+	}
+
+	if err := uc.UserService.SetUserDisabled(id, disabled); err != nil {
+		logger.Log.Warnf("SetUserDisabled service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to update user: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// DeleteUser 删除用户 (管理员)
+func (uc *UserController) DeleteUser(c *gin.Context) {
+	id := c.Param("id")
+	if id == c.GetString("userID") {
+		response.Error(c, http.StatusBadRequest, "Cannot delete yourself")
+		return
+	}
+
+	if err := uc.UserService.DeleteUser(id); err != nil {
+		logger.Log.Warnf("DeleteUser service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to delete user: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+type LoginAttemptListRequest struct {
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+	Username string `form:"username"`
+	IP       string `form:"ip"`
+}
+
+// GetLoginAttempts 获取登录失败记录 (管理员)
+func (uc *UserController) GetLoginAttempts(c *gin.Context) {
+	var req LoginAttemptListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Log.Warnf("GetLoginAttempts bind failed: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Invalid query parameters: %v", err))
+		return
+	}
+
+	list, total, err := uc.LoginGuardService.GetLoginAttempts(&service.LoginAttemptListReq{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Username: req.Username,
+		IP:       req.IP,
+	})
+	if err != nil {
+		logger.Log.Errorf("GetLoginAttempts service error: %v", err)
+		response.Error(c, http.StatusInternalServerError, fmt.Sprintf("Failed to fetch login attempts: %v", err))
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  list,
+		"total": total,
+		"page":  req.Page,
+		"size":  req.PageSize,
+	})
+}
+
+// ResetTwoFactor 关闭用户的两步验证 (管理员，用于用户丢失验证器与恢复码)
+func (uc *UserController) ResetTwoFactor(c *gin.Context) {
+	if err := uc.TwoFactorService.Reset(c.Param("id")); err != nil {
+		logger.Log.Warnf("ResetTwoFactor service error: %v", err)
+		response.Error(c, http.StatusBadRequest, fmt.Sprintf("Failed to reset two-factor authentication: %v", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
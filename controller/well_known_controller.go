@@ -0,0 +1,20 @@
+package controller
+
+import (
+	jwtpkg "go-blog/pkg/jwt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownController struct{}
+
+func NewWellKnownController() *WellKnownController {
+	return &WellKnownController{}
+}
+
+// JWKS 公开 JWT 验证公钥，供其他服务自行校验本站签发的令牌 (标准 JWK Set 格式，不使用统一响应包装)
+func (wc *WellKnownController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwtpkg.JWKS())
+}
@@ -10,11 +10,14 @@ import (
 	"time"
 
 	"go-blog/config"
-	"go-blog/model"
 	crypto "go-blog/pkg/crypto"
 	"go-blog/pkg/database"
 	jwtpkg "go-blog/pkg/jwt"
 	"go-blog/pkg/logger"
+	"go-blog/pkg/oauth"
+	"go-blog/pkg/scheduler"
+	"go-blog/pkg/storage"
+	"go-blog/pkg/throttle"
 	router "go-blog/router"
 	service "go-blog/services"
 )
@@ -23,52 +26,218 @@ func main() {
 	// 加载配置
 	config.InitConfig()
 	logger.InitLogger("logs/server.log", "info")
+	// 数据库迁移子命令
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	// 全站数据导出 / 导入子命令
+	if len(os.Args) > 1 && (os.Args[1] == "export" || os.Args[1] == "import" || os.Args[1] == "import-content") {
+		os.Exit(runBackup(os.Args[1], os.Args[2:]))
+	}
+
 	// 初始化数据库连接
-	db, err := database.InitMySQL()
+	db, err := database.Open(&config.AppConfig.Database)
 	if err != nil {
-		logger.Log.Errorf("❌ Failed to connect the database: %v", err)
+		logger.Log.Fatalf("❌ Failed to connect the database: %v", err)
 	}
+	logger.Log.Infof("✅ Database connection successfully! (driver: %s)", db.Dialector.Name())
 
-	// 自动迁移模型
-	err = db.AutoMigrate(
-		&model.User{},
-		&model.Category{},
-		&model.Tag{},
-		&model.Post{},
-		&model.SiteConfig{},
-	)
-	if err != nil {
-		logger.Log.Errorf("❌ Data table migration failed: %v", err)
+	// 执行数据库迁移
+	migrateOnBoot(db)
+
+	// 兼容旧数据的发布状态
+	if err := service.NewPostService(db).SyncLegacyStatus(); err != nil {
+		logger.Log.Errorf("❌ Failed to sync post status: %v", err)
+	}
+
+	// 初始化全文搜索
+	if err := service.InitSearch(db, config.AppConfig.Search.Backend); err != nil {
+		logger.Log.Errorf("❌ Failed to init search engine: %v", err)
+	} else {
+		logger.Log.Infof("✅ Search engine initialized successfully!")
 	}
-	logger.Log.Infof("✅ Data table migration successfully!")
 
 	// 初始化 JWT
 	jcfg := &jwtpkg.Config{
-		Algorithm:      config.AppConfig.JWT.Algorithm,
-		Secret:         config.AppConfig.JWT.Secret,
-		PrivateKeyPath: config.AppConfig.JWT.PrivateKeyPath,
-		PublicKeyPath:  config.AppConfig.JWT.PublicKeyPath,
-		ExpireHours:    config.AppConfig.JWT.ExpireHours,
+		Algorithm:           config.AppConfig.JWT.Algorithm,
+		Secret:              config.AppConfig.JWT.Secret,
+		PrivateKeyPath:      config.AppConfig.JWT.PrivateKeyPath,
+		PublicKeyPath:       config.AppConfig.JWT.PublicKeyPath,
+		AccessExpireMinutes: config.AppConfig.JWT.AccessExpireMinutes,
+		RefreshExpireHours:  config.AppConfig.JWT.RefreshExpireHours,
+		Rotation: jwtpkg.RotationConfig{
+			Dir:       config.AppConfig.JWT.Rotation.Dir,
+			Algorithm: config.AppConfig.JWT.Rotation.Algorithm,
+			Interval:  time.Duration(config.AppConfig.JWT.Rotation.RotateHours) * time.Hour,
+		},
+	}
+	for _, k := range config.AppConfig.JWT.Keys {
+		jcfg.Keys = append(jcfg.Keys, jwtpkg.KeyConfig{
+			ID:             k.ID,
+			Algorithm:      k.Algorithm,
+			Secret:         k.Secret,
+			PrivateKeyPath: k.PrivateKeyPath,
+			PublicKeyPath:  k.PublicKeyPath,
+		})
 	}
 	if err := jwtpkg.Init(jcfg); err != nil {
 		logger.Log.Errorf("❌ Failed to init JWT: %v", err)
 	}
 	logger.Log.Infof("✅ JWT initialized successfully!")
 
-	// 初始化 RSA 密钥对
-	if err := crypto.InitRSAKeyPair(); err != nil {
+	// 初始化登录加密密钥 (持久化并定期轮换)
+	kcfg := config.AppConfig.LoginKey
+	ccfg := &crypto.Config{
+		Dir:            kcfg.Dir,
+		PrivateKey:     kcfg.PrivateKey,
+		Bits:           kcfg.Bits,
+		RotateInterval: time.Duration(kcfg.RotateHours) * time.Hour,
+		LegacyPKCS1v15: kcfg.LegacyPKCS1v15,
+	}
+	if err := crypto.Init(ccfg); err != nil {
 		logger.Log.Errorf("❌ Failed to init RSA KeyPair: %v", err)
 	}
 	logger.Log.Infof("✅ RSA KeyPair initialized sucessfully!")
 
+	// 初始化第三方登录提供方
+	var providers []oauth.Config
+	for _, p := range config.AppConfig.OAuth.Providers {
+		providers = append(providers, oauth.Config{
+			Name:         p.Name,
+			Type:         p.Type,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Issuer:       p.Issuer,
+			AuthURL:      p.AuthURL,
+			TokenURL:     p.TokenURL,
+			UserInfoURL:  p.UserInfoURL,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+			AutoCreate:   p.AutoCreate,
+			DefaultRole:  p.DefaultRole,
+		})
+	}
+	if err := oauth.Init(providers); err != nil {
+		logger.Log.Errorf("❌ Failed to init OAuth providers: %v", err)
+	}
+
+	// 初始化文件存储
+	if err := initStorage(); err != nil {
+		logger.Log.Errorf("❌ Failed to init storage: %v", err)
+	}
+	logger.Log.Infof("✅ Storage initialized successfully!")
+
+	// 初始化登录防爆破计数器
+	policy := throttle.DefaultPolicy
+	if n := config.AppConfig.Login.FreeAttempts; n > 0 {
+		policy.FreeAttempts = n
+	}
+	if n := config.AppConfig.Login.MaxFailures; n > 0 {
+		policy.MaxFailures = n
+	}
+	if n := config.AppConfig.Login.LockMinutes; n > 0 {
+		policy.LockDuration = time.Duration(n) * time.Minute
+	}
+	throttle.Default = throttle.NewMemoryTracker(policy)
+
 	// 初始化 Service 并检查 / 创建默认管理员
 	userService := service.NewUserService(db)
-	if err := userService.CreateAdminIfNotExists(); err != nil {
+	acfg := config.AppConfig.Admin
+	bootstrap := &service.AdminBootstrap{
+		Username:     acfg.Username,
+		Email:        acfg.Email,
+		Password:     acfg.Password,
+		PasswordHash: acfg.PasswordHash,
+	}
+	if password, err := userService.CreateAdminIfNotExists(bootstrap); err != nil {
 		logger.Log.Errorf("❌ Failed to create default adminadministrator: %v", err)
+	} else if password != "" {
+		// 随机密码只在首次创建时打印一次
+		logger.Log.Warnf("🔑 Initial administrator created: username=%s password=%s (change it after first login)", bootstrap.Username, password)
 	} else {
-		logger.Log.Infof("✅ Default administrator created successfully!")
+		logger.Log.Infof("✅ Default administrator checked successfully!")
 	}
 
+	// 启动后台任务：定时发布文章
+	sched := scheduler.New()
+	if database.HasReplicas(db) {
+		// 定期检查只读副本，不可用的副本不再接收读请求，恢复后自动重新启用
+		replicaStatus := make(map[string]string)
+		sched.Every("check-db-replicas", 15*time.Second, func() error {
+			for _, h := range database.Check(context.Background(), db) {
+				if h.Role != database.RoleReplica || replicaStatus[h.Name] == h.Status {
+					continue
+				}
+				replicaStatus[h.Name] = h.Status
+				if h.Status == database.StatusUp {
+					logger.Log.Infof("✅ Database replica %s is up", h.Name)
+				} else {
+					logger.Log.Warnf("⚠️ Database replica %s is down: %s", h.Name, h.Error)
+				}
+			}
+			return nil
+		})
+	}
+	postService := service.NewPostService(db)
+	sched.Every("publish-scheduled-posts", time.Minute, func() error {
+		n, err := postService.PublishDuePosts()
+		if n > 0 {
+			logger.Log.Infof("⏰ Published %d scheduled posts", n)
+		}
+		return err
+	})
+	sessionService := service.NewSessionService(db)
+	sched.Every("purge-expired-sessions", time.Hour, func() error {
+		_, err := sessionService.PurgeExpiredSessions()
+		return err
+	})
+	loginGuardService := service.NewLoginGuardService(db)
+	sched.Every("purge-login-attempts", 24*time.Hour, func() error {
+		_, err := loginGuardService.PurgeLoginAttempts()
+		return err
+	})
+	auditService := service.NewAuditService(db)
+	if days := config.AppConfig.Audit.RetentionDays; days > 0 {
+		auditService.Retention = time.Duration(days) * 24 * time.Hour
+	}
+	sched.Every("purge-audit-logs", 24*time.Hour, func() error {
+		n, err := auditService.PurgeLogs()
+		if n > 0 {
+			logger.Log.Infof("🧹 Purged %d audit logs", n)
+		}
+		return err
+	})
+	if days := config.AppConfig.Trash.RetentionDays; days > 0 {
+		retention := time.Duration(days) * 24 * time.Hour
+		sched.Every("purge-trash", 24*time.Hour, func() error {
+			n, err := service.PurgeTrash(db, time.Now().Add(-retention))
+			if n > 0 {
+				logger.Log.Infof("🗑️ Purged %d items from trash", n)
+			}
+			return err
+		})
+	}
+	oauthService := service.NewOAuthService(db)
+	sched.Every("purge-oauth-states", time.Hour, func() error {
+		_, err := oauthService.PurgeExpiredStates()
+		return err
+	})
+	// 定期重新加载密钥目录，到期时轮换 (多实例共享目录时同步其他实例生成的密钥)
+	sched.Every("rotate-jwt-key", 10*time.Minute, func() error {
+		rotated, err := jwtpkg.RotateIfDue()
+		if rotated {
+			logger.Log.Infof("🔑 JWT signing key rotated")
+		}
+		return err
+	})
+	sched.Every("rotate-login-key", 10*time.Minute, func() error {
+		rotated, err := crypto.RotateIfDue()
+		if rotated {
+			logger.Log.Infof("🔑 Login encryption key rotated")
+		}
+		return err
+	})
+
 	r := router.InitRouter(db)
 	port := config.AppConfig.Server.Port
 	addr := fmt.Sprintf(":%d", port)
@@ -101,8 +270,32 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Log.Fatalf("❌ Server Shutdown (Force): %s", err)
 	}
+	if err := sched.Stop(ctx); err != nil {
+		logger.Log.Errorf("❌ Scheduler Shutdown: %s", err)
+	}
 	sqlDB, _ := db.DB()
 	_ = sqlDB.Close()
 
 	logger.Log.Infof("✅ Server exiting")
 }
+
+// initStorage 根据配置初始化文件存储
+func initStorage() error {
+	mcfg := config.AppConfig.Media
+	return storage.Init(&storage.Config{
+		Backend: mcfg.Storage,
+		Local: storage.LocalConfig{
+			Dir:       mcfg.Local.Dir,
+			URLPrefix: mcfg.Local.URLPrefix,
+		},
+		S3: storage.S3Config{
+			Endpoint:  mcfg.S3.Endpoint,
+			Region:    mcfg.S3.Region,
+			Bucket:    mcfg.S3.Bucket,
+			AccessKey: mcfg.S3.AccessKey,
+			SecretKey: mcfg.S3.SecretKey,
+			PublicURL: mcfg.S3.PublicURL,
+			PathStyle: mcfg.S3.PathStyle,
+		},
+	})
+}
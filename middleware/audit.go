@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"go-blog/model"
+	"go-blog/pkg/logger"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 为获取新建资源的 ID 最多缓存的响应大小
+const auditMaxBody = 64 << 10
+
+// auditWriter 缓存响应内容，用于从创建接口的响应中读取资源 ID
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.body.Len() < auditMaxBody {
+		w.body.Write(b[:min(len(b), auditMaxBody-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// audit 执行后续处理，修改类请求成功后写入审计日志 (记录修改前后的字段差异)
+func audit(c *gin.Context, auditService service.IAuditService) {
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		c.Next()
+		return
+	}
+
+	target := auditService.Target(c.Request.Method, c.FullPath())
+	id := c.Param("id")
+	if id == "" {
+		// 修订历史路由的文章 ID 参数名为 slug
+		id = c.Param("slug")
+	}
+	if target.Self {
+		id = c.GetString("userID")
+	}
+	before := auditService.Snapshot(target, id)
+
+	var w *auditWriter
+	if id == "" {
+		w = &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+	}
+
+	c.Next()
+
+	if c.Writer.Status() >= http.StatusBadRequest || c.IsAborted() {
+		return
+	}
+	if id == "" && before != nil {
+		id, _ = before["id"].(string)
+	}
+	if id == "" && w != nil {
+		id = createdID(w.body.Bytes())
+	}
+	after := auditService.Snapshot(target, id)
+
+	err := auditService.Record(&model.AuditLog{
+		ActorID:      c.GetString("userID"),
+		ActorName:    c.GetString("username"),
+		TokenID:      c.GetString("tokenID"),
+		Action:       target.Action,
+		ResourceType: target.Type,
+		ResourceID:   id,
+		Method:       c.Request.Method,
+		Path:         c.FullPath(),
+		IP:           c.ClientIP(),
+		UserAgent:    c.Request.UserAgent(),
+	}, before, after)
+	if err != nil {
+		logger.Log.Errorf("Record audit log failed: %v", err)
+	}
+}
+
+// createdID 从统一响应的 data.id (或 data.info.id) 中读取新建资源的 ID
+func createdID(body []byte) string {
+	var resp struct {
+		Data struct {
+			ID   string `json:"id"`
+			Info struct {
+				ID string `json:"id"`
+			} `json:"info"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	if resp.Data.ID != "" {
+		return resp.Data.ID
+	}
+	return resp.Data.Info.ID
+}
@@ -1,16 +1,27 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	jwtpkg "go-blog/pkg/jwt"
 	"go-blog/pkg/response"
+	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func JWTAuth() gin.HandlerFunc {
+// 必须修改密码的用户只能访问该接口
+const changePasswordPath = "/api/user/change-password"
+
+// JWTAuth 校验访问令牌，并确认其会话未被吊销；也接受 "Token <个人访问令牌>"
+// 认证后的修改类请求 (POST / PUT / DELETE) 写入审计日志
+func JWTAuth(db *gorm.DB) gin.HandlerFunc {
+	sessionService := service.NewSessionService(db)
+	apiTokenService := service.NewAPITokenService(db)
+	auditService := service.NewAuditService(db)
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -19,6 +30,21 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && parts[0] == "Token" {
+			user, token, err := apiTokenService.Authenticate(parts[1], c.ClientIP())
+			if err != nil {
+				response.Error(c, http.StatusUnauthorized, "Invalid or expired token")
+				c.Abort()
+				return
+			}
+			c.Set("userID", user.ID)
+			c.Set("username", user.Username)
+			c.Set("role", user.Role)
+			c.Set("tokenID", token.ID)
+			c.Set("scopes", token.Scopes)
+			audit(c, auditService)
+			return
+		}
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			response.Error(c, http.StatusUnauthorized, "Invalid token format")
 			c.Abort()
@@ -31,9 +57,35 @@ func JWTAuth() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if err := sessionService.ValidateToken(claims); err != nil {
+			if !errors.Is(err, service.ErrPasswordChangeRequired) {
+				response.Error(c, http.StatusUnauthorized, "Session revoked: "+err.Error())
+				c.Abort()
+				return
+			}
+			if c.FullPath() != changePasswordPath {
+				response.Error(c, http.StatusForbidden, "Password change required")
+				c.Abort()
+				return
+			}
+		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		audit(c, auditService)
+	}
+}
+
+// SessionOnly 账户安全相关接口只允许登录会话访问，拒绝个人访问令牌 (需在 JWTAuth 之后使用)
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("tokenID") != "" {
+			response.Error(c, http.StatusForbidden, "Not allowed with API token")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
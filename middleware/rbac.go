@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-blog/pkg/rbac"
+	"go-blog/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 校验当前用户拥有指定权限 (需在 JWTAuth 之后使用)
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, permission) {
+			response.Error(c, http.StatusForbidden, "Permission denied: "+permission)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission 判断当前用户角色拥有指定权限，个人访问令牌还需 scope 包含该权限
+func HasPermission(c *gin.Context, permission string) bool {
+	if !rbac.Can(c.GetString("role"), permission) {
+		return false
+	}
+	if scopes, ok := c.Get("scopes"); ok {
+		return rbac.ScopeAllows(scopes.([]string), permission)
+	}
+	return true
+}
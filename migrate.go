@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go-blog/config"
+	"go-blog/migrations"
+	"go-blog/pkg/database"
+	"go-blog/pkg/logger"
+	"go-blog/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Usage: blog-server migrate <command>
+
+Commands:
+  up             执行所有待执行的迁移
+  down [n]       回滚最近执行的 n 个迁移 (默认 1)
+  status         查看迁移状态
+  create <name>  在 ./migrations 下生成新的迁移文件
+`
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		path, err := migrations.Create("migrations", args[1], time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to create migration: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ Created %s\n", path)
+		return 0
+	}
+
+	switch args[0] {
+	case "up", "down", "status":
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, err := database.Open(&config.AppConfig.Database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to connect the database: %v\n", err)
+		return 1
+	}
+	m := migrate.New(db, migrations.All())
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up()
+		for _, mg := range done {
+			fmt.Printf("⬆️  %d_%s\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Migration failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ %d migrations applied\n", len(done))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "❌ Invalid step count %q\n", args[1])
+				return 2
+			}
+		}
+		done, err := m.Down(steps)
+		for _, mg := range done {
+			fmt.Printf("⬇️  %d_%s\n", mg.Version, mg.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Rollback failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ %d migrations rolled back\n", len(done))
+	case "status":
+		list, err := m.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read migration status: %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, s := range list {
+			appliedAt := "-"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, appliedAt)
+		}
+		w.Flush()
+	}
+	return 0
+}
+
+// migrateOnBoot 启动时执行迁移；配置 skip_migrate 时仅检查，存在待执行的迁移则拒绝启动
+func migrateOnBoot(db *gorm.DB) {
+	m := migrate.New(db, migrations.All())
+	if config.AppConfig.Database.SkipMigrate {
+		n, err := m.Pending()
+		if err != nil {
+			logger.Log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		if n > 0 {
+			logger.Log.Fatalf("❌ %d pending migrations, run `blog-server migrate up` first", n)
+		}
+		return
+	}
+	done, err := m.Up()
+	for _, mg := range done {
+		logger.Log.Infof("⬆️ Applied migration %d_%s", mg.Version, mg.Name)
+	}
+	if err != nil {
+		logger.Log.Fatalf("❌ Data table migration failed: %v", err)
+	}
+	logger.Log.Infof("✅ Data table migration successfully!")
+}
@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(upBaseline, downBaseline)
+}
+
+// baselineModels 初始表结构 (与引入迁移前 AutoMigrate 创建的表一致，另补上遗漏的友情链接表)
+func baselineModels() []any {
+	type User struct {
+		ID                 string `gorm:"type:char(36);primaryKey"`
+		Username           string `gorm:"size:50;unique;not null"`
+		Password           string `gorm:"size:255;not null"`
+		Email              string `gorm:"size:100;not null"`
+		Role               string `gorm:"size:20;default:'admin'"`
+		DisplayName        string `gorm:"size:50"`
+		Avatar             string `gorm:"size:255"`
+		Bio                string `gorm:"size:500"`
+		Disabled           bool   `gorm:"not null;default:false"`
+		MustChangePassword bool   `gorm:"not null;default:false"`
+		PasswordChangedAt  *time.Time
+		TOTPSecret         string    `gorm:"column:totp_secret;size:64"`
+		TOTPEnabled        bool      `gorm:"column:totp_enabled;not null;default:false"`
+		TOTPLastStep       int64     `gorm:"column:totp_last_step;not null;default:0"`
+		CreatedAt          time.Time `gorm:"index"`
+		UpdatedAt          time.Time
+	}
+	type Session struct {
+		ID            string    `gorm:"type:char(36);primaryKey"`
+		UserID        string    `gorm:"type:char(36);not null;index"`
+		TokenHash     string    `gorm:"size:64;not null;uniqueIndex"`
+		PrevTokenHash string    `gorm:"size:64;index"`
+		IP            string    `gorm:"size:45"`
+		UserAgent     string    `gorm:"size:255"`
+		ExpiresAt     time.Time `gorm:"index"`
+		RevokedAt     *time.Time
+		LastUsedAt    time.Time
+		CreatedAt     time.Time
+		UpdatedAt     time.Time
+	}
+	type LoginAttempt struct {
+		ID        string    `gorm:"type:char(36);primaryKey"`
+		Username  string    `gorm:"size:50;index"`
+		IP        string    `gorm:"size:45;index"`
+		UserAgent string    `gorm:"size:255"`
+		Reason    string    `gorm:"size:100"`
+		CreatedAt time.Time `gorm:"index"`
+	}
+	type RecoveryCode struct {
+		ID        string `gorm:"type:char(36);primaryKey"`
+		UserID    string `gorm:"type:char(36);not null;index"`
+		CodeHash  string `gorm:"size:64;not null;uniqueIndex"`
+		UsedAt    *time.Time
+		CreatedAt time.Time
+	}
+	type UserIdentity struct {
+		ID          string `gorm:"type:char(36);primaryKey"`
+		UserID      string `gorm:"type:char(36);not null;index"`
+		Provider    string `gorm:"size:50;not null;uniqueIndex:idx_provider_subject"`
+		Subject     string `gorm:"size:255;not null;uniqueIndex:idx_provider_subject"`
+		Username    string `gorm:"size:100"`
+		Email       string `gorm:"size:100"`
+		LastLoginAt *time.Time
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+	type OAuthState struct {
+		ID           string    `gorm:"type:char(36);primaryKey"`
+		StateHash    string    `gorm:"size:64;not null;uniqueIndex"`
+		Provider     string    `gorm:"size:50;not null"`
+		CodeVerifier string    `gorm:"size:128;not null"`
+		Nonce        string    `gorm:"size:64"`
+		CallbackURL  string    `gorm:"size:255"`
+		Redirect     string    `gorm:"size:255"`
+		LinkUserID   string    `gorm:"type:char(36)"`
+		ExpiresAt    time.Time `gorm:"index"`
+		CreatedAt    time.Time
+	}
+	type APIToken struct {
+		ID         string `gorm:"type:char(36);primaryKey"`
+		UserID     string `gorm:"type:char(36);not null;index"`
+		Name       string `gorm:"size:100;not null"`
+		Prefix     string `gorm:"size:20"`
+		TokenHash  string `gorm:"size:64;not null;uniqueIndex"`
+		Scopes     string `gorm:"size:255"`
+		ExpiresAt  *time.Time
+		LastUsedAt *time.Time
+		LastUsedIP string `gorm:"size:45"`
+		CreatedAt  time.Time
+	}
+	type AuditLog struct {
+		ID           string    `gorm:"type:char(36);primaryKey"`
+		ActorID      string    `gorm:"type:char(36);index"`
+		ActorName    string    `gorm:"size:50"`
+		TokenID      string    `gorm:"type:char(36)"`
+		Action       string    `gorm:"size:100;index"`
+		ResourceType string    `gorm:"size:30;index:idx_audit_resource"`
+		ResourceID   string    `gorm:"size:64;index:idx_audit_resource"`
+		Method       string    `gorm:"size:10"`
+		Path         string    `gorm:"size:255"`
+		Changes      string    `gorm:"type:text"`
+		IP           string    `gorm:"size:45"`
+		UserAgent    string    `gorm:"size:255"`
+		CreatedAt    time.Time `gorm:"index"`
+	}
+	type Category struct {
+		ID        string    `gorm:"type:char(36);primaryKey"`
+		Name      string    `gorm:"size:50;unique;not null"`
+		Slug      string    `gorm:"size:100;unique"`
+		CreatedAt time.Time `gorm:"index"`
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type Tag struct {
+		ID        string    `gorm:"type:char(36);primaryKey"`
+		Name      string    `gorm:"size:50;unique;not null"`
+		Slug      string    `gorm:"size:100;unique"`
+		CreatedAt time.Time `gorm:"index"`
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type Comment struct {
+		ID          string    `gorm:"type:char(36);primaryKey"`
+		PostID      string    `gorm:"type:char(36);index;not null"`
+		ParentID    *string   `gorm:"type:char(36);index"`
+		AuthorName  string    `gorm:"size:50;not null"`
+		AuthorEmail string    `gorm:"size:100;not null"`
+		AuthorURL   string    `gorm:"size:255"`
+		Content     string    `gorm:"type:text;not null"`
+		Status      string    `gorm:"size:20;default:'pending';index"`
+		IP          string    `gorm:"size:64"`
+		UserAgent   string    `gorm:"size:255"`
+		CreatedAt   time.Time `gorm:"index"`
+		UpdatedAt   time.Time
+	}
+	type Post struct {
+		ID          string     `gorm:"type:char(36);primaryKey"`
+		Title       string     `gorm:"size:255;not null"`
+		Content     string     `gorm:"type:longtext;not null"`
+		ContentHTML string     `gorm:"type:longtext"`
+		Summary     string     `gorm:"size:500"`
+		Slug        string     `gorm:"size:255;unique;not null;index"`
+		Cover       string     `gorm:"size:255"`
+		CategoryID  string     `gorm:"type:char(36);index"`
+		AuthorID    string     `gorm:"type:char(36);index"`
+		Views       *uint      `gorm:"default:0"`
+		IsPublished *bool      `gorm:"default:true"`
+		Status      string     `gorm:"size:20;default:'published';index"`
+		PublishAt   *time.Time `gorm:"index"`
+		CreatedAt   time.Time  `gorm:"index"`
+		UpdatedAt   time.Time
+		DeletedAt   gorm.DeletedAt `gorm:"index"`
+
+		Category Category  `gorm:"foreignKey:CategoryID"`
+		Author   User      `gorm:"foreignKey:AuthorID"`
+		Tags     []Tag     `gorm:"many2many:post_tags"`
+		Comments []Comment `gorm:"foreignKey:PostID"`
+	}
+	type PostRevision struct {
+		ID        string `gorm:"type:char(36);primaryKey"`
+		PostID    string `gorm:"type:char(36);not null;uniqueIndex:idx_post_version"`
+		Version   int    `gorm:"not null;uniqueIndex:idx_post_version"`
+		Title     string `gorm:"size:255;not null"`
+		Content   string `gorm:"type:longtext;not null"`
+		Summary   string `gorm:"size:500"`
+		TagIDs    string `gorm:"type:text"`
+		EditorID  string `gorm:"type:char(36);index"`
+		CreatedAt time.Time
+	}
+	type SiteConfig struct {
+		ID          string `gorm:"type:char(36);primaryKey"`
+		Title       string `gorm:"size:100;not null"`
+		Subtitle    string `gorm:"size:255"`
+		Description string `gorm:"type:text"`
+		Keywords    string `gorm:"size:255"`
+		Author      string `gorm:"size:50"`
+		Email       string `gorm:"size:100"`
+		GithubURL   string `gorm:"size:255"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+	}
+	type Link struct {
+		ID          string    `gorm:"type:char(36);primaryKey"`
+		Name        string    `gorm:"size:50;not null"`
+		URL         string    `gorm:"size:255;not null"`
+		Description string    `gorm:"size:255"`
+		Sort        int       `gorm:"default:0"`
+		CreatedAt   time.Time `gorm:"index"`
+		UpdatedAt   time.Time
+		DeletedAt   gorm.DeletedAt `gorm:"index"`
+	}
+	type Media struct {
+		ID           string `gorm:"type:char(36);primaryKey"`
+		FileName     string `gorm:"size:255;not null"`
+		MimeType     string `gorm:"size:50;not null"`
+		Size         int64  `gorm:"not null"`
+		Width        int
+		Height       int
+		Key          string    `gorm:"size:255;unique;not null"`
+		URL          string    `gorm:"size:255;not null"`
+		ThumbnailKey string    `gorm:"size:255"`
+		ThumbnailURL string    `gorm:"size:255"`
+		WebPKey      string    `gorm:"size:255"`
+		WebPURL      string    `gorm:"size:255"`
+		UploaderID   string    `gorm:"type:char(36);index"`
+		CreatedAt    time.Time `gorm:"index"`
+		UpdatedAt    time.Time
+	}
+
+	return []any{
+		&User{}, &Session{}, &LoginAttempt{}, &RecoveryCode{}, &UserIdentity{}, &OAuthState{},
+		&APIToken{}, &AuditLog{}, &Category{}, &Tag{}, &Post{}, &PostRevision{},
+		&SiteConfig{}, &Comment{}, &Media{}, &Link{},
+	}
+}
+
+// upBaseline 创建初始表结构 (AutoMigrate 只补充缺少的表、字段与索引，可直接用于已有数据库)
+func upBaseline(tx *gorm.DB) error {
+	return tx.Migrator().AutoMigrate(baselineModels()...)
+}
+
+// downBaseline 删除所有表
+func downBaseline(tx *gorm.DB) error {
+	models := baselineModels()
+	tables := []any{"post_tags"}
+	for i := len(models) - 1; i >= 0; i-- {
+		tables = append(tables, models[i])
+	}
+	return tx.Migrator().DropTable(tables...)
+}
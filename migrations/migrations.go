@@ -0,0 +1,104 @@
+// Package migrations 数据库版本化迁移
+//
+// 每个迁移是一个 <版本>_<名称>.go 文件 (通过 `blog-server migrate create <名称>` 生成)，
+// 在 init 中调用 register 注册 up / down 函数。迁移的校验和为文件内容的 SHA-256，
+// 已执行的迁移不能再修改，需要调整表结构时请新建迁移。
+//
+// 迁移中不要引用 model 包的结构体 (模型会随版本变化)，应在迁移内定义当时的表结构，
+// 并尽量使用 gorm Migrator 或按 tx.Dialector.Name() 区分 SQL，保证 MySQL 与 SQLite 均可执行。
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"go-blog/pkg/migrate"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed *.go
+var sources embed.FS
+
+var registry []migrate.Migration
+
+var fileRe = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.go$`)
+
+// register 注册调用方文件对应的迁移，版本与名称取自文件名
+func register(up, down func(tx *gorm.DB) error) {
+	_, file, _, _ := runtime.Caller(1)
+	base := filepath.Base(file)
+	match := fileRe.FindStringSubmatch(base)
+	if match == nil {
+		panic("migrations: invalid migration file name " + base)
+	}
+	src, err := sources.ReadFile(base)
+	if err != nil {
+		panic("migrations: " + err.Error())
+	}
+	version, _ := strconv.ParseInt(match[1], 10, 64)
+	sum := sha256.Sum256(src)
+	registry = append(registry, migrate.Migration{
+		Version:  version,
+		Name:     match[2],
+		Checksum: hex.EncodeToString(sum[:]),
+		Up:       up,
+		Down:     down,
+	})
+}
+
+// All 获取所有已注册的迁移
+func All() []migrate.Migration {
+	return append([]migrate.Migration(nil), registry...)
+}
+
+var nameRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+const template = `package migrations
+
+import "gorm.io/gorm"
+
+func init() {
+	register(up%[1]s, down%[1]s)
+}
+
+// up%[1]s %[2]s
+func up%[1]s(tx *gorm.DB) error {
+	return nil
+}
+
+// down%[1]s 回滚 %[2]s
+func down%[1]s(tx *gorm.DB) error {
+	return nil
+}
+`
+
+// Create 在 dir 下生成新的迁移文件，返回文件路径
+func Create(dir, name string, now time.Time) (string, error) {
+	snake := strings.Trim(nameRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if snake == "" {
+		return "", fmt.Errorf("invalid migration name %q", name)
+	}
+	var camel strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		camel.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.go", now.UTC().Format("20060102150405"), snake))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintf(f, template, camel.String(), name); err != nil {
+		return "", err
+	}
+	return path, nil
+}
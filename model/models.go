@@ -9,13 +9,22 @@ import (
 
 // 👋 User 用户表
 type User struct {
-	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
-	Username  string    `gorm:"size:50;unique;not null" json:"username"`
-	Password  string    `gorm:"size:255;not null" json:"-"`
-	Email     string    `gorm:"size:100;not null" json:"email"`
-	Role      string    `gorm:"size:20;default:'admin'" json:"role"`
-	CreatedAt time.Time `gorm:"index" json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                 string     `gorm:"type:char(36);primaryKey" json:"id"`
+	Username           string     `gorm:"size:50;unique;not null" json:"username"`
+	Password           string     `gorm:"size:255;not null" json:"-"`
+	Email              string     `gorm:"size:100;not null" json:"email"`
+	Role               string     `gorm:"size:20;default:'admin'" json:"role"`
+	DisplayName        string     `gorm:"size:50" json:"display_name"`
+	Avatar             string     `gorm:"size:255" json:"avatar"`
+	Bio                string     `gorm:"size:500" json:"bio"`
+	Disabled           bool       `gorm:"not null;default:false" json:"disabled"`             // 禁用后无法登录，已签发的 Token 立即失效
+	MustChangePassword bool       `gorm:"not null;default:false" json:"must_change_password"` // 修改密码前只能访问修改密码接口
+	PasswordChangedAt  *time.Time `json:"-"`                                                  // 最近一次修改密码的时间，早于该时间签发的 Token 失效
+	TOTPSecret         string     `gorm:"column:totp_secret;size:64" json:"-"`                // 两步验证密钥 (Base32)，启用前为待验证状态
+	TOTPEnabled        bool       `gorm:"column:totp_enabled;not null;default:false" json:"totp_enabled"`
+	TOTPLastStep       int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"` // 最近一次使用的时间窗口，防止验证码重放
+	CreatedAt          time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
@@ -23,13 +32,147 @@ func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
-// 📂 Category 分类表
-type Category struct {
+// 🔑 Session 登录会话表 (保存刷新令牌哈希，支持服务端吊销)
+type Session struct {
+	ID            string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID        string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	TokenHash     string     `gorm:"size:64;not null;uniqueIndex" json:"-"` // 当前刷新令牌的 SHA-256
+	PrevTokenHash string     `gorm:"size:64;index" json:"-"`                // 上一个刷新令牌，用于检测重放
+	IP            string     `gorm:"size:45" json:"ip"`
+	UserAgent     string     `gorm:"size:255" json:"user_agent"`
+	ExpiresAt     time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.NewString()
+	return
+}
+
+// 🚫 LoginAttempt 登录失败记录 (审计)
+type LoginAttempt struct {
 	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
-	Name      string    `gorm:"size:50;unique;not null" json:"name"`
-	Slug      string    `gorm:"size:100;unique" json:"slug"` // slug 用于前端别名
+	Username  string    `gorm:"size:50;index" json:"username"`
+	IP        string    `gorm:"size:45;index" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	Reason    string    `gorm:"size:100" json:"reason"`
 	CreatedAt time.Time `gorm:"index" json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (a *LoginAttempt) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.NewString()
+	return
+}
+
+// 🛟 RecoveryCode 两步验证恢复码 (一次性，仅保存哈希)
+type RecoveryCode struct {
+	ID        string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID    string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
+
+// 🔗 UserIdentity 第三方登录身份 (提供方 + subject 绑定到用户)
+type UserIdentity struct {
+	ID          string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID      string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_provider_subject" json:"subject"`
+	Username    string     `gorm:"size:100" json:"username"`
+	Email       string     `gorm:"size:100" json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.NewString()
+	return
+}
+
+// 🎫 OAuthState 第三方登录授权请求 (state 一次性使用，保存 PKCE verifier 与 nonce)
+type OAuthState struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	StateHash    string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Provider     string    `gorm:"size:50;not null" json:"provider"`
+	CodeVerifier string    `gorm:"size:128;not null" json:"-"`
+	Nonce        string    `gorm:"size:64" json:"-"`
+	CallbackURL  string    `gorm:"size:255" json:"-"`      // 提交给提供方的 redirect_uri，换取令牌时必须一致
+	Redirect     string    `gorm:"size:255" json:"-"`      // 登录完成后跳转的前端地址
+	LinkUserID   string    `gorm:"type:char(36)" json:"-"` // 不为空时为已登录用户绑定身份
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (o *OAuthState) BeforeCreate(tx *gorm.DB) (err error) {
+	o.ID = uuid.NewString()
+	return
+}
+
+// 🪪 APIToken 个人访问令牌 (用于脚本与 CI，仅保存哈希)
+type APIToken struct {
+	ID         string     `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID     string     `gorm:"type:char(36);not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:20" json:"prefix"` // 令牌前几位，便于识别
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"size:255;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:45" json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t *APIToken) BeforeCreate(tx *gorm.DB) (err error) {
+	t.ID = uuid.NewString()
+	return
+}
+
+// 📜 AuditLog 审计日志 (记录已认证用户的修改操作)
+type AuditLog struct {
+	ID           string                 `gorm:"type:char(36);primaryKey" json:"id"`
+	ActorID      string                 `gorm:"type:char(36);index" json:"actor_id"`
+	ActorName    string                 `gorm:"size:50" json:"actor_name"`
+	TokenID      string                 `gorm:"type:char(36)" json:"token_id"` // 通过个人访问令牌操作时记录令牌 ID
+	Action       string                 `gorm:"size:100;index" json:"action"`  // 如 post.update、comment.approve
+	ResourceType string                 `gorm:"size:30;index:idx_audit_resource" json:"resource_type"`
+	ResourceID   string                 `gorm:"size:64;index:idx_audit_resource" json:"resource_id"`
+	Method       string                 `gorm:"size:10" json:"method"`
+	Path         string                 `gorm:"size:255" json:"path"` // 路由模板
+	Changes      map[string]AuditChange `gorm:"type:text;serializer:json" json:"changes"`
+	IP           string                 `gorm:"size:45" json:"ip"`
+	UserAgent    string                 `gorm:"size:255" json:"user_agent"`
+	CreatedAt    time.Time              `gorm:"index" json:"created_at"`
+}
+
+// AuditChange 字段修改前后的值 (创建时 before 为空，删除时 after 为空)
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.NewString()
+	return
+}
+
+// 📂 Category 分类表
+type Category struct {
+	ID        string         `gorm:"type:char(36);primaryKey" json:"id"`
+	Name      string         `gorm:"size:50;unique;not null" json:"name"`
+	Slug      string         `gorm:"size:100;unique" json:"slug"` // slug 用于前端别名
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
@@ -39,11 +182,12 @@ func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
 
 // 🏷️ Tag 标签表
 type Tag struct {
-	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
-	Name      string    `gorm:"size:50;unique;not null" json:"name"`
-	Slug      string    `gorm:"size:100;unique" json:"slug"`
-	CreatedAt time.Time `gorm:"index" json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string         `gorm:"type:char(36);primaryKey" json:"id"`
+	Name      string         `gorm:"size:50;unique;not null" json:"name"`
+	Slug      string         `gorm:"size:100;unique" json:"slug"`
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
 
 func (t *Tag) BeforeCreate(tx *gorm.DB) (err error) {
@@ -51,25 +195,38 @@ func (t *Tag) BeforeCreate(tx *gorm.DB) (err error) {
 	return
 }
 
+// 文章状态
+const (
+	PostStatusDraft     = "draft"     // 草稿
+	PostStatusScheduled = "scheduled" // 定时发布
+	PostStatusPublished = "published" // 已发布
+	PostStatusArchived  = "archived"  // 已归档
+)
+
 // 📄 Post 文章表
 type Post struct {
-	ID          string    `gorm:"type:char(36);primaryKey" json:"id"`
-	Title       string    `gorm:"size:255;not null" json:"title"`
-	Content     string    `gorm:"type:longtext;not null" json:"content"`
-	Summary     string    `gorm:"size:500" json:"summary"`
-	Slug        string    `gorm:"size:255;unique;not null;index" json:"slug"` // SEO Friendly URL
-	Cover       string    `gorm:"size:255" json:"cover"`
-	CategoryID  string    `gorm:"type:char(36);index" json:"category_id"`
-	AuthorID    string    `gorm:"type:char(36);index" json:"author_id"`
-	Views       *uint     `gorm:"default:0" json:"views"`
-	IsPublished *bool     `gorm:"default:true" json:"is_published"`
-	CreatedAt   time.Time `gorm:"index" json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string         `gorm:"type:char(36);primaryKey" json:"id"`
+	Title       string         `gorm:"size:255;not null" json:"title"`
+	Content     string         `gorm:"type:longtext;not null" json:"content"`
+	ContentHTML string         `gorm:"type:longtext" json:"content_html"` // 由 Content 渲染并过滤后的 HTML 缓存
+	Summary     string         `gorm:"size:500" json:"summary"`
+	Slug        string         `gorm:"size:255;unique;not null;index" json:"slug"` // SEO Friendly URL
+	Cover       string         `gorm:"size:255" json:"cover"`
+	CategoryID  string         `gorm:"type:char(36);index" json:"category_id"`
+	AuthorID    string         `gorm:"type:char(36);index" json:"author_id"`
+	Views       *uint          `gorm:"default:0" json:"views"`
+	IsPublished *bool          `gorm:"default:true" json:"is_published"`                // 与 Status 保持同步，仅 published 时为 true
+	Status      string         `gorm:"size:20;default:'published';index" json:"status"` // 发布状态
+	PublishAt   *time.Time     `gorm:"index" json:"publish_at"`                         // 发布时间 (定时发布)
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"` // 软删除 (回收站)
 
 	// 关系映射
-	Category Category `gorm:"foreignKey:CategoryID" json:"category"`
-	Author   User     `gorm:"foreignKey:AuthorID" json:"author"`
-	Tags     []Tag    `gorm:"many2many:post_tags" json:"tags"`
+	Category Category  `gorm:"foreignKey:CategoryID" json:"category"`
+	Author   User      `gorm:"foreignKey:AuthorID" json:"author"`
+	Tags     []Tag     `gorm:"many2many:post_tags" json:"tags"`
+	Comments []Comment `gorm:"foreignKey:PostID" json:"comments,omitempty"`
 }
 
 func (p *Post) BeforeCreate(tx *gorm.DB) (err error) {
@@ -98,16 +255,91 @@ func (s *SiteConfig) BeforeCreate(tx *gorm.DB) (err error) {
 
 // 🔗 Link 友情链接表
 type Link struct {
+	ID          string         `gorm:"type:char(36);primaryKey" json:"id"`
+	Name        string         `gorm:"size:50;not null" json:"name"` // 网站名称
+	URL         string         `gorm:"size:255;not null" json:"url"` // 网址
+	Description string         `gorm:"size:255" json:"description"`  // 描述
+	Sort        int            `gorm:"default:0" json:"sort"`        // 排序权重
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (l *Link) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.NewString()
+	return
+}
+
+// 评论状态
+const (
+	CommentStatusPending  = "pending"  // 待审核
+	CommentStatusApproved = "approved" // 已通过
+	CommentStatusRejected = "rejected" // 已拒绝
+	CommentStatusSpam     = "spam"     // 垃圾评论
+)
+
+// 💬 Comment 评论表
+type Comment struct {
 	ID          string    `gorm:"type:char(36);primaryKey" json:"id"`
-	Name        string    `gorm:"size:50;not null" json:"name"` // 网站名称
-	URL         string    `gorm:"size:255;not null" json:"url"` // 网址
-	Description string    `gorm:"size:255" json:"description"`  // 描述
-	Sort        int       `gorm:"default:0" json:"sort"`        // 排序权重
+	PostID      string    `gorm:"type:char(36);index;not null" json:"post_id"`
+	ParentID    *string   `gorm:"type:char(36);index" json:"parent_id"` // 为空表示顶级评论
+	AuthorName  string    `gorm:"size:50;not null" json:"author_name"`
+	AuthorEmail string    `gorm:"size:100;not null" json:"author_email,omitempty"`
+	AuthorURL   string    `gorm:"size:255" json:"author_url"`
+	Content     string    `gorm:"type:text;not null" json:"content"`
+	Status      string    `gorm:"size:20;default:'pending';index" json:"status"`
+	IP          string    `gorm:"size:64" json:"ip,omitempty"`
+	UserAgent   string    `gorm:"size:255" json:"user_agent,omitempty"`
 	CreatedAt   time.Time `gorm:"index" json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 子评论 (仅用于组装评论树，不落库)
+	Replies []Comment `gorm:"-" json:"replies,omitempty"`
 }
 
-func (l *Link) BeforeCreate(tx *gorm.DB) (err error) {
-	l.ID = uuid.NewString()
+func (c *Comment) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.NewString()
+	return
+}
+
+// 🕘 PostRevision 文章修订历史 (保存每次更新前的内容)
+type PostRevision struct {
+	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
+	PostID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_post_version" json:"post_id"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_post_version" json:"version"` // 文章内递增的修订号
+	Title     string    `gorm:"size:255;not null" json:"title"`
+	Content   string    `gorm:"type:longtext;not null" json:"content"`
+	Summary   string    `gorm:"size:500" json:"summary"`
+	TagIDs    []string  `gorm:"type:text;serializer:json" json:"tag_ids"`
+	EditorID  string    `gorm:"type:char(36);index" json:"editor_id"` // 本次修改的操作者
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r *PostRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
+
+// 🖼️ Media 媒体文件表
+type Media struct {
+	ID           string    `gorm:"type:char(36);primaryKey" json:"id"`
+	FileName     string    `gorm:"size:255;not null" json:"file_name"` // 原始文件名
+	MimeType     string    `gorm:"size:50;not null" json:"mime_type"`
+	Size         int64     `gorm:"not null" json:"size"` // 字节数
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Key          string    `gorm:"size:255;unique;not null" json:"key"` // 存储路径
+	URL          string    `gorm:"size:255;not null" json:"url"`
+	ThumbnailKey string    `gorm:"size:255" json:"-"`
+	ThumbnailURL string    `gorm:"size:255" json:"thumbnail_url"`
+	WebPKey      string    `gorm:"size:255" json:"-"`
+	WebPURL      string    `gorm:"size:255" json:"webp_url"`
+	UploaderID   string    `gorm:"type:char(36);index" json:"uploader_id"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (m *Media) BeforeCreate(tx *gorm.DB) (err error) {
+	m.ID = uuid.NewString()
 	return
 }
@@ -0,0 +1,78 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Entry 从其他博客系统导入 (或导出到其他系统) 的一篇文章
+type Entry struct {
+	Source     string // 来源文件名或 WXR 中的文章链接，用于导入报告
+	Title      string
+	Slug       string
+	Content    string // Markdown 正文 (WXR 导入时为 HTML，渲染时原样保留)
+	Summary    string
+	Cover      string
+	Author     string // 作者用户名
+	Date       time.Time
+	Updated    time.Time
+	Draft      bool
+	Tags       []Term
+	Categories []Term // 按出现顺序，Hexo 的多级分类会展开
+}
+
+// Term 分类或标签，Slug 为空时由 Name 生成
+type Term struct {
+	Name string
+	Slug string
+}
+
+// NewTerm 根据名称创建分类 / 标签
+func NewTerm(name string) Term {
+	name = strings.TrimSpace(name)
+	return Term{Name: name, Slug: Slugify(name)}
+}
+
+// Slugify 生成 URL 别名：转为小写，保留字母 (包括中文等 Unicode 字符) 与数字，其余字符替换为 "-"
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// dateLayouts 前置元数据中常见的日期格式 (不含时区时按本地时间解析)
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// parseDate 解析日期字符串
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
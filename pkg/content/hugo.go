@@ -0,0 +1,81 @@
+package content
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HugoDir Hugo 站点中文章所在的目录
+const HugoDir = "content/posts"
+
+// hugoFrontMatter 导出的 YAML 前置元数据 (字段顺序即输出顺序)
+type hugoFrontMatter struct {
+	Title      string    `yaml:"title"`
+	Slug       string    `yaml:"slug"`
+	Date       time.Time `yaml:"date"`
+	Lastmod    time.Time `yaml:"lastmod,omitempty"`
+	Draft      bool      `yaml:"draft"`
+	Summary    string    `yaml:"summary,omitempty"`
+	Cover      string    `yaml:"cover,omitempty"`
+	Author     string    `yaml:"author,omitempty"`
+	Categories []string  `yaml:"categories,omitempty"`
+	Tags       []string  `yaml:"tags,omitempty"`
+}
+
+// HugoPath 文章在 Hugo 站点中的文件路径
+// slug 含路径分隔符或 ".." 时改用 Slugify 后的结果，避免写出导出目录 (zip 中同理)
+func HugoPath(e *Entry) string {
+	name := e.Slug
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		name = Slugify(name)
+	}
+	if name == "" {
+		name = "post"
+	}
+	return path.Join(HugoDir, name+".md")
+}
+
+// WriteHugo 将文章写为 Hugo 兼容的 Markdown 文件 (YAML 前置元数据)
+func WriteHugo(w io.Writer, e *Entry) error {
+	fm := hugoFrontMatter{
+		Title:   e.Title,
+		Slug:    e.Slug,
+		Date:    e.Date,
+		Draft:   e.Draft,
+		Summary: e.Summary,
+		Cover:   e.Cover,
+		Author:  e.Author,
+	}
+	if !e.Updated.Equal(e.Date) {
+		fm.Lastmod = e.Updated
+	}
+	for _, c := range e.Categories {
+		fm.Categories = append(fm.Categories, c.Name)
+	}
+	for _, t := range e.Tags {
+		fm.Tags = append(fm.Tags, t.Name)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(yamlDelimiter + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(fm); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	buf.WriteString(yamlDelimiter + "\n\n")
+	buf.WriteString(e.Content)
+	if len(e.Content) > 0 && e.Content[len(e.Content)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,197 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 前置元数据分隔符：YAML (Hexo / Hugo) 与 TOML (Hugo)
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// frontMatter Hexo / Hugo 前置元数据中使用的字段
+type frontMatter struct {
+	Title       string `yaml:"title" toml:"title"`
+	Date        any    `yaml:"date" toml:"date"`
+	Updated     any    `yaml:"updated" toml:"updated"` // Hexo
+	Lastmod     any    `yaml:"lastmod" toml:"lastmod"` // Hugo
+	Slug        string `yaml:"slug" toml:"slug"`
+	Tags        any    `yaml:"tags" toml:"tags"`             // 字符串或列表
+	Categories  any    `yaml:"categories" toml:"categories"` // 字符串或列表，Hexo 的多级分类为嵌套列表
+	Draft       bool   `yaml:"draft" toml:"draft"`           // Hugo
+	Published   *bool  `yaml:"published" toml:"published"`   // Hexo / Jekyll
+	Summary     string `yaml:"summary" toml:"summary"`
+	Description string `yaml:"description" toml:"description"`
+	Excerpt     string `yaml:"excerpt" toml:"excerpt"`
+	Cover       string `yaml:"cover" toml:"cover"`
+	Image       string `yaml:"image" toml:"image"`
+	Author      any    `yaml:"author" toml:"author"` // 字符串或列表 (Hugo authors 取第一个)
+	Authors     any    `yaml:"authors" toml:"authors"`
+}
+
+// ParseMarkdown 解析带 YAML (---) 或 TOML (+++) 前置元数据的 Markdown 文件 (Hexo / Hugo)
+// name 为文件相对路径：slug 缺省时使用文件名 (Hugo 页面包 index.md 使用目录名)，位于 _drafts 目录下的文件视为草稿
+func ParseMarkdown(name string, data []byte) (*Entry, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var fm frontMatter
+	body := text
+	switch {
+	case strings.HasPrefix(text, yamlDelimiter+"\n"):
+		raw, rest, err := splitFrontMatter(text, yamlDelimiter)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal([]byte(raw), &fm); err != nil {
+			return nil, fmt.Errorf("invalid YAML front matter: %w", err)
+		}
+		body = rest
+	case strings.HasPrefix(text, tomlDelimiter+"\n"):
+		raw, rest, err := splitFrontMatter(text, tomlDelimiter)
+		if err != nil {
+			return nil, err
+		}
+		if err := toml.Unmarshal([]byte(raw), &fm); err != nil {
+			return nil, fmt.Errorf("invalid TOML front matter: %w", err)
+		}
+		body = rest
+	default:
+		return nil, errors.New("front matter not found")
+	}
+
+	e := &Entry{
+		Source:  name,
+		Title:   strings.TrimSpace(fm.Title),
+		Slug:    strings.TrimSpace(fm.Slug),
+		Content: strings.TrimLeft(body, "\n"),
+		Summary: firstNonEmpty(fm.Summary, fm.Description, fm.Excerpt),
+		Cover:   firstNonEmpty(fm.Cover, fm.Image),
+		Draft:   fm.Draft || (fm.Published != nil && !*fm.Published),
+	}
+	if e.Title == "" {
+		return nil, errors.New("title is required")
+	}
+	if e.Slug == "" {
+		e.Slug = fileSlug(name)
+	}
+	if strings.Contains("/"+path.Dir(name)+"/", "/_drafts/") {
+		e.Draft = true
+	}
+	if authors := stringList(fm.Author); len(authors) > 0 {
+		e.Author = authors[0]
+	} else if authors := stringList(fm.Authors); len(authors) > 0 {
+		e.Author = authors[0]
+	}
+
+	var err error
+	if e.Date, err = toTime(fm.Date); err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	if e.Updated, err = toTime(firstNonNil(fm.Lastmod, fm.Updated)); err != nil {
+		return nil, fmt.Errorf("updated: %w", err)
+	}
+	for _, name := range stringList(fm.Tags) {
+		e.Tags = append(e.Tags, NewTerm(name))
+	}
+	for _, name := range stringList(fm.Categories) {
+		e.Categories = append(e.Categories, NewTerm(name))
+	}
+	return e, nil
+}
+
+// splitFrontMatter 拆分前置元数据与正文
+func splitFrontMatter(text, delimiter string) (string, string, error) {
+	rest := text[len(delimiter)+1:]
+	if strings.HasPrefix(rest, delimiter+"\n") || rest == delimiter {
+		return "", strings.TrimPrefix(rest, delimiter), nil
+	}
+	end := strings.Index(rest, "\n"+delimiter+"\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n"+delimiter) {
+			return "", "", errors.New("unterminated front matter")
+		}
+		end = len(rest) - len(delimiter) - 1
+	}
+	body := ""
+	if next := end + len(delimiter) + 2; next < len(rest) {
+		body = rest[next:]
+	}
+	return rest[:end], body, nil
+}
+
+// fileSlug 由文件路径生成 slug
+func fileSlug(name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if base == "index" || base == "_index" {
+		base = path.Base(path.Dir(name))
+	}
+	return Slugify(base)
+}
+
+// stringList 将字符串、列表或嵌套列表展开为字符串列表 (去除空值)
+func stringList(v any) []string {
+	var list []string
+	switch v := v.(type) {
+	case nil:
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			list = append(list, s)
+		}
+	case []any:
+		for _, item := range v {
+			list = append(list, stringList(item)...)
+		}
+	default:
+		list = append(list, stringList(fmt.Sprint(v))...)
+	}
+	return list
+}
+
+// toTime 转换前置元数据中的日期 (YAML 时间戳、TOML 日期或字符串)
+func toTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return v, nil
+	case toml.LocalDateTime:
+		return v.AsTime(time.Local), nil
+	case toml.LocalDate:
+		return v.AsTime(time.Local), nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return time.Time{}, nil
+		}
+		return parseDate(v)
+	default:
+		return parseDate(fmt.Sprint(v))
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstNonNil(values ...any) any {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package content
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WordPress 导出文件 (WXR) 中的日期格式与未设置时的占位值
+const (
+	wxrDateLayout = "2006-01-02 15:04:05"
+	wxrZeroDate   = "0000-00-00 00:00:00"
+)
+
+// WXR 使用的命名空间只以前缀区分，encoding/xml 按元素本地名匹配，
+// content:encoded 与 excerpt:encoded 通过命名空间 URI 区分
+const (
+	wxrContentNS = "http://purl.org/rss/1.0/modules/content/"
+	wxrExcerptNS = "http://wordpress.org/export/1.2/excerpt/"
+)
+
+type wxrDocument struct {
+	Channel struct {
+		Items []wxrItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Creator    string        `xml:"creator"`
+	Encoded    []wxrEncoded  `xml:"encoded"`
+	PostDate   string        `xml:"post_date"`
+	PostDateGM string        `xml:"post_date_gmt"`
+	Modified   string        `xml:"post_modified"`
+	ModifiedGM string        `xml:"post_modified_gmt"`
+	PostName   string        `xml:"post_name"`
+	Status     string        `xml:"status"`
+	PostType   string        `xml:"post_type"`
+	Categories []wxrCategory `xml:"category"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	NiceName string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+// ParseWXR 解析 WordPress 导出文件 (WXR)，只返回文章 (post_type 为 post)，跳过页面、附件与回收站中的文章
+// 正文为 WordPress 保存的 HTML，段落以空行分隔，可直接作为 Markdown 渲染
+func ParseWXR(r io.Reader) ([]*Entry, error) {
+	var doc wxrDocument
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid WXR file: %w", err)
+	}
+
+	entries := make([]*Entry, 0, len(doc.Channel.Items))
+	for _, item := range doc.Channel.Items {
+		if item.PostType != "post" || item.Status == "trash" || item.Status == "auto-draft" || item.Status == "inherit" {
+			continue
+		}
+		e := &Entry{
+			Source: item.Link,
+			Title:  strings.TrimSpace(item.Title),
+			Author: strings.TrimSpace(item.Creator),
+			Date:   wxrDate(item.PostDateGM, item.PostDate),
+			// 草稿、待审与私密文章均作为草稿导入，future 为定时发布 (由发布时间决定)
+			Draft: item.Status != "publish" && item.Status != "future",
+		}
+		e.Updated = wxrDate(item.ModifiedGM, item.Modified)
+		for _, enc := range item.Encoded {
+			switch enc.XMLName.Space {
+			case wxrContentNS:
+				e.Content = enc.Value
+			case wxrExcerptNS:
+				e.Summary = strings.TrimSpace(enc.Value)
+			}
+		}
+		if slug, err := url.PathUnescape(item.PostName); err == nil {
+			e.Slug = slug
+		}
+		if e.Slug == "" {
+			e.Slug = Slugify(e.Title)
+		}
+		if e.Source == "" {
+			e.Source = e.Slug
+		}
+		for _, c := range item.Categories {
+			term := NewTerm(c.Name)
+			if slug, err := url.PathUnescape(c.NiceName); err == nil && slug != "" {
+				term.Slug = slug
+			}
+			switch c.Domain {
+			case "category":
+				e.Categories = append(e.Categories, term)
+			case "post_tag":
+				e.Tags = append(e.Tags, term)
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// wxrDate 优先使用 UTC 时间，草稿的 UTC 时间未设置时按本地时间解析
+func wxrDate(gmt, local string) time.Time {
+	if gmt != "" && gmt != wxrZeroDate {
+		if t, err := time.ParseInLocation(wxrDateLayout, gmt, time.UTC); err == nil {
+			return t
+		}
+	}
+	if local != "" && local != wxrZeroDate {
+		if t, err := time.ParseInLocation(wxrDateLayout, local, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -3,36 +3,171 @@ package crypto
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"errors"
+	"go-blog/pkg/keystore"
+	"os"
 	"sync"
+	"time"
 )
 
+// 登录密码加密算法
+const (
+	AlgorithmOAEP     = "RSA-OAEP-256" // RSA-OAEP (SHA-256)，默认
+	AlgorithmPKCS1v15 = "RSA1_5"       // PKCS#1 v1.5，仅用于兼容旧客户端
+)
+
+const defaultBits = 2048
+
+// Config 登录加密密钥配置
+type Config struct {
+	Dir            string        // 密钥持久化目录 (多实例可共享)，为空时仅保存在内存，重启后失效
+	PrivateKey     string        // 固定私钥 (PEM)，设置后不再轮换
+	Bits           int           // 密钥长度，默认 2048
+	RotateInterval time.Duration // 轮换周期，0 表示不轮换
+	LegacyPKCS1v15 bool          // 是否接受 PKCS#1 v1.5 填充的密文
+}
+
 // RSAKeyPair 存储密钥对
 type RSAKeyPair struct {
+	ID         string // 密钥 ID (kid)
 	PrivateKey *rsa.PrivateKey
 	PublicKey  string // PEM 格式的公钥字符串
+	CreatedAt  time.Time
+}
+
+// PublicKeyInfo 提供给前端的当前公钥
+type PublicKeyInfo struct {
+	KID       string `json:"kid"`
+	PublicKey string `json:"public_key"`
+	Algorithm string `json:"algorithm"`
 }
 
-// 单例模式
+// 当前密钥与上一个密钥 (轮换后仍接受用旧公钥加密的密文)
 var (
-	GlobalKeyPair *RSAKeyPair
-	once          sync.Once
+	mu       sync.RWMutex
+	cfg      = Config{Bits: defaultBits}
+	current  *RSAKeyPair
+	previous *RSAKeyPair
 )
 
-// InitRSAKeyPair 初始化 RSA 密钥对 (2048位)
-func InitRSAKeyPair() error {
-	var err error
-	once.Do(func() {
-		RSAKeyPair, e := generateKeyPair(2048)
-		GlobalKeyPair = RSAKeyPair
-		err = e
-	})
+// Init 加载或生成登录加密密钥：优先使用配置的固定私钥，其次读取密钥目录，均不存在时生成新密钥
+func Init(c *Config) error {
+	if c == nil {
+		return errors.New("crypto config is nil")
+	}
+
+	mu.Lock()
+	cfg = *c
+	if cfg.Bits <= 0 {
+		cfg.Bits = defaultBits
+	}
+	current, previous = nil, nil
+	mu.Unlock()
+
+	if cfg.PrivateKey != "" {
+		key, err := parsePrivateKey([]byte(cfg.PrivateKey))
+		if err != nil {
+			return err
+		}
+		kp, err := newKeyPair(key, time.Time{})
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		current = kp
+		mu.Unlock()
+		return nil
+	}
+
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+			return err
+		}
+	}
+	_, err := RotateIfDue()
 	return err
 }
 
+// RotateIfDue 重新加载密钥目录 (同步其他实例生成的密钥)，当前密钥超过轮换周期时生成新密钥
+func RotateIfDue() (bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if cfg.PrivateKey != "" {
+		return false, nil
+	}
+
+	keys, err := loadKeys()
+	if err != nil {
+		return false, err
+	}
+
+	rotated := false
+	if len(keys) == 0 || (cfg.RotateInterval > 0 && time.Since(keys[0].CreatedAt) >= cfg.RotateInterval) {
+		kp, err := generateKeyPair(cfg.Bits)
+		if err != nil {
+			return false, err
+		}
+		if err := saveKey(kp); err != nil {
+			return false, err
+		}
+		keys = append([]*RSAKeyPair{kp}, keys...)
+		rotated = true
+	}
+
+	// 只保留当前与上一个密钥
+	keys = keystore.Prune(cfg.Dir, keys, func(kp *RSAKeyPair) string { return kp.ID }, func(i int) bool { return i < 2 })
+	current, previous = keys[0], nil
+	if len(keys) > 1 {
+		previous = keys[1]
+	}
+	return rotated, nil
+}
+
+// loadKeys 按创建时间倒序返回全部密钥 (未配置目录时返回内存中的密钥)
+func loadKeys() ([]*RSAKeyPair, error) {
+	if cfg.Dir == "" {
+		var keys []*RSAKeyPair
+		for _, kp := range []*RSAKeyPair{current, previous} {
+			if kp != nil {
+				keys = append(keys, kp)
+			}
+		}
+		return keys, nil
+	}
+
+	entries, err := keystore.Load(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*RSAKeyPair, 0, len(entries))
+	for _, e := range entries {
+		key, ok := e.Key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("invalid key file " + e.ID + ": not an RSA private key")
+		}
+		kp, err := newKeyPair(key, e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		kp.ID = e.ID
+		keys = append(keys, kp)
+	}
+	return keys, nil
+}
+
+// saveKey 将密钥写入密钥目录 (未配置目录时只保存在内存)
+func saveKey(kp *RSAKeyPair) error {
+	if cfg.Dir == "" {
+		return nil
+	}
+	return keystore.Save(cfg.Dir, kp.ID, kp.PrivateKey)
+}
+
 // generateKeyPair 生成私钥和公钥
 func generateKeyPair(bits int) (*RSAKeyPair, error) {
 	// 生成私钥
@@ -40,10 +175,12 @@ func generateKeyPair(bits int) (*RSAKeyPair, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newKeyPair(privateKey, time.Now().UTC())
+}
 
-	// 生成公钥 PEM 字符串
-	publicKey := &privateKey.PublicKey
-	pubASN1, err := x509.MarshalPKIXPublicKey(publicKey)
+// newKeyPair 生成公钥 PEM 与 kid (创建时间 + 公钥指纹)
+func newKeyPair(privateKey *rsa.PrivateKey, createdAt time.Time) (*RSAKeyPair, error) {
+	pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
 	if err != nil {
 		return nil, err
 	}
@@ -53,17 +190,63 @@ func generateKeyPair(bits int) (*RSAKeyPair, error) {
 		Bytes: pubASN1,
 	})
 
+	// 固定私钥没有创建时间，kid 只使用公钥指纹
+	kid, err := keystore.Thumbprint(&privateKey.PublicKey)
+	if !createdAt.IsZero() {
+		kid, err = keystore.NewID(&privateKey.PublicKey, createdAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	return &RSAKeyPair{
+		ID:         kid,
 		PrivateKey: privateKey,
 		PublicKey:  string(pubBytes),
+		CreatedAt:  createdAt,
 	}, nil
 }
 
-// Decrypt 使用私钥解密
-func Decrypt(encryptedBase64 string) (string, error) {
-	if GlobalKeyPair == nil || GlobalKeyPair.PrivateKey == nil {
+// parsePrivateKey 解析 PEM 私钥 (支持 PKCS#1 与 PKCS#8)
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return key, nil
+}
+
+// Decrypt 使用私钥解密。kid 为空时依次尝试当前与上一个密钥；
+// 默认使用 RSA-OAEP (SHA-256)，开启兼容模式时同时接受 PKCS#1 v1.5
+func Decrypt(encryptedBase64, kid string) (string, error) {
+	mu.RLock()
+	keys := make([]*RSAKeyPair, 0, 2)
+	for _, kp := range []*RSAKeyPair{current, previous} {
+		if kp != nil && (kid == "" || kp.ID == kid) {
+			keys = append(keys, kp)
+		}
+	}
+	initialized := current != nil
+	legacy := cfg.LegacyPKCS1v15
+	mu.RUnlock()
+
+	if !initialized {
 		return "", errors.New("RSA key pair not initialized")
 	}
+	if len(keys) == 0 {
+		return "", errors.New("unknown or expired key id")
+	}
 
 	// Base64 解码
 	encryptedBytes, err := base64.StdEncoding.DecodeString(encryptedBase64)
@@ -71,19 +254,29 @@ func Decrypt(encryptedBase64 string) (string, error) {
 		return "", errors.New("invalid base64 string")
 	}
 
-	// RSA 解密 (PKCS1v15 padding)
-	decryptedBytes, err := rsa.DecryptPKCS1v15(rand.Reader, GlobalKeyPair.PrivateKey, encryptedBytes)
-	if err != nil {
-		return "", errors.New("decryption failed")
+	for _, kp := range keys {
+		if decrypted, err := rsa.DecryptOAEP(sha256.New(), nil, kp.PrivateKey, encryptedBytes, nil); err == nil {
+			return string(decrypted), nil
+		}
+		if legacy {
+			if decrypted, err := rsa.DecryptPKCS1v15(nil, kp.PrivateKey, encryptedBytes); err == nil {
+				return string(decrypted), nil
+			}
+		}
 	}
-
-	return string(decryptedBytes), nil
+	return "", errors.New("decryption failed")
 }
 
-// GetPublicKey 获取公钥字符串
-func GetPublicKey() (string, error) {
-	if GlobalKeyPair == nil {
-		return "", errors.New("RSA key pair not initialized")
+// GetPublicKey 获取当前公钥及其 kid
+func GetPublicKey() (*PublicKeyInfo, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return nil, errors.New("RSA key pair not initialized")
 	}
-	return GlobalKeyPair.PublicKey, nil
+	return &PublicKeyInfo{
+		KID:       current.ID,
+		PublicKey: current.PublicKey,
+		Algorithm: AlgorithmOAEP,
+	}, nil
 }
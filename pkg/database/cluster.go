@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"go-blog/config"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// clusterPlugin Cluster 注册到主库 gorm.DB 的插件名
+const clusterPlugin = "go-blog:cluster"
+
+// 连接角色与状态
+const (
+	RolePrimary = "primary"
+	RoleReplica = "replica"
+
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// Cluster 只读副本集合，以插件形式注册到主库
+// 写入与普通查询始终使用主库，只有通过 Reader 获取的连接才会使用副本
+type Cluster struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	name    string
+	cfg     *config.DatabaseConfig
+	db      atomic.Pointer[gorm.DB] // 连接失败时为空，健康检查时重新连接
+	healthy atomic.Bool             // 由健康检查更新，启动后首次检查前视为不可用
+}
+
+// connect 建立副本连接 (不检查连接是否可用)
+func (r *replica) connect() error {
+	dialector, err := NewDialector(r.cfg)
+	if err != nil {
+		return err
+	}
+	db, err := open(dialector, &r.cfg.Pool, false)
+	if err != nil {
+		return err
+	}
+	r.db.Store(db)
+	return nil
+}
+
+func (c *Cluster) Name() string {
+	return clusterPlugin
+}
+
+func (c *Cluster) Initialize(db *gorm.DB) error {
+	return nil
+}
+
+// pick 轮询选择健康的副本，没有可用副本时返回 nil
+func (c *Cluster) pick() *replica {
+	n := len(c.replicas)
+	start := c.next.Add(1)
+	for i := 0; i < n; i++ {
+		r := c.replicas[(int(start)+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// Reader 获取只读查询使用的连接：优先使用健康的副本，
+// 未配置副本、副本均不可用或处于事务中时返回 db 本身
+func Reader(db *gorm.DB) *gorm.DB {
+	c, ok := db.Config.Plugins[clusterPlugin].(*Cluster)
+	if !ok {
+		return db
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db
+	}
+	r := c.pick()
+	if r == nil {
+		return db
+	}
+	return r.db.Load().WithContext(db.Statement.Context)
+}
+
+// Health 单个数据库连接的健康状态
+type Health struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Check 检查主库与所有副本的连接，并更新副本的可用状态
+func Check(ctx context.Context, db *gorm.DB) []Health {
+	list := []Health{ping(ctx, RolePrimary, RolePrimary, db)}
+	c, ok := db.Config.Plugins[clusterPlugin].(*Cluster)
+	if !ok {
+		return list
+	}
+	for _, r := range c.replicas {
+		var h Health
+		if r.db.Load() == nil {
+			if err := r.connect(); err != nil {
+				h = Health{Name: r.name, Role: RoleReplica, Status: StatusDown, Error: err.Error()}
+			}
+		}
+		if h.Status == "" {
+			h = ping(ctx, r.name, RoleReplica, r.db.Load())
+		}
+		r.healthy.Store(h.Status == StatusUp)
+		list = append(list, h)
+	}
+	return list
+}
+
+// ping 检查单个连接 (超时 3 秒)
+func ping(ctx context.Context, name, role string, db *gorm.DB) Health {
+	h := Health{Name: name, Role: role, Status: StatusUp}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	h.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		h.Status = StatusDown
+		h.Error = err.Error()
+	}
+	return h
+}
+
+// HasReplicas 是否配置了只读副本
+func HasReplicas(db *gorm.DB) bool {
+	c, ok := db.Config.Plugins[clusterPlugin].(*Cluster)
+	return ok && len(c.replicas) > 0
+}
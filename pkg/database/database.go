@@ -0,0 +1,184 @@
+package database
+
+import (
+	"fmt"
+	"go-blog/config"
+	"go-blog/pkg/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open 根据配置的驱动连接主库 (未就绪时按指数退避重试)，并注册只读副本
+func Open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Replicas) == 0 {
+		return db, nil
+	}
+
+	cluster := &Cluster{}
+	for i, rc := range cfg.Replicas {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("replica-%d", i+1)
+		}
+		r := &replica{name: name, cfg: replicaConfig(cfg, rc)}
+		// 副本启动时不可用不影响服务启动，由健康检查重新连接并标记可用后再使用
+		if err := r.connect(); err != nil {
+			logger.Log.Warnf("⚠️ Failed to connect database replica %s: %v", name, err)
+		}
+		cluster.replicas = append(cluster.replicas, r)
+	}
+	if err := db.Use(cluster); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// connect 连接主库，失败时按 1s、2s、4s ... 重试
+func connect(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	maxBackoff := time.Duration(cfg.Connect.MaxBackoffSeconds) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		dialector, err := NewDialector(cfg)
+		if err != nil {
+			return nil, err
+		}
+		db, err := open(dialector, &cfg.Pool, true)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= cfg.Connect.Retries {
+			return nil, err
+		}
+		logger.Log.Warnf("⏳ Database is not ready (%v), retrying in %s (%d/%d)", err, backoff, attempt+1, cfg.Connect.Retries)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// open 打开连接并设置连接池，ping 为 true 时立即检查连接是否可用
+func open(dialector gorm.Dialector, pool *config.DatabasePoolConfig, ping bool) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			// 使用单数表名 User -> user
+			SingularTable: true,
+		},
+		DisableAutomaticPing: !ping,
+	})
+	if err != nil {
+		// 连接失败时关闭已创建的连接池，避免重试时泄漏
+		if db != nil {
+			if sqlDB, e := db.DB(); e == nil {
+				sqlDB.Close()
+			}
+		}
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(pool.ConnMaxLifetimeMinutes) * time.Minute)
+	}
+	if pool.ConnMaxIdleTimeMinutes > 0 {
+		sqlDB.SetConnMaxIdleTime(time.Duration(pool.ConnMaxIdleTimeMinutes) * time.Minute)
+	}
+	return db, nil
+}
+
+// replicaConfig 合并副本与主库配置
+func replicaConfig(cfg *config.DatabaseConfig, rc config.DatabaseReplicaConfig) *config.DatabaseConfig {
+	merged := *cfg
+	merged.Replicas = nil
+	if rc.Host != "" {
+		merged.Host = rc.Host
+	}
+	if rc.Port != 0 {
+		merged.Port = rc.Port
+	}
+	if rc.User != "" {
+		merged.User = rc.User
+	}
+	if rc.Password != "" {
+		merged.Password = rc.Password
+	}
+	if rc.Path != "" {
+		merged.Path = rc.Path
+	}
+	return &merged
+}
+
+// NewDialector 根据驱动生成连接配置 (不建立连接)
+func NewDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Name,
+			cfg.Charset,
+			cfg.ParseTime,
+			cfg.Loc,
+		)
+		return mysql.Open(dsn), nil
+	case DriverSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = "data/blog.db"
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		// WAL 模式允许读写并发，busy_timeout 避免并发写入时立即返回 database is locked
+		return sqlite.Open(path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"), nil
+	case DriverPostgres:
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, quoteValue(cfg.Password), cfg.Name, sslMode)
+		if cfg.Loc != "" && cfg.Loc != "Local" {
+			dsn += " TimeZone=" + cfg.Loc
+		}
+		return postgresDialector{postgres.Open(dsn).(*postgres.Dialector)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// quoteValue 转义 PostgreSQL 连接串中的值 (包含空格或引号时需加单引号)
+func quoteValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
@@ -1,39 +0,0 @@
-package database
-
-import (
-	"fmt"
-	"go-blog/config"
-	"go-blog/pkg/logger"
-
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-	"gorm.io/gorm/schema"
-)
-
-// 初始化 mysql 数据库
-func InitMySQL() (*gorm.DB, error) {
-	dbConfig := config.AppConfig.Database
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
-		dbConfig.User,
-		dbConfig.Password,
-		dbConfig.Host,
-		dbConfig.Port,
-		dbConfig.Name,
-		dbConfig.Charset,
-		dbConfig.ParseTime,
-		dbConfig.Loc,
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		NamingStrategy: schema.NamingStrategy{
-			// 使用单数表名 User -> user
-			SingularTable: true,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	logger.Log.Infof("✅ Database connection successfully!")
-	return db, nil
-}
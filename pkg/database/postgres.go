@@ -0,0 +1,39 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// postgresDialector 将模型中 MySQL 专用的列类型 (如 type:longtext) 转换为 PostgreSQL 支持的类型
+type postgresDialector struct {
+	*postgres.Dialector
+}
+
+// mysqlTextTypes MySQL 文本类型，PostgreSQL 中统一使用 text
+var mysqlTextTypes = map[string]bool{
+	"tinytext":   true,
+	"mediumtext": true,
+	"longtext":   true,
+}
+
+func (d postgresDialector) DataTypeOf(field *schema.Field) string {
+	dataType := d.Dialector.DataTypeOf(field)
+	if mysqlTextTypes[strings.ToLower(dataType)] {
+		return "text"
+	}
+	return dataType
+}
+
+// Migrator 迁移时使用转换后的列类型
+func (d postgresDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return postgres.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
@@ -0,0 +1,215 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+const generator = "blog_go"
+
+// Channel 订阅源的通用描述，可输出为 RSS 2.0 或 Atom 1.0
+type Channel struct {
+	Title       string
+	Link        string // 站点地址
+	FeedURL     string // 订阅源自身地址
+	Description string
+	Author      string
+	Email       string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item 订阅源条目
+type Item struct {
+	Title      string
+	Link       string
+	Summary    string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// ----- RSS 2.0 -----
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string    `xml:"title"`
+	Link           string    `xml:"link"`
+	AtomLink       rssSelf   `xml:"atom:link"`
+	Description    string    `xml:"description"`
+	ManagingEditor string    `xml:"managingEditor,omitempty"`
+	LastBuildDate  string    `xml:"lastBuildDate,omitempty"`
+	Generator      string    `xml:"generator"`
+	Items          []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"author,omitempty"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// RSS 输出 RSS 2.0 文档
+func (ch *Channel) RSS() ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       ch.Title,
+			Link:        ch.Link,
+			AtomLink:    rssSelf{Href: ch.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Description: ch.Description,
+			Generator:   generator,
+			Items:       make([]rssItem, 0, len(ch.Items)),
+		},
+	}
+	// RSS 规范要求 managingEditor 为 "email (name)" 格式
+	if ch.Email != "" {
+		doc.Channel.ManagingEditor = formatPerson(ch.Email, ch.Author)
+	}
+	if !ch.Updated.IsZero() {
+		doc.Channel.LastBuildDate = ch.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, it := range ch.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			Description: it.Summary,
+			Categories:  it.Categories,
+			GUID:        rssGUID{Value: it.Link, IsPermaLink: true},
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+		}
+		if ch.Email != "" && it.Author != "" {
+			item.Author = formatPerson(ch.Email, it.Author)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return marshal(doc)
+}
+
+func formatPerson(email, name string) string {
+	if name == "" {
+		return email
+	}
+	return fmt.Sprintf("%s (%s)", email, name)
+}
+
+// ----- Atom 1.0 -----
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Gen      string      `xml:"generator"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+}
+
+// Atom 输出 Atom 1.0 文档
+func (ch *Channel) Atom() ([]byte, error) {
+	updated := ch.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomFeed{
+		Title:    ch.Title,
+		Subtitle: ch.Description,
+		ID:       ch.FeedURL,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: ch.Link, Rel: "alternate", Type: "text/html"},
+			{Href: ch.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Gen:     generator,
+		Entries: make([]atomEntry, 0, len(ch.Items)),
+	}
+	// Atom 要求 feed 或每个 entry 至少有一个 author
+	name := ch.Author
+	if name == "" {
+		name = ch.Title
+	}
+	doc.Author = &atomPerson{Name: name, Email: ch.Email}
+
+	for _, it := range ch.Items {
+		entry := atomEntry{
+			Title:     it.Title,
+			ID:        it.Link,
+			Link:      atomLink{Href: it.Link, Rel: "alternate", Type: "text/html"},
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Summary:   it.Summary,
+		}
+		if it.Author != "" {
+			entry.Author = &atomPerson{Name: it.Author}
+		}
+		for _, c := range it.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
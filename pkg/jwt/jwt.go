@@ -1,29 +1,44 @@
 package jwt
 
 import (
-	"crypto/rsa"
 	"errors"
-	"os"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Config struct {
+	// 单密钥配置 (未配置 Keys 时使用)
 	Algorithm      string
 	Secret         string
 	PrivateKeyPath string
 	PublicKeyPath  string
-	ExpireHours    int
+
+	Keys     []KeyConfig    // 多密钥，列表中最后一个带私钥的密钥用于签名，其余仅用于验证
+	Rotation RotationConfig // 自动轮换，启用时新令牌使用最新的轮换密钥签名
+
+	AccessExpireMinutes int // 访问令牌有效期 (分钟)
+	RefreshExpireHours  int // 刷新令牌有效期 (小时)
 }
 
+const (
+	defaultAccessExpireMinutes = 15
+	defaultRefreshExpireHours  = 30 * 24
+
+	// ChallengeTTL 两步验证挑战令牌有效期
+	ChallengeTTL = 5 * time.Minute
+	// challengeAudience 挑战令牌的受众，访问令牌校验时拒绝
+	challengeAudience = "login-2fa"
+)
+
 var cfg *Config
-var rsaPrivateKey *rsa.PrivateKey
-var rsaPublicKey *rsa.PublicKey
 
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"` // 所属会话，用于服务端吊销
 	jwt.RegisteredClaims
 }
 
@@ -32,59 +47,51 @@ func Init(c *Config) error {
 	if cfg == nil {
 		return errors.New("jwt config is nil")
 	}
+	if cfg.AccessExpireMinutes <= 0 {
+		cfg.AccessExpireMinutes = defaultAccessExpireMinutes
+	}
+	if cfg.RefreshExpireHours <= 0 {
+		cfg.RefreshExpireHours = defaultRefreshExpireHours
+	}
+	return loadKeys(cfg)
+}
+
+// AccessTokenTTL 访问令牌有效期
+func AccessTokenTTL() time.Duration {
+	if cfg == nil {
+		return defaultAccessExpireMinutes * time.Minute
+	}
+	return time.Duration(cfg.AccessExpireMinutes) * time.Minute
+}
 
-	if cfg.Algorithm == "RS256" {
-		// 读取私钥
-		privBytes, err := os.ReadFile(cfg.PrivateKeyPath)
-		if err != nil {
-			return err
-		}
-		privKey, err := jwt.ParseRSAPrivateKeyFromPEM(privBytes)
-		if err != nil {
-			return err
-		}
-		rsaPrivateKey = privKey
-
-		// 读取公钥
-		pubBytes, err := os.ReadFile(cfg.PublicKeyPath)
-		if err != nil {
-			return err
-		}
-		pubKey, err := jwt.ParseRSAPublicKeyFromPEM(pubBytes)
-		if err != nil {
-			return err
-		}
-		rsaPublicKey = pubKey
-	}
-	return nil
+// RefreshTokenTTL 刷新令牌有效期
+func RefreshTokenTTL() time.Duration {
+	if cfg == nil {
+		return defaultRefreshExpireHours * time.Hour
+	}
+	return time.Duration(cfg.RefreshExpireHours) * time.Hour
 }
 
-// GenerateToken 生成 Token
-func GenerateToken(userID, username string) (string, error) {
+// GenerateToken 生成短期访问 Token，绑定到指定会话
+func GenerateToken(userID, username, role, sessionID string) (string, error) {
 	if cfg == nil {
 		return "", errors.New("jwt not initialized")
 	}
-	exp := time.Hour * time.Duration(cfg.ExpireHours)
 	now := time.Now()
 
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)), // 24小时过期
-			Issuer:    "blog_go",                               // 签发者
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL())),
+			Issuer:    "blog_go", // 签发者
 		},
 	}
 
-	if cfg.Algorithm == "RS256" {
-		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-		return token.SignedString(rsaPrivateKey)
-	}
-
-	// 默认 HS256
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.Secret))
+	return sign(claims)
 }
 
 // ParseToken 解析并验证Token
@@ -94,21 +101,6 @@ func ParseToken(tokenString string) (*Claims, error) {
 	}
 	claims := &Claims{}
 
-	keyFunc := func(token *jwt.Token) (any, error) {
-		// 根据算法验证
-		if cfg.Algorithm == "RS256" {
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return rsaPublicKey, nil
-		}
-		// HS
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(cfg.Secret), nil
-	}
-
 	parsed, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 	if err != nil {
 		return nil, err
@@ -116,5 +108,67 @@ func ParseToken(tokenString string) (*Claims, error) {
 	if !parsed.Valid {
 		return nil, errors.New("invalid token")
 	}
+	// 两步验证挑战令牌不能作为访问令牌使用
+	if slices.Contains(claims.Audience, challengeAudience) {
+		return nil, errors.New("invalid token")
+	}
 	return claims, nil
 }
+
+// GenerateChallengeToken 密码校验通过后签发两步验证挑战令牌 (仅用于换取正式令牌)
+func GenerateChallengeToken(userID string) (string, error) {
+	if cfg == nil {
+		return "", errors.New("jwt not initialized")
+	}
+	now := time.Now()
+
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		Audience:  jwt.ClaimStrings{challengeAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ChallengeTTL)),
+		Issuer:    "blog_go",
+	}
+	return sign(claims)
+}
+
+// ParseChallengeToken 解析两步验证挑战令牌，返回用户 ID
+func ParseChallengeToken(tokenString string) (string, error) {
+	if cfg == nil {
+		return "", errors.New("jwt not initialized")
+	}
+	claims := &jwt.RegisteredClaims{}
+
+	parsed, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, jwt.WithAudience(challengeAudience))
+	if err != nil {
+		return "", err
+	}
+	if !parsed.Valid || claims.Subject == "" {
+		return "", errors.New("invalid challenge token")
+	}
+	return claims.Subject, nil
+}
+
+// sign 使用当前签名密钥签名，并在头部写入 kid
+func sign(claims jwt.Claims) (string, error) {
+	k := signingKey()
+	if k == nil {
+		return "", errors.New("no jwt signing key")
+	}
+	token := jwt.NewWithClaims(signingMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+	return token.SignedString(k.signKey)
+}
+
+// keyFunc 按 kid 选择验证密钥，且令牌算法必须与密钥一致
+func keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k := findKey(kid)
+	if k == nil {
+		return nil, errors.New("unknown key id")
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+	return k.verifyKey, nil
+}
@@ -0,0 +1,395 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"go-blog/pkg/keystore"
+	"math/big"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// 时钟偏差余量：轮换下来的密钥在最后一个令牌过期后再多保留一段时间
+const retireGrace = time.Minute
+
+// KeyConfig 单个签名密钥
+type KeyConfig struct {
+	ID             string // kid，为空时根据公钥指纹生成
+	Algorithm      string // HS256 | RS256 | ES256 | EdDSA
+	Secret         string // HS256 密钥
+	PrivateKeyPath string // 非对称私钥 (PEM)，为空时仅用于验证
+	PublicKeyPath  string // 非对称公钥 (PEM)，配置了私钥时可省略
+}
+
+// RotationConfig 自动轮换：密钥保存在目录中，定期生成新密钥签名，旧密钥在其签发的令牌过期前仍用于验证
+type RotationConfig struct {
+	Dir       string        // 密钥目录 (多实例应共享)，为空表示不启用
+	Algorithm string        // RS256 | ES256 | EdDSA，默认 ES256
+	Interval  time.Duration // 轮换周期
+}
+
+// Key 已加载的密钥
+type Key struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time // 仅轮换生成的密钥有值
+	signKey   any       // 为 nil 时只能验证
+	verifyKey any
+}
+
+// JWK RFC 7517 公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json 响应
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// 密钥环：轮换密钥 (由新到旧) 在前，静态配置的密钥在后；第一个带私钥的密钥用于签名
+var (
+	keyMu       sync.RWMutex
+	staticKeys  []*Key
+	rotatedKeys []*Key
+)
+
+// loadKeys 根据配置加载静态密钥，并在启用轮换时加载 / 生成轮换密钥
+func loadKeys(c *Config) error {
+	configs := c.Keys
+	if len(configs) == 0 && legacyConfigured(c) {
+		configs = []KeyConfig{{
+			Algorithm:      c.Algorithm,
+			Secret:         c.Secret,
+			PrivateKeyPath: c.PrivateKeyPath,
+			PublicKeyPath:  c.PublicKeyPath,
+		}}
+	}
+
+	static := make([]*Key, 0, len(configs))
+	for _, kc := range configs {
+		k, err := loadKey(kc)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(static, func(o *Key) bool { return o.ID == k.ID }) {
+			return fmt.Errorf("duplicate jwt key id %q", k.ID)
+		}
+		static = append(static, k)
+	}
+	// 配置列表中靠后的密钥更新
+	slices.Reverse(static)
+
+	keyMu.Lock()
+	staticKeys, rotatedKeys = static, nil
+	keyMu.Unlock()
+
+	if c.Rotation.Dir != "" {
+		if c.Rotation.Algorithm == "" {
+			c.Rotation.Algorithm = ES256
+		}
+		if c.Rotation.Algorithm == HS256 {
+			return errors.New("key rotation requires an asymmetric algorithm")
+		}
+		if err := os.MkdirAll(c.Rotation.Dir, 0o700); err != nil {
+			return err
+		}
+		if _, err := RotateIfDue(); err != nil {
+			return err
+		}
+	}
+
+	if signingKey() == nil {
+		return errors.New("no jwt signing key configured")
+	}
+	return nil
+}
+
+// legacyConfigured 是否配置了旧版单密钥 (algorithm + secret / 密钥路径)
+func legacyConfigured(c *Config) bool {
+	if c.Algorithm == RS256 || c.Algorithm == ES256 || c.Algorithm == EdDSA {
+		return c.PrivateKeyPath != "" || c.PublicKeyPath != ""
+	}
+	return c.Secret != ""
+}
+
+// loadKey 加载单个静态密钥
+func loadKey(kc KeyConfig) (*Key, error) {
+	alg := kc.Algorithm
+	if alg == "" {
+		alg = HS256
+	}
+	k := &Key{ID: kc.ID, Algorithm: alg}
+
+	if alg == HS256 {
+		if kc.Secret == "" {
+			return nil, errors.New("HS256 key requires a secret")
+		}
+		k.signKey, k.verifyKey = []byte(kc.Secret), []byte(kc.Secret)
+		if k.ID == "" {
+			k.ID = "default"
+		}
+		return k, nil
+	}
+
+	switch {
+	case kc.PrivateKeyPath != "":
+		data, err := os.ReadFile(kc.PrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if k.signKey, k.verifyKey, err = parsePrivateKey(alg, data); err != nil {
+			return nil, err
+		}
+	case kc.PublicKeyPath != "":
+		data, err := os.ReadFile(kc.PublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if k.verifyKey, err = parsePublicKey(alg, data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s key requires a private or public key path", alg)
+	}
+
+	if k.ID == "" {
+		id, err := keystore.Thumbprint(k.verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		k.ID = id
+	}
+	return k, nil
+}
+
+// RotateIfDue 重新加载轮换目录 (同步其他实例生成的密钥)，最新密钥超过轮换周期时生成新密钥，并清理已无有效令牌的旧密钥
+func RotateIfDue() (bool, error) {
+	if cfg == nil || cfg.Rotation.Dir == "" {
+		return false, nil
+	}
+	rc := cfg.Rotation
+
+	entries, err := keystore.Load(rc.Dir)
+	if err != nil {
+		return false, err
+	}
+	keys := make([]*Key, 0, len(entries))
+	for _, e := range entries {
+		k, err := rotatedKey(e)
+		if err != nil {
+			return false, fmt.Errorf("invalid key file %s: %w", e.ID, err)
+		}
+		keys = append(keys, k)
+	}
+
+	rotated := false
+	if len(keys) == 0 || (rc.Interval > 0 && time.Since(keys[0].CreatedAt) >= rc.Interval) {
+		k, err := generateKey(rc.Algorithm, rc.Dir)
+		if err != nil {
+			return false, err
+		}
+		keys = append([]*Key{k}, keys...)
+		rotated = true
+	}
+
+	// 密钥被替换后，其签发的令牌最多再有效 retention，之后删除
+	retention := max(AccessTokenTTL(), ChallengeTTL) + retireGrace
+	kept := keystore.Prune(rc.Dir, keys, func(k *Key) string { return k.ID }, func(i int) bool {
+		return i == 0 || time.Since(keys[i-1].CreatedAt) < retention
+	})
+
+	keyMu.Lock()
+	rotatedKeys = kept
+	keyMu.Unlock()
+	return rotated, nil
+}
+
+// generateKey 生成新的签名密钥并写入轮换目录
+func generateKey(alg, dir string) (*Key, error) {
+	var priv crypto.Signer
+	var err error
+	switch alg {
+	case RS256:
+		priv, err = rsa.GenerateKey(rand.Reader, 2048)
+	case ES256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case EdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	id, err := keystore.NewID(priv.Public(), now)
+	if err != nil {
+		return nil, err
+	}
+	k, err := rotatedKey(keystore.Entry{ID: id, CreatedAt: now, Key: priv})
+	if err != nil {
+		return nil, err
+	}
+	return k, keystore.Save(dir, id, priv)
+}
+
+// rotatedKey 转换轮换目录中的私钥，算法由密钥类型决定
+func rotatedKey(e keystore.Entry) (*Key, error) {
+	k := &Key{ID: e.ID, CreatedAt: e.CreatedAt}
+	switch key := e.Key.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm, k.signKey, k.verifyKey = RS256, key, &key.PublicKey
+	case *ecdsa.PrivateKey:
+		k.Algorithm, k.signKey, k.verifyKey = ES256, key, &key.PublicKey
+	case ed25519.PrivateKey:
+		k.Algorithm, k.signKey, k.verifyKey = EdDSA, key, key.Public()
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+	return k, nil
+}
+
+func parsePrivateKey(alg string, data []byte) (sign, verify any, err error) {
+	switch alg {
+	case RS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	case ES256:
+		key, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if key.Curve != elliptic.P256() {
+			return nil, nil, errors.New("ES256 requires a P-256 key")
+		}
+		return key, &key.PublicKey, nil
+	case EdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.(crypto.Signer).Public(), nil
+	}
+	return nil, nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+func parsePublicKey(alg string, data []byte) (any, error) {
+	switch alg {
+	case RS256:
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	case ES256:
+		return jwt.ParseECPublicKeyFromPEM(data)
+	case EdDSA:
+		return jwt.ParseEdPublicKeyFromPEM(data)
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// signingKey 当前用于签名的密钥
+func signingKey() *Key {
+	keyMu.RLock()
+	defer keyMu.RUnlock()
+	for _, k := range slices.Concat(rotatedKeys, staticKeys) {
+		if k.signKey != nil {
+			return k
+		}
+	}
+	return nil
+}
+
+// findKey 按 kid 查找验证密钥，kid 为空 (升级前签发的令牌) 时使用当前签名密钥
+func findKey(kid string) *Key {
+	if kid == "" {
+		return signingKey()
+	}
+	keyMu.RLock()
+	defer keyMu.RUnlock()
+	for _, k := range slices.Concat(rotatedKeys, staticKeys) {
+		if k.ID == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case RS256:
+		return jwt.SigningMethodRS256
+	case ES256:
+		return jwt.SigningMethodES256
+	case EdDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodHS256
+}
+
+// JWKS 返回全部非对称验证公钥 (HS256 密钥不公开)
+func JWKS() JWKSet {
+	keyMu.RLock()
+	defer keyMu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0)}
+	for _, k := range slices.Concat(rotatedKeys, staticKeys) {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64(pub.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			point, err := pub.Bytes()
+			if err != nil {
+				continue
+			}
+			// 非压缩点格式：0x04 || X || Y
+			size := (len(point) - 1) / 2
+			jwk.Kty, jwk.Crv = "EC", "P-256"
+			jwk.X = b64(point[1 : 1+size])
+			jwk.Y = b64(point[1+size:])
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = b64(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
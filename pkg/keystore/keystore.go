@@ -0,0 +1,123 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 轮换密钥目录：每个私钥保存为 <kid>.pem (PKCS#8)，kid 为 "创建时间-公钥指纹"，多实例可共享同一目录
+const (
+	fileExt = ".pem"
+	timeFmt = "20060102T150405.000"
+)
+
+// Entry 目录中的私钥
+type Entry struct {
+	ID        string
+	CreatedAt time.Time
+	Key       crypto.Signer
+}
+
+// Thumbprint 公钥指纹 (DER 的 SHA-256 前 8 字节)
+func Thumbprint(pub any) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// NewID 生成 kid (创建时间 + 公钥指纹)，kid 的字典序即创建时间顺序
+func NewID(pub any, createdAt time.Time) (string, error) {
+	id, err := Thumbprint(pub)
+	if err != nil {
+		return "", err
+	}
+	return createdAt.UTC().Format(timeFmt) + "-" + id, nil
+}
+
+// Load 读取目录中的全部私钥 (PKCS#8，兼容 RSA 的 PKCS#1)，按创建时间由新到旧排序
+func Load(dir string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", filepath.Base(file), err)
+		}
+		kid := strings.TrimSuffix(filepath.Base(file), fileExt)
+		createdAt, _ := time.Parse(timeFmt, strings.SplitN(kid, "-", 2)[0])
+		entries = append(entries, Entry{ID: kid, CreatedAt: createdAt, Key: key})
+	}
+	// kid 以创建时间开头，按字典序倒序即为由新到旧
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(b.ID, a.ID)
+	})
+	return entries, nil
+}
+
+// Save 以 PKCS#8 格式写入私钥 (先写临时文件再重命名，避免其他实例读到不完整的文件)
+func Save(dir, kid string, key crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, kid+fileExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Prune 按 retain(i) 保留由新到旧排列的密钥，其余从目录中删除 (dir 为空时只过滤)，返回保留的密钥
+func Prune[K any](dir string, keys []K, kid func(K) string, retain func(i int) bool) []K {
+	kept := make([]K, 0, len(keys))
+	for i, k := range keys {
+		if retain(i) {
+			kept = append(kept, k)
+			continue
+		}
+		if dir != "" {
+			_ = os.Remove(filepath.Join(dir, kid(k)+fileExt))
+		}
+	}
+	return kept
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+	return key, nil
+}
@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"bytes"
+	stdhtml "html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var (
+	// md Markdown 渲染器：GFM (表格 / 删除线 / 任务列表 / 自动链接) + 脚注 + 标题锚点 + 代码高亮
+	md = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			highlighting.NewHighlighting(
+				// 仅输出 class，由前端主题决定配色
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+		goldmark.WithRendererOptions(
+			// 允许内联 HTML，统一交由 sanitizer 过滤
+			html.WithUnsafe(),
+		),
+	)
+
+	// policy HTML 白名单，在 UGC 策略基础上放行高亮、锚点与脚注所需的属性
+	policy = newPolicy()
+
+	// textPolicy 去除全部标签，仅保留文本
+	textPolicy = bluemonday.StrictPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\- ]+$`)).OnElements("pre", "code", "span", "div", "a", "sup", "li")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_:\-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div")
+	// GFM 任务列表
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// headingIDs 标题锚点生成器，保留中文等 Unicode 字符 (goldmark 默认实现只保留 ASCII)
+type headingIDs struct {
+	values map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{values: make(map[string]bool)}
+}
+
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(string(value)) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	id := strings.TrimRight(b.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+
+	// 重复标题追加序号
+	unique := id
+	for i := 1; s.values[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	s.values[unique] = true
+	return []byte(unique)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
+
+// Render 将 Markdown 渲染为经过安全过滤的 HTML
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	if err := md.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		return "", err
+	}
+	return string(policy.SanitizeBytes(buf.Bytes())), nil
+}
+
+// PlainText 提取 HTML 中的纯文本 (用于搜索索引等场景)
+func PlainText(html string) string {
+	return stdhtml.UnescapeString(textPolicy.Sanitize(html))
+}
@@ -0,0 +1,318 @@
+package migrate
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 迁移状态
+const (
+	StateApplied = "applied" // 已执行
+	StatePending = "pending" // 待执行
+	StateChanged = "changed" // 已执行，但迁移内容被修改 (校验和不一致)
+	StateMissing = "missing" // 数据库中已执行，但当前程序中不存在 (通常是新版本程序执行过的迁移)
+)
+
+var (
+	// ErrLocked 其他实例正在执行迁移
+	ErrLocked = errors.New("migration lock is held by another process")
+	// ErrChecksumMismatch 已执行的迁移被修改
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+)
+
+// Migration 一个版本化的迁移，Version 递增且唯一 (通常为创建时间 20060102150405)
+type Migration struct {
+	Version  int64
+	Name     string
+	Checksum string // 迁移内容的 SHA-256，已执行的迁移被修改时拒绝继续执行
+	Up       func(tx *gorm.DB) error
+	Down     func(tx *gorm.DB) error
+}
+
+// Status 迁移状态
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// schemaMigration 已执行的迁移记录
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	Checksum  string    `gorm:"size:64;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// schemaMigrationLock 迁移锁 (只有一行)，防止多个实例同时执行迁移
+type schemaMigrationLock struct {
+	ID       int       `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"size:64;not null"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigrationLock) TableName() string { return "schema_migrations_lock" }
+
+// Migrator 迁移执行器
+type Migrator struct {
+	DB          *gorm.DB
+	Migrations  []Migration
+	LockTimeout time.Duration // 等待其他实例释放锁的最长时间
+	LockTTL     time.Duration // 超过该时长的锁视为持有者已崩溃，可以接管
+	owner       string
+}
+
+func New(db *gorm.DB, migrations []Migration) *Migrator {
+	list := append([]Migration(nil), migrations...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return &Migrator{
+		DB:          db,
+		Migrations:  list,
+		LockTimeout: time.Minute,
+		LockTTL:     10 * time.Minute,
+		owner:       newOwner(),
+	}
+}
+
+// Status 获取所有迁移的状态 (按版本升序)
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	return m.status(applied), nil
+}
+
+// Up 按版本顺序执行所有待执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err := m.withLock(func(applied map[int64]schemaMigration) error {
+		for _, mg := range m.Migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			if err := m.run(mg, true); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if err := m.prepare(); err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err := m.withLock(func(applied map[int64]schemaMigration) error {
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		for i := 0; i < steps && i < len(versions); i++ {
+			mg, ok := m.find(versions[i])
+			if !ok {
+				return fmt.Errorf("migration %d (%s) is not known to this binary", versions[i], applied[versions[i]].Name)
+			}
+			if mg.Down == nil {
+				return fmt.Errorf("migration %d_%s is irreversible", mg.Version, mg.Name)
+			}
+			if err := m.run(mg, false); err != nil {
+				return err
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Pending 统计待执行的迁移数量
+func (m *Migrator) Pending() (int, error) {
+	list, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, s := range list {
+		if s.State == StatePending {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// prepare 校验迁移列表并创建迁移记录表
+func (m *Migrator) prepare() error {
+	for i, mg := range m.Migrations {
+		if mg.Up == nil {
+			return fmt.Errorf("migration %d_%s has no up function", mg.Version, mg.Name)
+		}
+		if i > 0 && m.Migrations[i-1].Version == mg.Version {
+			return fmt.Errorf("duplicate migration version %d", mg.Version)
+		}
+	}
+	// 多个实例同时建表时可能失败，表已存在即可
+	if err := m.DB.Migrator().AutoMigrate(&schemaMigration{}, &schemaMigrationLock{}); err != nil {
+		if !m.DB.Migrator().HasTable(&schemaMigration{}) || !m.DB.Migrator().HasTable(&schemaMigrationLock{}) {
+			return err
+		}
+	}
+	return nil
+}
+
+// withLock 持有迁移锁执行 fn，执行前校验已执行迁移的校验和
+func (m *Migrator) withLock(fn func(applied map[int64]schemaMigration) error) error {
+	if err := m.lock(); err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	// 获取锁后重新读取，其他实例可能刚刚执行完迁移
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	var changed []string
+	for _, s := range m.status(applied) {
+		if s.State == StateChanged {
+			changed = append(changed, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(changed, ", "))
+	}
+	return fn(applied)
+}
+
+// run 在事务中执行迁移并更新迁移记录 (MySQL 的 DDL 会隐式提交，失败时需人工检查)
+func (m *Migrator) run(mg Migration, up bool) error {
+	err := m.DB.Transaction(func(tx *gorm.DB) error {
+		if up {
+			if err := mg.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   mg.Version,
+				Name:      mg.Name,
+				Checksum:  mg.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		}
+		if err := mg.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, "version = ?", mg.Version).Error
+	})
+	if err != nil {
+		direction := "up"
+		if !up {
+			direction = "down"
+		}
+		return fmt.Errorf("migration %d_%s %s: %w", mg.Version, mg.Name, direction, err)
+	}
+	return nil
+}
+
+// applied 读取已执行的迁移
+func (m *Migrator) applied() (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := m.DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) status(applied map[int64]schemaMigration) []Status {
+	list := make([]Status, 0, len(m.Migrations)+len(applied))
+	for _, mg := range m.Migrations {
+		s := Status{Version: mg.Version, Name: mg.Name, State: StatePending}
+		if row, ok := applied[mg.Version]; ok {
+			s.State = StateApplied
+			if row.Checksum != mg.Checksum {
+				s.State = StateChanged
+			}
+			appliedAt := row.AppliedAt
+			s.AppliedAt = &appliedAt
+		}
+		list = append(list, s)
+	}
+	for _, row := range applied {
+		if _, ok := m.find(row.Version); !ok {
+			appliedAt := row.AppliedAt
+			list = append(list, Status{Version: row.Version, Name: row.Name, State: StateMissing, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mg := range m.Migrations {
+		if mg.Version == version {
+			return mg, true
+		}
+	}
+	return Migration{}, false
+}
+
+// lock 获取迁移锁：插入唯一的锁记录，超过 LockTTL 的锁视为失效
+func (m *Migrator) lock() error {
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		m.DB.Where("id = ? and locked_at < ?", 1, time.Now().Add(-m.LockTTL)).Delete(&schemaMigrationLock{})
+		err := m.DB.Create(&schemaMigrationLock{ID: 1, Owner: m.owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			var holder schemaMigrationLock
+			if m.DB.Limit(1).Find(&holder, "id = ?", 1).RowsAffected == 0 {
+				return err
+			}
+			return fmt.Errorf("%w: owner %s since %s", ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (m *Migrator) unlock() {
+	m.DB.Where("id = ? and owner = ?", 1, m.owner).Delete(&schemaMigrationLock{})
+}
+
+// newOwner 锁持有者标识：主机名 + 进程号 + 随机串
+func newOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	rand.Read(b)
+	owner := fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b))
+	if len(owner) > 64 {
+		owner = owner[len(owner)-64:]
+	}
+	return owner
+}
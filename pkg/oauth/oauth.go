@@ -0,0 +1,393 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 提供方类型
+const (
+	TypeOIDC   = "oidc"
+	TypeGitHub = "github"
+)
+
+// GitHub 默认端点
+const (
+	githubAuthURL     = "https://github.com/login/oauth/authorize"
+	githubTokenURL    = "https://github.com/login/oauth/access_token"
+	githubUserInfoURL = "https://api.github.com/user"
+)
+
+// Config 第三方登录提供方配置
+type Config struct {
+	Name         string // 路由中使用的名称，如 github
+	Type         string // oidc | github
+	ClientID     string
+	ClientSecret string
+	Issuer       string   // OIDC 签发方，端点通过 {issuer}/.well-known/openid-configuration 自动发现
+	AuthURL      string   // 覆盖授权端点
+	TokenURL     string   // 覆盖令牌端点
+	UserInfoURL  string   // 覆盖用户信息端点
+	RedirectURL  string   // 回调地址，为空时使用 {站点地址}/api/auth/oauth/{name}/callback
+	Scopes       []string // 为空时 OIDC 使用 openid email profile，GitHub 使用 read:user user:email
+	AutoCreate   bool     // 身份未绑定用户时自动创建用户
+	DefaultRole  string   // 自动创建用户的角色
+}
+
+// Identity 第三方身份信息
+type Identity struct {
+	Subject       string
+	Username      string
+	Name          string
+	Email         string
+	EmailVerified bool
+	Avatar        string
+}
+
+// Token 令牌端点的响应
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+// Provider 第三方登录提供方
+type Provider struct {
+	Config
+	Client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     *keySet
+}
+
+// metadata OIDC 发现文档
+type metadata struct {
+	Issuer           string `json:"issuer"`
+	AuthEndpoint     string `json:"authorization_endpoint"`
+	TokenEndpoint    string `json:"token_endpoint"`
+	UserInfoEndpoint string `json:"userinfo_endpoint"`
+	JWKSURI          string `json:"jwks_uri"`
+}
+
+// Default 已配置的提供方
+var Default = map[string]*Provider{}
+
+// Init 根据配置创建提供方 (OIDC 端点在首次使用时发现)
+func Init(configs []Config) error {
+	providers := make(map[string]*Provider, len(configs))
+	for _, c := range configs {
+		p, err := NewProvider(c)
+		if err != nil {
+			return err
+		}
+		if _, ok := providers[p.Name]; ok {
+			return fmt.Errorf("duplicate oauth provider %q", p.Name)
+		}
+		providers[p.Name] = p
+	}
+	Default = providers
+	return nil
+}
+
+// NewProvider 校验配置并创建提供方
+func NewProvider(c Config) (*Provider, error) {
+	if c.Name == "" || c.ClientID == "" {
+		return nil, errors.New("oauth provider requires name and client_id")
+	}
+	switch c.Type {
+	case TypeGitHub:
+		c.AuthURL = withDefault(c.AuthURL, githubAuthURL)
+		c.TokenURL = withDefault(c.TokenURL, githubTokenURL)
+		c.UserInfoURL = withDefault(c.UserInfoURL, githubUserInfoURL)
+		if len(c.Scopes) == 0 {
+			c.Scopes = []string{"read:user", "user:email"}
+		}
+	case TypeOIDC:
+		if c.Issuer == "" {
+			return nil, fmt.Errorf("oidc provider %q requires issuer", c.Name)
+		}
+		c.Issuer = strings.TrimRight(c.Issuer, "/")
+		if len(c.Scopes) == 0 {
+			c.Scopes = []string{"openid", "email", "profile"}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported oauth provider type %q", c.Type)
+	}
+	return &Provider{Config: c, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// AuthCodeURL 生成授权地址 (授权码 + PKCE S256)
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, verifier, nonce string) (string, error) {
+	authURL := p.AuthURL
+	if p.Type == TypeOIDC {
+		md, err := p.discover(ctx)
+		if err != nil {
+			return "", err
+		}
+		authURL = withDefault(authURL, md.AuthEndpoint)
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", strings.Join(p.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", CodeChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	if p.Type == TypeOIDC {
+		q.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(authURL, "?") {
+		sep = "&"
+	}
+	return authURL + sep + q.Encode(), nil
+}
+
+// Exchange 使用授权码与 PKCE verifier 换取令牌
+func (p *Provider) Exchange(ctx context.Context, redirectURI, code, verifier string) (*Token, error) {
+	tokenURL := p.TokenURL
+	if p.Type == TypeOIDC {
+		md, err := p.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tokenURL = withDefault(tokenURL, md.TokenEndpoint)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", p.ClientID)
+	form.Set("client_secret", p.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token Token
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, err
+	}
+	// GitHub 出错时仍返回 200
+	if token.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDesc)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token exchange failed: empty access token")
+	}
+	return &token, nil
+}
+
+// Identity 获取第三方身份：OIDC 校验 id_token (签名、签发方、受众、有效期与 nonce)，GitHub 查询用户接口
+func (p *Provider) Identity(ctx context.Context, token *Token, nonce string) (*Identity, error) {
+	if p.Type == TypeGitHub {
+		return p.githubIdentity(ctx, token.AccessToken)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("missing id_token")
+	}
+
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(token.IDToken, claims,
+		func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.verifyKey(ctx, md.JWKSURI, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+
+	id := &Identity{
+		Subject:       claims.Subject,
+		Username:      claims.PreferredUsername,
+		Name:          claims.Name,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Avatar:        claims.Picture,
+	}
+	if id.Subject == "" {
+		return nil, errors.New("invalid id_token: missing sub")
+	}
+
+	// id_token 不含邮箱时查询用户信息端点 (sub 必须一致)
+	userInfoURL := withDefault(p.UserInfoURL, md.UserInfoEndpoint)
+	if id.Email == "" && userInfoURL != "" {
+		var info idTokenClaims
+		if err := p.getJSON(ctx, userInfoURL, token.AccessToken, &info); err == nil && info.Subject == id.Subject {
+			id.Email, id.EmailVerified = info.Email, bool(info.EmailVerified)
+			id.Username = withDefault(id.Username, info.PreferredUsername)
+			id.Name = withDefault(id.Name, info.Name)
+			id.Avatar = withDefault(id.Avatar, info.Picture)
+		}
+	}
+	return id, nil
+}
+
+type idTokenClaims struct {
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     flexBool `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Picture           string   `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// flexBool 兼容部分提供方以字符串返回的 email_verified
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	*b = flexBool(err == nil && v)
+	return nil
+}
+
+// githubIdentity 查询 GitHub 用户信息，公开资料无邮箱时查询已验证的主邮箱
+func (p *Provider) githubIdentity(ctx context.Context, accessToken string) (*Identity, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := p.getJSON(ctx, p.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("invalid github user")
+	}
+
+	id := &Identity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Name:     user.Name,
+		Email:    user.Email,
+		Avatar:   user.AvatarURL,
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.UserInfoURL, "/")+"/emails", accessToken, &emails); err == nil {
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				id.Email, id.EmailVerified = e.Email, true
+			}
+		}
+	}
+	return id, nil
+}
+
+// discover 获取并缓存 OIDC 发现文档
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var md metadata
+	if err := p.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", "", &md); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if strings.TrimRight(md.Issuer, "/") != p.Issuer {
+		return nil, fmt.Errorf("oidc discovery failed: issuer mismatch %q", md.Issuer)
+	}
+	if md.JWKSURI == "" {
+		return nil, errors.New("oidc discovery failed: missing jwks_uri")
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return p.doJSON(req, v)
+}
+
+func (p *Provider) doJSON(req *http.Request, v any) error {
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// RandomString 生成 URL 安全的随机字符串 (state / nonce / PKCE verifier)
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge PKCE S256：BASE64URL(SHA256(verifier))
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func withDefault(v, def string) string {
+	if v != "" {
+		return v
+	}
+	return def
+}
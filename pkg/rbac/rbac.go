@@ -0,0 +1,128 @@
+package rbac
+
+import "slices"
+
+// 角色
+const (
+	RoleAdmin  = "admin"  // 管理员：全部权限
+	RoleEditor = "editor" // 编辑：管理所有内容
+	RoleAuthor = "author" // 作者：仅管理自己的文章
+	RoleViewer = "viewer" // 访客：只读后台数据
+)
+
+// 权限
+const (
+	PostRead   = "post:read"   // 查看草稿、修订历史
+	PostCreate = "post:create" // 创建文章
+	PostUpdate = "post:update" // 编辑自己的文章
+	PostDelete = "post:delete" // 删除自己的文章
+	PostManage = "post:manage" // 编辑 / 删除他人的文章
+
+	CategoryManage = "category:manage"
+	TagManage      = "tag:manage"
+	LinkManage     = "link:manage"
+	ConfigManage   = "config:manage"
+
+	CommentRead     = "comment:read"
+	CommentModerate = "comment:moderate"
+
+	MediaRead   = "media:read"
+	MediaUpload = "media:upload"
+	MediaDelete = "media:delete"
+
+	UserManage = "user:manage"
+	AuditRead  = "audit:read"  // 查看审计日志
+	SiteBackup = "site:backup" // 导出 / 导入全站数据
+)
+
+// 个人访问令牌的权限范围 (scope)，令牌的实际权限为用户角色权限与 scope 的交集
+const (
+	ScopePostsRead     = "posts:read"
+	ScopePostsWrite    = "posts:write"
+	ScopeMediaRead     = "media:read"
+	ScopeMediaWrite    = "media:write"
+	ScopeCommentsRead  = "comments:read"
+	ScopeCommentsWrite = "comments:write"
+	ScopeSiteWrite     = "site:write" // 分类、标签、友链、站点配置
+)
+
+// scopes scope -> 权限 (令牌不能用于用户管理)
+var scopes = map[string][]string{
+	ScopePostsRead:     {PostRead},
+	ScopePostsWrite:    {PostRead, PostCreate, PostUpdate, PostDelete, PostManage},
+	ScopeMediaRead:     {MediaRead},
+	ScopeMediaWrite:    {MediaRead, MediaUpload, MediaDelete},
+	ScopeCommentsRead:  {CommentRead},
+	ScopeCommentsWrite: {CommentRead, CommentModerate},
+	ScopeSiteWrite:     {CategoryManage, TagManage, LinkManage, ConfigManage},
+}
+
+// all 通配权限
+const all = "*"
+
+// permissions 角色 -> 权限矩阵
+var permissions = map[string][]string{
+	RoleAdmin: {all},
+	RoleEditor: {
+		PostRead, PostCreate, PostUpdate, PostDelete, PostManage,
+		CategoryManage, TagManage, LinkManage,
+		CommentRead, CommentModerate,
+		MediaRead, MediaUpload, MediaDelete,
+	},
+	RoleAuthor: {
+		PostRead, PostCreate, PostUpdate, PostDelete,
+		CommentRead,
+		MediaRead, MediaUpload,
+	},
+	RoleViewer: {
+		PostRead, CommentRead, MediaRead,
+	},
+}
+
+// Roles 全部角色
+func Roles() []string {
+	return []string{RoleAdmin, RoleEditor, RoleAuthor, RoleViewer}
+}
+
+// ValidRole 判断角色是否存在
+func ValidRole(role string) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// Can 判断角色是否拥有指定权限
+func Can(role, permission string) bool {
+	perms := permissions[role]
+	return slices.Contains(perms, all) || slices.Contains(perms, permission)
+}
+
+// Permissions 角色拥有的权限列表
+func Permissions(role string) []string {
+	return slices.Clone(permissions[role])
+}
+
+// Scopes 全部 scope
+func Scopes() []string {
+	return []string{
+		ScopePostsRead, ScopePostsWrite,
+		ScopeMediaRead, ScopeMediaWrite,
+		ScopeCommentsRead, ScopeCommentsWrite,
+		ScopeSiteWrite,
+	}
+}
+
+// ValidScope 判断 scope 是否存在
+func ValidScope(scope string) bool {
+	_, ok := scopes[scope]
+	return ok
+}
+
+// RoleHasScope 判断角色至少拥有 scope 中的一项权限
+func RoleHasScope(role, scope string) bool {
+	return slices.ContainsFunc(scopes[scope], func(p string) bool { return Can(role, p) })
+}
+
+// ScopeAllows 判断 scope 列表是否包含指定权限
+func ScopeAllows(list []string, permission string) bool {
+	return slices.ContainsFunc(list, func(s string) bool { return slices.Contains(scopes[s], permission) })
+}
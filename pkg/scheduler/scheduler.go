@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"go-blog/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Job 定时任务
+type Job func() error
+
+// Scheduler 后台定时任务调度器，支持随服务优雅关闭
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// Every 每隔 interval 执行一次 job (注册后立即执行一次)
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.run(name, job)
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// run 执行单次任务，panic 不会导致调度器退出
+func (s *Scheduler) run(name string, job Job) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Log.Errorf("[Scheduler] job %s panic: %v", name, err)
+		}
+	}()
+	if err := job(); err != nil {
+		logger.Log.Errorf("[Scheduler] job %s failed: %v", name, err)
+	}
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
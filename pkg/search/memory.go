@@ -0,0 +1,147 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	titleWeight = 3 // 标题中的词频权重
+)
+
+type memoryDoc struct {
+	doc    Document
+	length int            // 加权后的词数
+	terms  map[string]int // 词 -> 加权词频
+}
+
+// MemorySearcher 基于内存倒排索引的搜索引擎 (BM25 排序)
+type MemorySearcher struct {
+	mu       sync.RWMutex
+	docs     map[string]*memoryDoc
+	postings map[string]map[string]int // 词 -> 文档 ID -> 加权词频
+	totalLen int
+}
+
+func NewMemorySearcher() *MemorySearcher {
+	return &MemorySearcher{
+		docs:     make(map[string]*memoryDoc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+var _ Searcher = (*MemorySearcher)(nil)
+
+// Index 新增或更新文档
+func (ms *MemorySearcher) Index(doc Document) error {
+	terms := make(map[string]int)
+	length := 0
+	for _, t := range Tokenize(doc.Title) {
+		terms[t] += titleWeight
+		length += titleWeight
+	}
+	for _, t := range Tokenize(doc.Content) {
+		terms[t]++
+		length++
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.remove(doc.ID)
+	ms.docs[doc.ID] = &memoryDoc{doc: doc, length: length, terms: terms}
+	ms.totalLen += length
+	for t, tf := range terms {
+		if ms.postings[t] == nil {
+			ms.postings[t] = make(map[string]int)
+		}
+		ms.postings[t][doc.ID] = tf
+	}
+	return nil
+}
+
+// Remove 删除文档
+func (ms *MemorySearcher) Remove(id string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.remove(id)
+	return nil
+}
+
+func (ms *MemorySearcher) remove(id string) {
+	old, ok := ms.docs[id]
+	if !ok {
+		return
+	}
+	for t := range old.terms {
+		delete(ms.postings[t], id)
+		if len(ms.postings[t]) == 0 {
+			delete(ms.postings, t)
+		}
+	}
+	ms.totalLen -= old.length
+	delete(ms.docs, id)
+}
+
+// Search 检索：文档需包含全部查询词，按 BM25 得分降序返回
+func (ms *MemorySearcher) Search(query string, limit int) ([]Hit, error) {
+	terms := uniqueTokens(query)
+	hits := make([]Hit, 0)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	n := float64(len(ms.docs))
+	if n == 0 {
+		return hits, nil
+	}
+	avgLen := float64(ms.totalLen) / n
+
+	// 从文档数最少的词开始求交集
+	sort.Slice(terms, func(i, j int) bool { return len(ms.postings[terms[i]]) < len(ms.postings[terms[j]]) })
+	scores := make(map[string]float64)
+	for id := range ms.postings[terms[0]] {
+		scores[id] = 0
+	}
+	for _, t := range terms {
+		posting := ms.postings[t]
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id := range scores {
+			tf, ok := posting[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			dl := float64(ms.docs[id].length)
+			scores[id] += idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*dl/avgLen))
+		}
+	}
+
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	for i := range hits {
+		doc := ms.docs[hits[i].ID].doc
+		hits[i].Title = Highlight(doc.Title, query)
+		hits[i].Snippet = Snippet(doc.Content, query)
+	}
+	return hits, nil
+}
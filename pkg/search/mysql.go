@@ -0,0 +1,87 @@
+package search
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// fulltextIndex MySQL 全文索引名称
+const fulltextIndex = "idx_fulltext_title_content"
+
+// MySQLSearcher 基于 MySQL FULLTEXT 索引 (ngram 分词器) 的搜索引擎
+// 索引由 MySQL 自动维护，Index / Remove 无需额外操作
+type MySQLSearcher struct {
+	DB    *gorm.DB
+	Table string // 文章表名，需包含 id / title / content 列
+}
+
+func NewMySQLSearcher(db *gorm.DB, table string) *MySQLSearcher {
+	return &MySQLSearcher{DB: db, Table: table}
+}
+
+var _ Searcher = (*MySQLSearcher)(nil)
+
+// Init 创建 ngram 全文索引 (已存在则跳过)
+func (m *MySQLSearcher) Init() error {
+	var count int64
+	err := m.DB.Raw(
+		"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+		m.Table, fulltextIndex,
+	).Scan(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return m.DB.Exec(fmt.Sprintf(
+		"ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (`title`, `content`) WITH PARSER ngram",
+		m.Table, fulltextIndex,
+	)).Error
+}
+
+// Index 由 MySQL 自动维护索引
+func (m *MySQLSearcher) Index(doc Document) error {
+	return nil
+}
+
+// Remove 由 MySQL 自动维护索引
+func (m *MySQLSearcher) Remove(id string) error {
+	return nil
+}
+
+// Search 使用 MATCH ... AGAINST 检索，按相关度降序返回
+func (m *MySQLSearcher) Search(query string, limit int) ([]Hit, error) {
+	hits := make([]Hit, 0)
+	if len(Tokenize(query)) == 0 {
+		return hits, nil
+	}
+
+	var rows []struct {
+		ID      string
+		Title   string
+		Content string
+		Score   float64
+	}
+	db := m.DB.Table(m.Table).
+		Select("id, title, content, MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score", query).
+		Where("MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)", query).
+		Order("score DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		hits = append(hits, Hit{
+			ID:      r.ID,
+			Score:   r.Score,
+			Title:   Highlight(r.Title, query),
+			Snippet: Snippet(r.Content, query),
+		})
+	}
+	return hits, nil
+}
@@ -0,0 +1,191 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document 待索引的文档
+type Document struct {
+	ID      string
+	Title   string
+	Content string // 纯文本正文
+}
+
+// Hit 搜索命中结果
+type Hit struct {
+	ID      string  `json:"id"`
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`   // 高亮后的标题 (HTML)
+	Snippet string  `json:"snippet"` // 高亮后的正文摘要 (HTML)
+}
+
+// Searcher 搜索引擎接口
+type Searcher interface {
+	// Index 新增或更新文档
+	Index(doc Document) error
+	// Remove 删除文档
+	Remove(id string) error
+	// Search 按相关度降序返回命中结果，limit <= 0 表示不限制数量
+	Search(query string, limit int) ([]Hit, error)
+}
+
+// Default 全局搜索引擎，默认为内存倒排索引
+var Default Searcher = NewMemorySearcher()
+
+// ----- 分词 -----
+
+// isCJK 判断是否为中日韩字符
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// Tokenize 分词：拉丁字母 / 数字按单词切分，中日韩文字按二元组 (bigram) 切分
+func Tokenize(text string) []string {
+	tokens := make([]string, 0)
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// uniqueTokens 去重后的查询词
+func uniqueTokens(query string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	for _, t := range Tokenize(query) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// ----- 高亮 -----
+
+const (
+	snippetLength = 120 // 摘要长度 (字符数)
+	snippetBefore = 30  // 首个命中词之前保留的字符数
+)
+
+type span struct{ start, end int }
+
+// matchSpans 查找 terms 在 text 中的所有出现位置 (忽略大小写)，返回合并后的区间
+func matchSpans(text []rune, terms []string) []span {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	spans := make([]span, 0)
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				spans = append(spans, span{i, i + len(t)})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return spans
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := []span{spans[0]}
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			last.end = max(last.end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// render 输出 text[from:to]，命中部分用 <mark> 包裹，其余部分做 HTML 转义
+func render(text []rune, spans []span, from, to int) string {
+	var b strings.Builder
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(text[pos:start])))
+		b.WriteString("<mark>" + html.EscapeString(string(text[start:end])) + "</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(text[pos:to])))
+	return b.String()
+}
+
+// Highlight 高亮整段文本中的查询词
+func Highlight(text, query string) string {
+	runes := []rune(text)
+	return render(runes, matchSpans(runes, uniqueTokens(query)), 0, len(runes))
+}
+
+// Snippet 截取首个命中词附近的摘要并高亮
+func Snippet(text, query string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	spans := matchSpans(runes, uniqueTokens(query))
+
+	start := 0
+	if len(spans) > 0 {
+		start = max(spans[0].start-snippetBefore, 0)
+	}
+	end := min(start+snippetLength, len(runes))
+
+	snippet := render(runes, spans, start, end)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
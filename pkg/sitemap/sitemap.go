@@ -0,0 +1,84 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// MaxURLs 单个 sitemap 文件允许的最大 URL 数 (sitemaps.org 协议限制)
+const MaxURLs = 50000
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL sitemap 中的一条地址
+type URL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// NewURL 创建一条地址，lastmod 为零值时省略
+func NewURL(loc string, lastMod time.Time) URL {
+	u := URL{Loc: loc}
+	if !lastMod.IsZero() {
+		u.LastMod = lastMod.UTC().Format(time.RFC3339)
+	}
+	return u
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []URL    `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet 输出 <urlset> 文档
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlSet{Xmlns: xmlns, URLs: urls})
+}
+
+// Index 输出 <sitemapindex> 文档，locs 为各个子 sitemap 的地址
+func Index(locs []string, lastMod time.Time) ([]byte, error) {
+	idx := sitemapIndex{Xmlns: xmlns, Sitemaps: make([]entry, 0, len(locs))}
+	for _, loc := range locs {
+		u := NewURL(loc, lastMod)
+		idx.Sitemaps = append(idx.Sitemaps, entry{Loc: u.Loc, LastMod: u.LastMod})
+	}
+	return marshal(idx)
+}
+
+// Robots 生成 robots.txt 内容
+func Robots(disallow []string, sitemapURL string) string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		// 空 Disallow 表示允许抓取全部内容
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	if sitemapURL != "" {
+		b.WriteString("\nSitemap: " + sitemapURL + "\n")
+	}
+	return b.String()
+}
+
+func marshal(v any) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type LocalConfig struct {
+	Dir       string // 本地存储目录
+	URLPrefix string // 对外访问路径前缀，如 /uploads
+}
+
+// LocalStorage 本地磁盘存储
+type LocalStorage struct {
+	Dir       string
+	URLPrefix string
+}
+
+func NewLocalStorage(dir, urlPrefix string) *LocalStorage {
+	if dir == "" {
+		dir = "./uploads"
+	}
+	if urlPrefix == "" {
+		urlPrefix = "/uploads"
+	}
+	return &LocalStorage{Dir: dir, URLPrefix: "/" + strings.Trim(urlPrefix, "/")}
+}
+
+var _ Storage = (*LocalStorage)(nil)
+
+// Put 写入文件
+func (ls *LocalStorage) Put(key string, data []byte, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	path := filepath.Join(ls.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Get 读取文件
+func (ls *LocalStorage) Get(key string) ([]byte, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(ls.Dir, filepath.FromSlash(key)))
+}
+
+// Delete 删除文件
+func (ls *LocalStorage) Delete(key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(ls.Dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL 访问地址
+func (ls *LocalStorage) URL(key string) string {
+	return ls.URLPrefix + "/" + key
+}
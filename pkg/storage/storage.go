@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 存储后端
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// Storage 文件存储接口
+type Storage interface {
+	// Put 写入对象，key 为以 "/" 分隔的相对路径
+	Put(key string, data []byte, contentType string) error
+	// Get 读取对象
+	Get(key string) ([]byte, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(key string) error
+	// URL 返回对象的访问地址
+	URL(key string) string
+}
+
+type Config struct {
+	Backend string
+	Local   LocalConfig
+	S3      S3Config
+}
+
+// Default 全局文件存储，默认写入本地 ./uploads 目录
+var Default Storage = NewLocalStorage("./uploads", "/uploads")
+
+// Init 根据配置初始化全局文件存储
+func Init(c *Config) error {
+	if c == nil {
+		return errors.New("storage config is nil")
+	}
+	switch c.Backend {
+	case "", BackendLocal:
+		Default = NewLocalStorage(c.Local.Dir, c.Local.URLPrefix)
+	case BackendS3:
+		s3, err := NewS3Storage(c.S3)
+		if err != nil {
+			return err
+		}
+		Default = s3
+	default:
+		return fmt.Errorf("unsupported storage backend: %s", c.Backend)
+	}
+	return nil
+}
+
+// validKey 校验对象路径，禁止越级访问
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key: %q", key)
+	}
+	return nil
+}
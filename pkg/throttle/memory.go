@@ -0,0 +1,101 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// MemoryTracker 基于内存的计数器 (单实例部署)
+type MemoryTracker struct {
+	mu      sync.Mutex
+	policy  Policy
+	entries map[string]*entry
+	ops     int
+	now     func() time.Time
+}
+
+func NewMemoryTracker(policy Policy) *MemoryTracker {
+	return &MemoryTracker{
+		policy:  policy,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+var _ Tracker = (*MemoryTracker)(nil)
+
+// Blocked 返回 key 剩余的等待时长
+func (m *MemoryTracker) Blocked(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.get(key)
+	if e == nil {
+		return 0, nil
+	}
+	return max(e.blockedUntil.Sub(m.now()), 0), nil
+}
+
+// Fail 记录一次失败
+func (m *MemoryTracker) Fail(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	e := m.get(key)
+	if e == nil {
+		e = &entry{}
+		m.entries[key] = e
+	}
+	e.failures++
+	e.lastFailure = now
+	delay := m.policy.Backoff(e.failures)
+	e.blockedUntil = now.Add(delay)
+
+	m.ops++
+	if m.ops%1024 == 0 {
+		m.sweep(now)
+	}
+	return delay, nil
+}
+
+// Reset 清除失败记录
+func (m *MemoryTracker) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// get 获取未过期的记录 (需持有锁)
+func (m *MemoryTracker) get(key string) *entry {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	if m.expired(e, m.now()) {
+		delete(m.entries, key)
+		return nil
+	}
+	return e
+}
+
+// expired 超过统计窗口且已解除锁定的记录视为过期
+func (m *MemoryTracker) expired(e *entry, now time.Time) bool {
+	return now.After(e.blockedUntil) && now.Sub(e.lastFailure) > m.policy.Window
+}
+
+// sweep 清理过期记录，防止内存无限增长 (需持有锁)
+func (m *MemoryTracker) sweep(now time.Time) {
+	for key, e := range m.entries {
+		if m.expired(e, now) {
+			delete(m.entries, key)
+		}
+	}
+}
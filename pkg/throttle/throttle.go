@@ -0,0 +1,54 @@
+package throttle
+
+import "time"
+
+// Policy 失败次数 -> 等待时间策略
+type Policy struct {
+	FreeAttempts int           // 允许连续失败的次数，超出后开始退避
+	BaseDelay    time.Duration // 首次退避时长，之后每次失败翻倍
+	MaxDelay     time.Duration // 退避时长上限
+	MaxFailures  int           // 达到该失败次数后锁定
+	LockDuration time.Duration // 锁定时长
+	Window       time.Duration // 超过该时间无失败则清零计数
+}
+
+// DefaultPolicy 默认策略：连续失败 3 次后退避 1s、2s、4s…，10 次后锁定 15 分钟
+var DefaultPolicy = Policy{
+	FreeAttempts: 3,
+	BaseDelay:    time.Second,
+	MaxDelay:     5 * time.Minute,
+	MaxFailures:  10,
+	LockDuration: 15 * time.Minute,
+	Window:       time.Hour,
+}
+
+// Backoff 计算第 failures 次失败后需等待的时长
+func (p Policy) Backoff(failures int) time.Duration {
+	if p.MaxFailures > 0 && failures >= p.MaxFailures {
+		return p.LockDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Tracker 失败尝试计数器，默认实现为内存版本，可替换为 Redis 等共享存储
+type Tracker interface {
+	// Blocked 返回 key 剩余的等待时长，0 表示允许尝试
+	Blocked(key string) (time.Duration, error)
+	// Fail 记录一次失败，返回此后需等待的时长
+	Fail(key string) (time.Duration, error)
+	// Reset 清除 key 的失败记录
+	Reset(key string) error
+}
+
+// Default 全局登录尝试计数器
+var Default Tracker = NewMemoryTracker(DefaultPolicy)
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"go-blog/controller"
+	"go-blog/middleware"
+	"go-blog/pkg/rbac"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func AdminRouter(r *gin.Engine, db *gorm.DB) {
+	auditController := controller.NewAuditController(service.NewAuditService(db))
+	backupController := controller.NewBackupController(service.NewBackupService(db))
+	contentImportController := controller.NewContentImportController(service.NewContentImportService(db))
+
+	adminGroup := r.Group("/api/admin")
+	adminGroup.Use(middleware.JWTAuth(db))
+	{
+		adminGroup.GET("/audit", middleware.RequirePermission(rbac.AuditRead), auditController.GetAuditLogs)
+		adminGroup.GET("/export", middleware.RequirePermission(rbac.SiteBackup), backupController.Export)
+		adminGroup.POST("/import", middleware.RequirePermission(rbac.SiteBackup), backupController.Import)
+		adminGroup.GET("/export/hugo", middleware.RequirePermission(rbac.SiteBackup), contentImportController.ExportHugo)
+		adminGroup.POST("/import/content", middleware.RequirePermission(rbac.SiteBackup), contentImportController.ImportContent)
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +21,16 @@ func CategoryRouter(r *gin.Engine, db *gorm.DB) {
 
 		// 认证接口
 		authGroup := categoryGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
-			authGroup.POST("", categoryController.CreateCategory)
-			authGroup.PUT("/:id", categoryController.UpdateCategory)
-			authGroup.DELETE("/:id", categoryController.DeleteCategory)
+			authGroup.POST("", middleware.RequirePermission(rbac.CategoryManage), categoryController.CreateCategory)
+			authGroup.PUT("/:id", middleware.RequirePermission(rbac.CategoryManage), categoryController.UpdateCategory)
+			authGroup.DELETE("/:id", middleware.RequirePermission(rbac.CategoryManage), categoryController.DeleteCategory)
+
+			// 回收站
+			authGroup.GET("/trash", middleware.RequirePermission(rbac.CategoryManage), categoryController.GetCategoryTrash)
+			authGroup.POST("/trash/:id/restore", middleware.RequirePermission(rbac.CategoryManage), categoryController.RestoreCategory)
+			authGroup.DELETE("/trash/:id", middleware.RequirePermission(rbac.CategoryManage), categoryController.PurgeCategory)
 		}
 	}
 }
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"go-blog/controller"
+	"go-blog/middleware"
+	"go-blog/pkg/rbac"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func CommentRouter(r *gin.Engine, db *gorm.DB) {
+	commentService := service.NewCommentService(db)
+	commentController := controller.NewCommentController(commentService)
+
+	// 公开接口：文章评论
+	postCommentGroup := r.Group("/api/posts/:slug/comments")
+	{
+		postCommentGroup.GET("", commentController.GetPostComments)
+		postCommentGroup.POST("", commentController.CreateComment)
+	}
+
+	// 认证接口：评论审核
+	commentGroup := r.Group("/api/comments")
+	commentGroup.Use(middleware.JWTAuth(db))
+	{
+		commentGroup.GET("", middleware.RequirePermission(rbac.CommentRead), commentController.GetCommentList)
+		commentGroup.PUT("/:id/approve", middleware.RequirePermission(rbac.CommentModerate), commentController.ApproveComment)
+		commentGroup.PUT("/:id/reject", middleware.RequirePermission(rbac.CommentModerate), commentController.RejectComment)
+		commentGroup.PUT("/:id/spam", middleware.RequirePermission(rbac.CommentModerate), commentController.SpamComment)
+		commentGroup.DELETE("/:id", middleware.RequirePermission(rbac.CommentModerate), commentController.DeleteComment)
+	}
+}
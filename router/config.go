@@ -3,6 +3,7 @@ package router
 import (
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,9 +21,9 @@ func ConfigRouter(r *gin.Engine, db *gorm.DB) {
 
 		// 认证：修改配置
 		authGroup := configGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
-			authGroup.PUT("", configController.UpdateConfig)
+			authGroup.PUT("", middleware.RequirePermission(rbac.ConfigManage), configController.UpdateConfig)
 		}
 	}
 }
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"go-blog/controller"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func FeedRouter(r *gin.Engine, db *gorm.DB) {
+	feedService := service.NewFeedService(db)
+	feedController := controller.NewFeedController(feedService)
+
+	// 全站订阅
+	r.GET("/feed.xml", feedController.RSS)
+	r.GET("/atom.xml", feedController.Atom)
+
+	// 分类 / 标签订阅
+	r.GET("/api/categories/:slug/feed.xml", feedController.CategoryRSS)
+	r.GET("/api/categories/:slug/atom.xml", feedController.CategoryAtom)
+	r.GET("/api/tags/:slug/feed.xml", feedController.TagRSS)
+	r.GET("/api/tags/:slug/atom.xml", feedController.TagAtom)
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +21,16 @@ func LinkRouter(r *gin.Engine, db *gorm.DB) {
 
 		// 认证：增删改
 		authGroup := linkGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
-			authGroup.POST("", linkController.CreateLink)
-			authGroup.PUT("/:id", linkController.UpdateLink)
-			authGroup.DELETE("/:id", linkController.DeleteLink)
+			authGroup.POST("", middleware.RequirePermission(rbac.LinkManage), linkController.CreateLink)
+			authGroup.PUT("/:id", middleware.RequirePermission(rbac.LinkManage), linkController.UpdateLink)
+			authGroup.DELETE("/:id", middleware.RequirePermission(rbac.LinkManage), linkController.DeleteLink)
+
+			// 回收站
+			authGroup.GET("/trash", middleware.RequirePermission(rbac.LinkManage), linkController.GetLinkTrash)
+			authGroup.POST("/trash/:id/restore", middleware.RequirePermission(rbac.LinkManage), linkController.RestoreLink)
+			authGroup.DELETE("/trash/:id", middleware.RequirePermission(rbac.LinkManage), linkController.PurgeLink)
 		}
 	}
 }
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"go-blog/config"
+	"go-blog/controller"
+	"go-blog/middleware"
+	"go-blog/pkg/rbac"
+	"go-blog/pkg/storage"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MediaRouter(r *gin.Engine, db *gorm.DB) {
+	mediaService := service.NewMediaService(db)
+	if size := config.AppConfig.Media.MaxSizeMB; size > 0 {
+		mediaService.MaxSize = int64(size) << 20
+	}
+	if width := config.AppConfig.Media.ThumbnailWidth; width > 0 {
+		mediaService.ThumbnailWidth = width
+	}
+	if mp := config.AppConfig.Media.MaxMegapixels; mp > 0 {
+		mediaService.MaxPixels = int64(mp) * 1_000_000
+	}
+	mediaController := controller.NewMediaController(mediaService)
+
+	// 本地存储时由服务直接提供静态文件
+	if local, ok := storage.Default.(*storage.LocalStorage); ok {
+		r.Static(local.URLPrefix, local.Dir)
+	}
+
+	mediaGroup := r.Group("/api/media")
+	mediaGroup.Use(middleware.JWTAuth(db))
+	{
+		mediaGroup.GET("", middleware.RequirePermission(rbac.MediaRead), mediaController.GetMediaList)
+		mediaGroup.POST("", middleware.RequirePermission(rbac.MediaUpload), mediaController.UploadMedia)
+		mediaGroup.DELETE("/:id", middleware.RequirePermission(rbac.MediaDelete), mediaController.DeleteMedia)
+	}
+}
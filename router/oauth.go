@@ -0,0 +1,33 @@
+package router
+
+import (
+	"go-blog/controller"
+	"go-blog/middleware"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func OAuthRouter(r *gin.Engine, db *gorm.DB) {
+	oauthService := service.NewOAuthService(db)
+	sessionService := service.NewSessionService(db)
+	oauthController := controller.NewOAuthController(oauthService, sessionService)
+
+	// 第三方登录 (公开)
+	oauthGroup := r.Group("/api/auth/oauth")
+	{
+		oauthGroup.GET("/providers", oauthController.GetProviders)
+		oauthGroup.GET("/:provider/login", oauthController.Login)
+		oauthGroup.GET("/:provider/callback", oauthController.Callback)
+	}
+
+	// 当前用户绑定的第三方身份
+	identityGroup := r.Group("/api/user/identities")
+	identityGroup.Use(middleware.JWTAuth(db), middleware.SessionOnly())
+	{
+		identityGroup.GET("", oauthController.GetIdentities)
+		identityGroup.POST("/:provider", oauthController.Link)
+		identityGroup.DELETE("/:id", oauthController.Unlink)
+	}
+}
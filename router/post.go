@@ -1,8 +1,10 @@
 package router
 
 import (
+	"go-blog/config"
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -11,8 +13,15 @@ import (
 
 func PostRouter(r *gin.Engine, db *gorm.DB) {
 	postService := service.NewPostService(db)
+	if n := config.AppConfig.Post.MaxRevisions; n > 0 {
+		postService.MaxRevisions = n
+	}
 	postController := controller.NewPostController(postService)
 
+	revisionService := service.NewRevisionService(db)
+	revisionService.PostService = postService
+	revisionController := controller.NewRevisionController(revisionService, postService)
+
 	postGroup := r.Group("/api/posts")
 	{
 		// 公开接口
@@ -21,11 +30,25 @@ func PostRouter(r *gin.Engine, db *gorm.DB) {
 
 		// 认证接口
 		authGroup := postGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
-			authGroup.POST("", postController.CreatePost)
-			authGroup.PUT("/:id", postController.UpdatePost)
-			authGroup.DELETE("/:id", postController.DeletePost)
+			authGroup.POST("", middleware.RequirePermission(rbac.PostCreate), postController.CreatePost)
+			authGroup.PUT("/:id", middleware.RequirePermission(rbac.PostUpdate), postController.UpdatePost)
+			authGroup.DELETE("/:id", middleware.RequirePermission(rbac.PostDelete), postController.DeletePost)
+
+			// 后台列表 (作者只能看到自己的文章)
+			authGroup.GET("/manage", middleware.RequirePermission(rbac.PostRead), postController.GetManagedPostList)
+
+			// 回收站 (作者只能恢复 / 彻底删除自己的文章)
+			authGroup.GET("/trash", middleware.RequirePermission(rbac.PostRead), postController.GetPostTrash)
+			authGroup.POST("/trash/:id/restore", middleware.RequirePermission(rbac.PostUpdate), postController.RestorePost)
+			authGroup.DELETE("/trash/:id", middleware.RequirePermission(rbac.PostDelete), postController.PurgePost)
+
+			// 修订历史 (与 /:slug、评论路由共用路由树，参数名为 slug，值为文章 ID)
+			authGroup.GET("/:slug/revisions", middleware.RequirePermission(rbac.PostRead), revisionController.GetRevisions)
+			authGroup.GET("/:slug/revisions/diff", middleware.RequirePermission(rbac.PostRead), revisionController.DiffRevisions)
+			authGroup.GET("/:slug/revisions/:rev", middleware.RequirePermission(rbac.PostRead), revisionController.GetRevision)
+			authGroup.POST("/:slug/revisions/:rev/restore", middleware.RequirePermission(rbac.PostUpdate), revisionController.RestoreRevision)
 		}
 	}
 }
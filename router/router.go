@@ -2,6 +2,7 @@ package router
 
 import (
 	"go-blog/middleware"
+	"go-blog/pkg/database"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -17,33 +18,40 @@ func InitRouter(db *gorm.DB) *gin.Engine {
 
 	// 注册业务路由
 	UserRoutes(r, db)
+	OAuthRouter(r, db)
 	PostRouter(r, db)
 	CategoryRouter(r, db)
 	TagRouter(r, db)
 	LinkRouter(r, db)
 	ConfigRouter(r, db)
+	CommentRouter(r, db)
+	FeedRouter(r, db)
+	SitemapRouter(r, db)
+	SearchRouter(r, db)
+	MediaRouter(r, db)
+	WellKnownRouter(r, db)
+	AdminRouter(r, db)
 
 	r.GET("/api/health", func(c *gin.Context) {
-		// 检查数据库连接
-		sqlDB, err := db.DB()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"status": "DOWN",
-				"error":  "DB connection failed",
-			})
-			return
-		}
-		if err := sqlDB.Ping(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"status": "DOWN",
-				"error":  "DB ping failed",
-			})
-			return
+		// 分别检查主库与只读副本的连接：主库不可用时为 DOWN，仅副本不可用时为 DEGRADED
+		databases := database.Check(c.Request.Context(), db)
+		status, code := "UP", http.StatusOK
+		var errMsg any
+		for _, h := range databases {
+			if h.Status == database.StatusUp {
+				continue
+			}
+			if h.Role == database.RolePrimary {
+				status, code, errMsg = "DOWN", http.StatusInternalServerError, "DB ping failed"
+				break
+			}
+			status = "DEGRADED"
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"status": "UP",
-			"error":  nil,
+		c.JSON(code, gin.H{
+			"status":    status,
+			"error":     errMsg,
+			"databases": databases,
 		})
 	})
 
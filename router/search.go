@@ -0,0 +1,16 @@
+package router
+
+import (
+	"go-blog/controller"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SearchRouter(r *gin.Engine, db *gorm.DB) {
+	searchService := service.NewSearchService(db)
+	searchController := controller.NewSearchController(searchService)
+
+	r.GET("/api/search", searchController.Search)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"go-blog/controller"
+	service "go-blog/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func SitemapRouter(r *gin.Engine, db *gorm.DB) {
+	sitemapService := service.NewSitemapService(db)
+	sitemapController := controller.NewSitemapController(sitemapService)
+
+	r.GET("/sitemap.xml", sitemapController.Sitemap)
+	r.GET("/sitemaps/:file", sitemapController.SitemapPage)
+	r.GET("/robots.txt", sitemapController.Robots)
+}
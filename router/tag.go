@@ -3,6 +3,7 @@ package router
 import (
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +21,16 @@ func TagRouter(r *gin.Engine, db *gorm.DB) {
 
 		// 认证接口
 		authGroup := tagGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
-			authGroup.POST("", tagController.CreateTag)
-			authGroup.PUT("/:id", tagController.UpdateTag)
-			authGroup.DELETE("/:id", tagController.DeleteTag)
+			authGroup.POST("", middleware.RequirePermission(rbac.TagManage), tagController.CreateTag)
+			authGroup.PUT("/:id", middleware.RequirePermission(rbac.TagManage), tagController.UpdateTag)
+			authGroup.DELETE("/:id", middleware.RequirePermission(rbac.TagManage), tagController.DeleteTag)
+
+			// 回收站
+			authGroup.GET("/trash", middleware.RequirePermission(rbac.TagManage), tagController.GetTagTrash)
+			authGroup.POST("/trash/:id/restore", middleware.RequirePermission(rbac.TagManage), tagController.RestoreTag)
+			authGroup.DELETE("/trash/:id", middleware.RequirePermission(rbac.TagManage), tagController.PurgeTag)
 		}
 	}
 }
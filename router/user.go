@@ -3,6 +3,7 @@ package router
 import (
 	"go-blog/controller"
 	"go-blog/middleware"
+	"go-blog/pkg/rbac"
 	service "go-blog/services"
 
 	"github.com/gin-gonic/gin"
@@ -11,20 +12,64 @@ import (
 
 func UserRoutes(r *gin.Engine, db *gorm.DB) {
 	userService := service.NewUserService(db)
-	userController := controller.NewUserController(userService)
+	sessionService := service.NewSessionService(db)
+	loginGuardService := service.NewLoginGuardService(db)
+	twoFactorService := service.NewTwoFactorService(db)
+	userController := controller.NewUserController(userService, sessionService, loginGuardService, twoFactorService)
+	twoFactorController := controller.NewTwoFactorController(twoFactorService)
+	apiTokenController := controller.NewAPITokenController(service.NewAPITokenService(db))
 
 	userGroup := r.Group("/api/user")
 	{
 		// 公开接口
 		userGroup.POST("/login", userController.Login)
+		userGroup.POST("/login/2fa", userController.LoginTwoFactor)
 		userGroup.GET("/public-key", userController.GetPublicKey)
+		userGroup.POST("/refresh", userController.Refresh)
 
 		// 需要认证的接口组
 		authGroup := userGroup.Group("")
-		authGroup.Use(middleware.JWTAuth())
+		authGroup.Use(middleware.JWTAuth(db))
 		{
 			authGroup.GET("/profile", userController.GetProfile)
-			authGroup.POST("/change-password", userController.ChangePassword)
 		}
+
+		// 账户安全相关接口 (不允许个人访问令牌)
+		accountGroup := userGroup.Group("")
+		accountGroup.Use(middleware.JWTAuth(db), middleware.SessionOnly())
+		{
+			accountGroup.PUT("/profile", userController.UpdateProfile)
+			accountGroup.POST("/change-password", userController.ChangePassword)
+			accountGroup.POST("/logout", userController.Logout)
+			accountGroup.POST("/logout-all", userController.LogoutAll)
+
+			// 两步验证
+			accountGroup.POST("/2fa/setup", twoFactorController.Setup)
+			accountGroup.POST("/2fa/enable", twoFactorController.Enable)
+			accountGroup.POST("/2fa/disable", twoFactorController.Disable)
+			accountGroup.POST("/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+
+			// 个人访问令牌
+			accountGroup.GET("/tokens", apiTokenController.GetTokens)
+			accountGroup.GET("/tokens/scopes", apiTokenController.GetScopes)
+			accountGroup.POST("/tokens", apiTokenController.CreateToken)
+			accountGroup.DELETE("/tokens/:id", apiTokenController.RevokeToken)
+		}
+	}
+
+	// 用户管理 (管理员)
+	usersGroup := r.Group("/api/users")
+	usersGroup.Use(middleware.JWTAuth(db), middleware.RequirePermission(rbac.UserManage))
+	{
+		usersGroup.GET("", userController.GetUserList)
+		usersGroup.GET("/login-attempts", userController.GetLoginAttempts)
+		usersGroup.GET("/:id", userController.GetUser)
+		usersGroup.POST("", userController.CreateUser)
+		usersGroup.PUT("/:id", userController.UpdateUser)
+		usersGroup.PUT("/:id/password", userController.ResetPassword)
+		usersGroup.PUT("/:id/disable", userController.DisableUser)
+		usersGroup.PUT("/:id/enable", userController.EnableUser)
+		usersGroup.DELETE("/:id", userController.DeleteUser)
+		usersGroup.DELETE("/:id/2fa", userController.ResetTwoFactor)
 	}
 }
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"go-blog/controller"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func WellKnownRouter(r *gin.Engine, db *gorm.DB) {
+	wellKnownController := controller.NewWellKnownController()
+
+	r.GET("/.well-known/jwks.json", wellKnownController.JWKS)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"go-blog/model"
+	"go-blog/pkg/rbac"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupAPITokenTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.APIToken{})
+	return db
+}
+
+func TestAPITokenService_CreateAndAuthenticate(t *testing.T) {
+	db := setupAPITokenTestDB()
+	svc := NewAPITokenService(db)
+
+	author := &model.User{Username: "ci", Password: "x", Role: rbac.RoleAuthor}
+	db.Create(author)
+
+	// Case 1: 参数校验：scope 必须存在且不能超出角色权限
+	_, _, err := svc.CreateToken(author.ID, "ci", nil, 0)
+	assert.Error(t, err)
+	_, _, err = svc.CreateToken(author.ID, "ci", []string{"posts:everything"}, 0)
+	assert.ErrorContains(t, err, "invalid scope")
+	_, _, err = svc.CreateToken(author.ID, "ci", []string{rbac.ScopeSiteWrite}, 0)
+	assert.ErrorContains(t, err, "exceeds role")
+
+	// Case 2: 创建成功，只保存哈希
+	token, raw, err := svc.CreateToken(author.ID, " ci ", []string{rbac.ScopePostsWrite, rbac.ScopeMediaWrite, rbac.ScopePostsWrite}, 0)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, apiTokenPrefix))
+	assert.True(t, strings.HasPrefix(raw, token.Prefix))
+	assert.Equal(t, "ci", token.Name)
+	assert.Equal(t, []string{rbac.ScopeMediaWrite, rbac.ScopePostsWrite}, token.Scopes)
+	assert.Nil(t, token.ExpiresAt)
+	var stored model.APIToken
+	db.First(&stored, "id = ?", token.ID)
+	assert.Equal(t, hashToken(raw), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, raw)
+	assert.Equal(t, token.Scopes, stored.Scopes)
+
+	// Case 3: 使用令牌认证，记录最近使用时间与 IP
+	user, got, err := svc.Authenticate(raw, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, author.ID, user.ID)
+	assert.Equal(t, token.ID, got.ID)
+	db.First(&stored, "id = ?", token.ID)
+	assert.NotNil(t, stored.LastUsedAt)
+	assert.Equal(t, "10.0.0.1", stored.LastUsedIP)
+
+	// Case 4: 错误的令牌
+	_, _, err = svc.Authenticate(raw+"x", "10.0.0.1")
+	assert.Error(t, err)
+	_, _, err = svc.Authenticate("Bearer-token", "10.0.0.1")
+	assert.Error(t, err)
+
+	// Case 5: 用户被禁用后令牌失效
+	db.Model(author).Update("disabled", true)
+	_, _, err = svc.Authenticate(raw, "10.0.0.1")
+	assert.ErrorContains(t, err, "disabled")
+	db.Model(author).Update("disabled", false)
+
+	// Case 6: 吊销后令牌失效，不能吊销他人的令牌
+	other := &model.User{Username: "other", Password: "x", Role: rbac.RoleAdmin}
+	db.Create(other)
+	assert.Error(t, svc.RevokeToken(other.ID, token.ID))
+	assert.NoError(t, svc.RevokeToken(author.ID, token.ID))
+	_, _, err = svc.Authenticate(raw, "10.0.0.1")
+	assert.Error(t, err)
+	list, _ := svc.GetTokens(author.ID)
+	assert.Len(t, list, 0)
+}
+
+func TestAPITokenService_Expiry(t *testing.T) {
+	db := setupAPITokenTestDB()
+	svc := NewAPITokenService(db)
+
+	editor := &model.User{Username: "editor", Password: "x", Role: rbac.RoleEditor}
+	db.Create(editor)
+
+	// Case 1: 设置有效期
+	token, raw, err := svc.CreateToken(editor.ID, "deploy", []string{rbac.ScopeSiteWrite}, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.NotNil(t, token.ExpiresAt)
+	_, _, err = svc.Authenticate(raw, "10.0.0.1")
+	assert.NoError(t, err)
+
+	// Case 2: 过期后认证失败
+	db.Model(&model.APIToken{}).Where("id = ?", token.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	_, _, err = svc.Authenticate(raw, "10.0.0.1")
+	assert.ErrorContains(t, err, "expired")
+
+	// Case 3: 令牌的权限为角色权限与 scope 的交集
+	assert.True(t, rbac.ScopeAllows([]string{rbac.ScopePostsWrite}, rbac.PostCreate))
+	assert.False(t, rbac.ScopeAllows([]string{rbac.ScopePostsRead}, rbac.PostCreate))
+	assert.False(t, rbac.ScopeAllows(rbac.Scopes(), rbac.UserManage))
+}
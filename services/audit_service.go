@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/json"
+	"go-blog/model"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 审计日志默认保留时长
+	defaultAuditRetention = 180 * 24 * time.Hour
+	// 修改记录中字符串的最大长度 (文章正文等长文本只保留开头)
+	auditMaxValueLen = 500
+)
+
+// auditResource 路由前缀对应的资源类型与模型
+type auditResource struct {
+	Prefix string
+	Type   string
+	Self   bool // 当前用户自身 (资源 ID 为操作者 ID)
+	Model  func() any
+}
+
+// auditResources 按顺序匹配，较长的前缀在前
+var auditResources = []auditResource{
+	{Prefix: "/api/user/tokens", Type: "api_token", Model: func() any { return &model.APIToken{} }},
+	{Prefix: "/api/user/identities", Type: "user_identity", Model: func() any { return &model.UserIdentity{} }},
+	{Prefix: "/api/user", Type: "user", Self: true, Model: func() any { return &model.User{} }},
+	{Prefix: "/api/users", Type: "user", Model: func() any { return &model.User{} }},
+	{Prefix: "/api/posts", Type: "post", Model: func() any { return &model.Post{} }},
+	{Prefix: "/api/categories", Type: "category", Model: func() any { return &model.Category{} }},
+	{Prefix: "/api/tags", Type: "tag", Model: func() any { return &model.Tag{} }},
+	{Prefix: "/api/links", Type: "link", Model: func() any { return &model.Link{} }},
+	{Prefix: "/api/config", Type: "site_config", Model: func() any { return &model.SiteConfig{} }},
+	{Prefix: "/api/comments", Type: "comment", Model: func() any { return &model.Comment{} }},
+	{Prefix: "/api/media", Type: "media", Model: func() any { return &model.Media{} }},
+}
+
+// AuditTarget 请求对应的审计资源
+type AuditTarget struct {
+	Type   string
+	Action string
+	Self   bool
+	model  func() any
+}
+
+// Singleton 资源为全局唯一记录 (站点配置)，无需 ID
+func (t *AuditTarget) Singleton() bool {
+	return t.Type == "site_config"
+}
+
+type AuditLogListReq struct {
+	Page         int
+	PageSize     int
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Since        *time.Time
+	Until        *time.Time
+}
+
+type IAuditService interface {
+	Target(method, path string) *AuditTarget
+	Snapshot(target *AuditTarget, id string) map[string]any
+	Record(log *model.AuditLog, before, after map[string]any) error
+	GetLogs(req *AuditLogListReq) ([]model.AuditLog, int64, error)
+	PurgeLogs() (int64, error)
+}
+
+type AuditService struct {
+	DB        *gorm.DB
+	Retention time.Duration
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{DB: db, Retention: defaultAuditRetention}
+}
+
+var _ IAuditService = (*AuditService)(nil)
+
+// Target 根据请求方法与路由模板确定资源类型与操作名
+// 如 PUT /api/comments/:id/approve -> comment.approve，POST /api/posts -> post.create
+func (as *AuditService) Target(method, path string) *AuditTarget {
+	target := &AuditTarget{}
+	rest := ""
+	for _, r := range auditResources {
+		if path == r.Prefix || strings.HasPrefix(path, r.Prefix+"/") {
+			target.Type, target.Self, target.model = r.Type, r.Self, r.Model
+			rest = strings.TrimPrefix(path, r.Prefix)
+			break
+		}
+	}
+	// 未登记的路由以 /api 后的第一段作为资源类型
+	if target.Type == "" {
+		segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+		target.Type = segments[0]
+		rest = strings.TrimPrefix(path, "/api/"+segments[0])
+	}
+
+	var parts []string
+	for _, seg := range strings.Split(rest, "/") {
+		if seg != "" && !strings.HasPrefix(seg, ":") && !strings.HasPrefix(seg, "*") {
+			parts = append(parts, seg)
+		}
+	}
+	switch {
+	case len(parts) == 0:
+		parts = append(parts, auditVerb(method))
+	case method == "DELETE":
+		parts = append(parts, "delete")
+	}
+	target.Action = target.Type + "." + strings.Join(parts, ".")
+	return target
+}
+
+// Snapshot 读取资源当前的值 (按 JSON 序列化，不含隐藏字段)，不存在时返回 nil
+func (as *AuditService) Snapshot(target *AuditTarget, id string) map[string]any {
+	if target == nil || target.model == nil || (id == "" && !target.Singleton()) {
+		return nil
+	}
+	m := target.model()
+	// 包含回收站中的记录，软删除、恢复与彻底删除均能记录差异
+	db := as.DB.Unscoped()
+	if id != "" {
+		db = db.Where("id = ?", id)
+	}
+	// 使用 Find 避免资源已删除时记录 record not found 日志
+	if result := db.Limit(1).Find(m); result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var snapshot map[string]any
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// Record 写入审计日志，Changes 为修改前后有变化的字段
+func (as *AuditService) Record(log *model.AuditLog, before, after map[string]any) error {
+	log.Changes = auditDiff(before, after)
+	log.ActorName = truncate(log.ActorName, 50)
+	log.ResourceID = truncate(log.ResourceID, 64)
+	log.Path = truncate(log.Path, 255)
+	log.UserAgent = truncate(log.UserAgent, 255)
+	return as.DB.Create(log).Error
+}
+
+// GetLogs 获取审计日志 (分页，按时间倒序)
+func (as *AuditService) GetLogs(req *AuditLogListReq) ([]model.AuditLog, int64, error) {
+	list := make([]model.AuditLog, 0)
+	var total int64
+
+	db := as.DB.Model(&model.AuditLog{})
+	if req.ActorID != "" {
+		db = db.Where("actor_id = ?", req.ActorID)
+	}
+	if req.Action != "" {
+		db = db.Where("action = ?", req.Action)
+	}
+	if req.ResourceType != "" {
+		db = db.Where("resource_type = ?", req.ResourceType)
+	}
+	if req.ResourceID != "" {
+		db = db.Where("resource_id = ?", req.ResourceID)
+	}
+	if req.Since != nil {
+		db = db.Where("created_at >= ?", *req.Since)
+	}
+	if req.Until != nil {
+		db = db.Where("created_at < ?", *req.Until)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := db.Offset(offset).Limit(req.PageSize).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// PurgeLogs 清理超过保留时长的审计日志
+func (as *AuditService) PurgeLogs() (int64, error) {
+	result := as.DB.Where("created_at < ?", time.Now().Add(-as.Retention)).Delete(&model.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+func auditVerb(method string) string {
+	switch method {
+	case "POST":
+		return "create"
+	case "PUT", "PATCH":
+		return "update"
+	case "DELETE":
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// auditDiff 比较修改前后的字段，忽略更新时间
+func auditDiff(before, after map[string]any) map[string]model.AuditChange {
+	changes := make(map[string]model.AuditChange)
+	add := func(key string) {
+		if key == "updated_at" {
+			return
+		}
+		if _, done := changes[key]; done {
+			return
+		}
+		b, a := before[key], after[key]
+		if !reflect.DeepEqual(b, a) {
+			changes[key] = model.AuditChange{Before: auditValue(b), After: auditValue(a)}
+		}
+	}
+	for key := range before {
+		add(key)
+	}
+	for key := range after {
+		add(key)
+	}
+	return changes
+}
+
+// auditValue 截断过长的字符串
+func auditValue(v any) any {
+	s, ok := v.(string)
+	if !ok || utf8.RuneCountInString(s) <= auditMaxValueLen {
+		return v
+	}
+	return string([]rune(s)[:auditMaxValueLen]) + "…"
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"go-blog/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupAuditTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Link{}, &model.SiteConfig{}, &model.AuditLog{})
+	return db
+}
+
+func TestAuditService_Target(t *testing.T) {
+	svc := NewAuditService(setupAuditTestDB())
+
+	cases := []struct {
+		method, path, typ, action string
+		self                      bool
+	}{
+		{"POST", "/api/posts", "post", "post.create", false},
+		{"PUT", "/api/posts/:id", "post", "post.update", false},
+		{"DELETE", "/api/posts/:id", "post", "post.delete", false},
+		{"POST", "/api/posts/:slug/revisions/:rev/restore", "post", "post.revisions.restore", false},
+		{"PUT", "/api/comments/:id/approve", "comment", "comment.approve", false},
+		{"PUT", "/api/config", "site_config", "site_config.update", false},
+		{"DELETE", "/api/users/:id/2fa", "user", "user.2fa.delete", false},
+		{"PUT", "/api/users/:id/disable", "user", "user.disable", false},
+		{"POST", "/api/user/change-password", "user", "user.change-password", true},
+		{"POST", "/api/user/tokens", "api_token", "api_token.create", false},
+		{"DELETE", "/api/user/tokens/:id", "api_token", "api_token.delete", false},
+		{"POST", "/api/widgets/:id", "widgets", "widgets.create", false},
+	}
+	// Case 1: 根据路由模板确定资源类型与操作名
+	for _, tc := range cases {
+		target := svc.Target(tc.method, tc.path)
+		assert.Equal(t, tc.typ, target.Type, tc.path)
+		assert.Equal(t, tc.action, target.Action, tc.path)
+		assert.Equal(t, tc.self, target.Self, tc.path)
+	}
+}
+
+func TestAuditService_RecordAndQuery(t *testing.T) {
+	db := setupAuditTestDB()
+	svc := NewAuditService(db)
+	target := svc.Target("PUT", "/api/links/:id")
+
+	link := &model.Link{Name: "Go", URL: "https://go.dev"}
+	db.Create(link)
+
+	// Case 1: 记录修改前后的字段差异，忽略更新时间
+	before := svc.Snapshot(target, link.ID)
+	assert.Equal(t, "Go", before["name"])
+	db.Model(link).Updates(map[string]any{"name": "Golang", "updated_at": time.Now().Add(time.Hour)})
+	after := svc.Snapshot(target, link.ID)
+	err := svc.Record(&model.AuditLog{ActorID: "u1", ActorName: "admin", Action: target.Action, ResourceType: target.Type, ResourceID: link.ID, Method: "PUT"}, before, after)
+	assert.NoError(t, err)
+
+	var log model.AuditLog
+	db.First(&log)
+	assert.Equal(t, map[string]model.AuditChange{"name": {Before: "Go", After: "Golang"}}, log.Changes)
+
+	// Case 2: 移入回收站的记录仍可读取；彻底删除时 after 为空，长文本截断
+	db.Model(link).Update("name", strings.Repeat("长", 600))
+	before = svc.Snapshot(target, link.ID)
+	db.Delete(link)
+	assert.NotNil(t, svc.Snapshot(target, link.ID)["deleted_at"])
+	db.Unscoped().Delete(link)
+	assert.Nil(t, svc.Snapshot(target, link.ID))
+	svc.Record(&model.AuditLog{ActorID: "u2", Action: "link.delete", ResourceType: "link", ResourceID: link.ID}, before, nil)
+	list, total, err := svc.GetLogs(&AuditLogListReq{Page: 1, PageSize: 10, Action: "link.delete"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	name := list[0].Changes["name"]
+	assert.Nil(t, name.After)
+	assert.Equal(t, auditMaxValueLen+1, len([]rune(name.Before.(string))))
+
+	// Case 3: 站点配置无需 ID
+	db.Create(&model.SiteConfig{Title: "blog"})
+	snapshot := svc.Snapshot(svc.Target("PUT", "/api/config"), "")
+	assert.Equal(t, "blog", snapshot["title"])
+
+	// Case 4: 按条件筛选
+	_, total, _ = svc.GetLogs(&AuditLogListReq{Page: 1, PageSize: 10, ResourceType: "link", ResourceID: link.ID})
+	assert.Equal(t, int64(2), total)
+	_, total, _ = svc.GetLogs(&AuditLogListReq{Page: 1, PageSize: 10, ActorID: "u1"})
+	assert.Equal(t, int64(1), total)
+	future := time.Now().Add(time.Hour)
+	_, total, _ = svc.GetLogs(&AuditLogListReq{Page: 1, PageSize: 10, Since: &future})
+	assert.Equal(t, int64(0), total)
+
+	// Case 5: 清理超过保留时长的日志
+	db.Model(&model.AuditLog{}).Where("actor_id = ?", "u1").Update("created_at", time.Now().Add(-200*24*time.Hour))
+	n, err := svc.PurgeLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
@@ -0,0 +1,921 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/rbac"
+	"go-blog/pkg/storage"
+	"io"
+	"io/fs"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 导出归档格式与版本，归档结构变化时递增版本
+const (
+	BackupFormat  = "go-blog-backup"
+	BackupVersion = 1
+)
+
+// 导入时的冲突处理策略
+const (
+	ConflictSkip      = "skip"      // 保留已有记录
+	ConflictOverwrite = "overwrite" // 使用归档中的内容覆盖已有记录
+	ConflictRename    = "rename"    // 以新的 slug / 名称导入 (仅文章、分类、标签；其余资源同 skip)
+)
+
+// 归档中的文件
+const (
+	backupManifestFile   = "manifest.json"
+	backupUsersFile      = "users.json"
+	backupCategoriesFile = "categories.json"
+	backupTagsFile       = "tags.json"
+	backupPostsFile      = "posts.json"
+	backupPostTagsFile   = "post_tags.json"
+	backupLinksFile      = "links.json"
+	backupSiteFile       = "site_config.json"
+	backupMediaFile      = "media.json"
+	backupFilesDir       = "files/" // 媒体文件，按存储路径存放
+)
+
+var errDryRun = errors.New("dry run")
+
+// BackupManifest 归档清单
+type BackupManifest struct {
+	Format           string         `json:"format"`
+	Version          int            `json:"version"`
+	CreatedAt        time.Time      `json:"created_at"`
+	IncludePasswords bool           `json:"include_passwords"`
+	Counts           map[string]int `json:"counts"`
+	Warnings         []string       `json:"warnings,omitempty"`
+}
+
+// 归档记录：与数据表结构解耦，只包含迁移站点所需的字段
+
+type backupUser struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	DisplayName string    `json:"display_name"`
+	Avatar      string    `json:"avatar"`
+	Bio         string    `json:"bio"`
+	Disabled    bool      `json:"disabled"`
+	Password    string    `json:"password,omitempty"` // bcrypt 哈希，仅在导出时指定包含密码时存在
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type backupTerm struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type backupPost struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Summary    string     `json:"summary"`
+	Slug       string     `json:"slug"`
+	Cover      string     `json:"cover"`
+	CategoryID string     `json:"category_id"`
+	AuthorID   string     `json:"author_id"`
+	Views      uint       `json:"views"`
+	Status     string     `json:"status"`
+	PublishAt  *time.Time `json:"publish_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type backupPostTag struct {
+	PostID string `json:"post_id"`
+	TagID  string `json:"tag_id"`
+}
+
+type backupLink struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Sort        int       `json:"sort"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type backupSiteConfig struct {
+	Title       string `json:"title"`
+	Subtitle    string `json:"subtitle"`
+	Description string `json:"description"`
+	Keywords    string `json:"keywords"`
+	Author      string `json:"author"`
+	Email       string `json:"email"`
+	GithubURL   string `json:"github_url"`
+}
+
+type backupMedia struct {
+	ID           string    `json:"id"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Key          string    `json:"key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	WebPKey      string    `json:"webp_key"`
+	UploaderID   string    `json:"uploader_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	IncludePasswords bool // 包含用户密码哈希
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Strategy        string // 冲突处理策略，默认 skip
+	DryRun          bool   // 只校验并统计，不写入任何数据
+	DefaultAuthorID string // 作者 / 上传者不在归档中时使用的用户，为空时使用最早创建的管理员
+}
+
+// ImportCount 单类资源的导入结果
+type ImportCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Renamed int `json:"renamed"` // 以新的 slug / 名称创建 (同时计入 created)
+	Skipped int `json:"skipped"`
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	Strategy  string                  `json:"strategy"`
+	Manifest  *BackupManifest         `json:"manifest"`
+	Resources map[string]*ImportCount `json:"resources"`
+	Warnings  []string                `json:"warnings"`
+}
+
+type IBackupService interface {
+	Export(w io.Writer, opts *ExportOptions) error
+	Import(r io.ReaderAt, size int64, opts *ImportOptions) (*ImportReport, error)
+}
+
+type BackupService struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+}
+
+func NewBackupService(db *gorm.DB) *BackupService {
+	return &BackupService{DB: db, Storage: storage.Default}
+}
+
+var _ IBackupService = (*BackupService)(nil)
+
+// Export 将站点数据写入 zip 归档 (流式写入，不含回收站中的记录)
+func (bs *BackupService) Export(w io.Writer, opts *ExportOptions) error {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	zw := zip.NewWriter(w)
+	manifest := &BackupManifest{
+		Format:           BackupFormat,
+		Version:          BackupVersion,
+		CreatedAt:        time.Now(),
+		IncludePasswords: opts.IncludePasswords,
+		Counts:           make(map[string]int),
+	}
+
+	var err error
+	if manifest.Counts["users"], err = exportTable(zw, backupUsersFile, bs.DB, func(u *model.User) backupUser {
+		rec := backupUser{
+			ID: u.ID, Username: u.Username, Email: u.Email, Role: u.Role, DisplayName: u.DisplayName,
+			Avatar: u.Avatar, Bio: u.Bio, Disabled: u.Disabled, CreatedAt: u.CreatedAt,
+		}
+		if opts.IncludePasswords {
+			rec.Password = u.Password
+		}
+		return rec
+	}); err != nil {
+		return err
+	}
+	if manifest.Counts["categories"], err = exportTable(zw, backupCategoriesFile, bs.DB, func(c *model.Category) backupTerm {
+		return backupTerm{ID: c.ID, Name: c.Name, Slug: c.Slug, CreatedAt: c.CreatedAt, UpdatedAt: c.UpdatedAt}
+	}); err != nil {
+		return err
+	}
+	if manifest.Counts["tags"], err = exportTable(zw, backupTagsFile, bs.DB, func(t *model.Tag) backupTerm {
+		return backupTerm{ID: t.ID, Name: t.Name, Slug: t.Slug, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt}
+	}); err != nil {
+		return err
+	}
+	if manifest.Counts["posts"], err = exportTable(zw, backupPostsFile, bs.DB.Omit("content_html"), func(p *model.Post) backupPost {
+		rec := backupPost{
+			ID: p.ID, Title: p.Title, Content: p.Content, Summary: p.Summary, Slug: p.Slug, Cover: p.Cover,
+			CategoryID: p.CategoryID, AuthorID: p.AuthorID, Status: p.Status, PublishAt: p.PublishAt,
+			CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt,
+		}
+		if p.Views != nil {
+			rec.Views = *p.Views
+		}
+		return rec
+	}); err != nil {
+		return err
+	}
+
+	// 文章与标签的关联 (双方均未删除)
+	postTags := make([]backupPostTag, 0)
+	if err := bs.DB.Table("post_tags").Select("post_tags.post_id, post_tags.tag_id").
+		Where("post_id in (?)", bs.DB.Model(&model.Post{}).Select("id")).
+		Where("tag_id in (?)", bs.DB.Model(&model.Tag{}).Select("id")).
+		Order("post_id, tag_id").Scan(&postTags).Error; err != nil {
+		return err
+	}
+	if err := writeJSON(zw, backupPostTagsFile, postTags); err != nil {
+		return err
+	}
+	manifest.Counts["post_tags"] = len(postTags)
+
+	if manifest.Counts["links"], err = exportTable(zw, backupLinksFile, bs.DB, func(l *model.Link) backupLink {
+		return backupLink{ID: l.ID, Name: l.Name, URL: l.URL, Description: l.Description, Sort: l.Sort, CreatedAt: l.CreatedAt}
+	}); err != nil {
+		return err
+	}
+
+	var site model.SiteConfig
+	if bs.DB.Limit(1).Find(&site).RowsAffected > 0 {
+		if err := writeJSON(zw, backupSiteFile, backupSiteConfig{
+			Title: site.Title, Subtitle: site.Subtitle, Description: site.Description, Keywords: site.Keywords,
+			Author: site.Author, Email: site.Email, GithubURL: site.GithubURL,
+		}); err != nil {
+			return err
+		}
+		manifest.Counts["site_config"] = 1
+	}
+
+	var media []backupMedia
+	if manifest.Counts["media"], err = exportTable(zw, backupMediaFile, bs.DB, func(m *model.Media) backupMedia {
+		rec := backupMedia{
+			ID: m.ID, FileName: m.FileName, MimeType: m.MimeType, Size: m.Size, Width: m.Width, Height: m.Height,
+			Key: m.Key, ThumbnailKey: m.ThumbnailKey, WebPKey: m.WebPKey, UploaderID: m.UploaderID, CreatedAt: m.CreatedAt,
+		}
+		media = append(media, rec)
+		return rec
+	}); err != nil {
+		return err
+	}
+	// 媒体文件 (原图、缩略图、WebP)，读取失败时记录到清单中
+	for _, m := range media {
+		for _, key := range mediaKeys(&m) {
+			data, err := bs.Storage.Get(key)
+			if err != nil {
+				manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("media file %s: %v", key, err))
+				continue
+			}
+			f, err := zw.CreateHeader(&zip.FileHeader{Name: backupFilesDir + key, Method: zip.Store, Modified: m.CreatedAt})
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeJSON(zw, backupManifestFile, manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// exportTable 分批读取数据表并以 JSON 数组写入归档，返回记录数
+// FindInBatches 按主键排序并以上一批最后的主键分页，query 不能再指定排序，否则会重复或遗漏记录
+func exportTable[M any, R any](zw *zip.Writer, name string, query *gorm.DB, convert func(*M) R) (int, error) {
+	f, err := createEntry(zw, name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(f, "["); err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(f)
+	count := 0
+	var batch []M
+	err = query.FindInBatches(&batch, 200, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if count > 0 {
+				if _, err := io.WriteString(f, ","); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(convert(&batch[i])); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.WriteString(f, "]\n")
+	return count, err
+}
+
+// createEntry 在归档中创建压缩文件 (记录修改时间)
+func createEntry(zw *zip.Writer, name string) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	f, err := createEntry(zw, name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// mediaKeys 媒体的全部文件 (去重，WebP 原图与原图为同一文件)
+func mediaKeys(m *backupMedia) []string {
+	keys := []string{m.Key}
+	for _, key := range []string{m.ThumbnailKey, m.WebPKey} {
+		if key != "" && key != m.Key {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Import 从 zip 归档导入站点数据：在一个事务中完成，所有记录使用新的 ID，
+// 文章、标签、媒体等之间的引用按 ID 映射关系重建
+func (bs *BackupService) Import(r io.ReaderAt, size int64, opts *ImportOptions) (*ImportReport, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = ConflictSkip
+	}
+	if strategy != ConflictSkip && strategy != ConflictOverwrite && strategy != ConflictRename {
+		return nil, fmt.Errorf("invalid conflict strategy %q", opts.Strategy)
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	manifest := &BackupManifest{}
+	if err := readJSON(zr, backupManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != BackupFormat {
+		return nil, fmt.Errorf("invalid archive: unknown format %q", manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > BackupVersion {
+		return nil, fmt.Errorf("unsupported archive version %d (supported: %d)", manifest.Version, BackupVersion)
+	}
+
+	report := &ImportReport{
+		DryRun:    opts.DryRun,
+		Strategy:  strategy,
+		Manifest:  manifest,
+		Resources: make(map[string]*ImportCount),
+		Warnings:  make([]string, 0),
+	}
+	im := &importer{
+		zr:         zr,
+		strategy:   strategy,
+		report:     report,
+		storage:    bs.Storage,
+		users:      make(map[string]string),
+		categories: make(map[string]string),
+		tags:       make(map[string]string),
+		posts:      make(map[string]string),
+	}
+
+	err = bs.DB.Transaction(func(tx *gorm.DB) error {
+		im.tx = tx
+		if err := im.run(opts.DefaultAuthorID); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// 提交后写入媒体文件并重建搜索索引 (失败时只记录警告，数据已导入)
+	for _, file := range im.files {
+		data, err := readFile(zr, backupFilesDir+file.key)
+		if err == nil {
+			err = bs.Storage.Put(file.key, data, file.contentType)
+		}
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("media file %s: %v", file.key, err))
+		}
+	}
+	if report.count("posts").Created+report.count("posts").Updated > 0 && NewSearchService(bs.DB).Searcher != nil {
+		if err := NewSearchService(bs.DB).RebuildIndex(); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("search index: %v", err))
+		}
+	}
+	return report, nil
+}
+
+func (r *ImportReport) count(resource string) *ImportCount {
+	c, ok := r.Resources[resource]
+	if !ok {
+		c = &ImportCount{}
+		r.Resources[resource] = c
+	}
+	return c
+}
+
+func (r *ImportReport) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// readJSON 读取归档中的 JSON 文件 (文件不存在时保持 v 不变)
+func readJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if name == backupManifestFile {
+			return errors.New("invalid archive: manifest.json not found")
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("invalid archive: %s: %w", name, err)
+	}
+	return nil
+}
+
+func readFile(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// importFile 待写入存储的媒体文件
+type importFile struct {
+	key         string
+	contentType string
+}
+
+// importer 单次导入的状态：旧 ID -> 新 ID 映射与待写入的文件
+type importer struct {
+	zr       *zip.Reader
+	tx       *gorm.DB
+	strategy string
+	report   *ImportReport
+	storage  storage.Storage
+
+	users      map[string]string
+	categories map[string]string
+	tags       map[string]string
+	posts      map[string]string
+	files      []importFile
+
+	defaultAuthorID string
+}
+
+func (im *importer) run(defaultAuthorID string) error {
+	if err := im.importUsers(); err != nil {
+		return err
+	}
+	im.defaultAuthorID = defaultAuthorID
+	if im.defaultAuthorID == "" {
+		var admin model.User
+		if im.tx.Where("role = ?", rbac.RoleAdmin).Order("created_at").Limit(1).Find(&admin).RowsAffected > 0 {
+			im.defaultAuthorID = admin.ID
+		}
+	}
+
+	if err := im.importTerms("categories", backupCategoriesFile, &model.Category{}, im.categories, func(rec *backupTerm) (string, error) {
+		c := &model.Category{Name: rec.Name, Slug: rec.Slug, CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt}
+		err := im.tx.Create(c).Error
+		return c.ID, err
+	}); err != nil {
+		return err
+	}
+	if err := im.importTerms("tags", backupTagsFile, &model.Tag{}, im.tags, func(rec *backupTerm) (string, error) {
+		t := &model.Tag{Name: rec.Name, Slug: rec.Slug, CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt}
+		err := im.tx.Create(t).Error
+		return t.ID, err
+	}); err != nil {
+		return err
+	}
+	for _, step := range []func() error{im.importPosts, im.importLinks, im.importSiteConfig, im.importMedia} {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// author 映射作者 / 上传者，不在归档中时使用默认用户
+func (im *importer) author(oldID string) string {
+	if id, ok := im.users[oldID]; ok {
+		return id
+	}
+	return im.defaultAuthorID
+}
+
+// findExisting 查找冲突的已有记录 (包括回收站中的记录)，返回其 ID
+func (im *importer) findExisting(m any, query any, args ...any) (string, error) {
+	var ids []string
+	err := im.tx.Unscoped().Model(m).Where(query, args...).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// uniqueValue 生成未被占用的值 (包括回收站中的记录)：value-2、value-3 ... (format 为追加的后缀格式)
+func uniqueValue(db *gorm.DB, m any, column, value, format string) (string, error) {
+	candidate := value
+	for i := 2; ; i++ {
+		var count int64
+		if err := db.Unscoped().Model(m).Where(column+" = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = value + fmt.Sprintf(format, i)
+	}
+}
+
+// importUsers 按用户名匹配已有用户；覆盖时只更新资料与密码，不修改已有用户的角色与状态
+func (im *importer) importUsers() error {
+	var records []backupUser
+	if err := readJSON(im.zr, backupUsersFile, &records); err != nil {
+		return err
+	}
+	count := im.report.count("users")
+	withoutPassword := 0
+	for _, rec := range records {
+		existing, err := im.findExisting(&model.User{}, "username = ?", rec.Username)
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			im.users[rec.ID] = existing
+			if im.strategy != ConflictOverwrite {
+				count.Skipped++
+				continue
+			}
+			updates := map[string]any{"email": rec.Email, "display_name": rec.DisplayName, "avatar": rec.Avatar, "bio": rec.Bio}
+			if rec.Password != "" {
+				updates["password"] = rec.Password
+				updates["password_changed_at"] = time.Now()
+			}
+			if err := im.tx.Model(&model.User{}).Where("id = ?", existing).Updates(updates).Error; err != nil {
+				return err
+			}
+			count.Updated++
+			continue
+		}
+
+		// 归档可能被篡改或来自其他版本，未知角色降级为 viewer
+		role := rec.Role
+		if !rbac.ValidRole(role) {
+			im.report.warn("user %q: unknown role %q, imported as %s", rec.Username, role, rbac.RoleViewer)
+			role = rbac.RoleViewer
+		}
+		user := &model.User{
+			Username: rec.Username, Email: rec.Email, Role: role, DisplayName: rec.DisplayName,
+			Avatar: rec.Avatar, Bio: rec.Bio, Disabled: rec.Disabled, Password: rec.Password, CreatedAt: rec.CreatedAt,
+		}
+		if user.Password == "" {
+			// 归档不含密码时设置随机密码，需由管理员重置后才能登录
+			password, err := randomPassword()
+			if err != nil {
+				return err
+			}
+			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			user.Password = string(hash)
+			user.MustChangePassword = true
+			withoutPassword++
+		}
+		if err := im.tx.Create(user).Error; err != nil {
+			return err
+		}
+		im.users[rec.ID] = user.ID
+		count.Created++
+	}
+	if withoutPassword > 0 {
+		im.report.warn("%d users were imported without passwords, reset their passwords before they can log in", withoutPassword)
+	}
+	return nil
+}
+
+// importTerms 导入分类 / 标签，按 slug 或名称匹配已有记录
+func (im *importer) importTerms(resource, file string, m any, ids map[string]string, create func(rec *backupTerm) (string, error)) error {
+	var records []backupTerm
+	if err := readJSON(im.zr, file, &records); err != nil {
+		return err
+	}
+	count := im.report.count(resource)
+	for _, rec := range records {
+		existing, err := im.findExisting(m, "slug = ? or name = ?", rec.Slug, rec.Name)
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			switch im.strategy {
+			case ConflictSkip:
+				ids[rec.ID] = existing
+				count.Skipped++
+				continue
+			case ConflictOverwrite:
+				if err := im.tx.Unscoped().Model(m).Where("id = ?", existing).
+					Updates(map[string]any{"name": rec.Name, "slug": rec.Slug, "deleted_at": nil}).Error; err != nil {
+					return fmt.Errorf("%s %q: %w", resource, rec.Slug, err)
+				}
+				ids[rec.ID] = existing
+				count.Updated++
+				continue
+			case ConflictRename:
+				if rec.Slug, err = uniqueValue(im.tx, m, "slug", rec.Slug, "-%d"); err != nil {
+					return err
+				}
+				if rec.Name, err = uniqueValue(im.tx, m, "name", rec.Name, " (%d)"); err != nil {
+					return err
+				}
+				count.Renamed++
+			}
+		}
+		id, err := create(&rec)
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", resource, rec.Slug, err)
+		}
+		ids[rec.ID] = id
+		count.Created++
+	}
+	return nil
+}
+
+// importPosts 导入文章与标签关联，按 slug 匹配已有文章；覆盖时替换正文与标签
+func (im *importer) importPosts() error {
+	var records []backupPost
+	if err := readJSON(im.zr, backupPostsFile, &records); err != nil {
+		return err
+	}
+	var postTags []backupPostTag
+	if err := readJSON(im.zr, backupPostTagsFile, &postTags); err != nil {
+		return err
+	}
+	tagsOf := make(map[string][]string)
+	for _, pt := range postTags {
+		tagsOf[pt.PostID] = append(tagsOf[pt.PostID], pt.TagID)
+	}
+
+	count := im.report.count("posts")
+	for _, rec := range records {
+		post := &model.Post{
+			Title: rec.Title, Content: rec.Content, Summary: rec.Summary, Slug: rec.Slug, Cover: rec.Cover,
+			AuthorID: im.author(rec.AuthorID), Status: rec.Status, PublishAt: rec.PublishAt,
+			CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt,
+		}
+		if rec.CategoryID != "" {
+			if id, ok := im.categories[rec.CategoryID]; ok {
+				post.CategoryID = id
+			} else {
+				im.report.warn("post %q: category %s not found in archive", rec.Slug, rec.CategoryID)
+			}
+		}
+		if err := normalizeImportedPost(post, rec.Views); err != nil {
+			return fmt.Errorf("post %q: %w", rec.Slug, err)
+		}
+
+		existing, err := im.findExisting(&model.Post{}, "slug = ?", rec.Slug)
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			switch im.strategy {
+			case ConflictSkip:
+				im.posts[rec.ID] = existing
+				count.Skipped++
+				continue
+			case ConflictOverwrite:
+				err := im.tx.Unscoped().Model(&model.Post{}).Where("id = ?", existing).Updates(map[string]any{
+					"title": post.Title, "content": post.Content, "content_html": post.ContentHTML, "summary": post.Summary,
+					"cover": post.Cover, "category_id": post.CategoryID, "author_id": post.AuthorID, "views": post.Views,
+					"is_published": post.IsPublished, "status": post.Status, "publish_at": post.PublishAt, "deleted_at": nil,
+				}).Error
+				if err != nil {
+					return fmt.Errorf("post %q: %w", rec.Slug, err)
+				}
+				if err := im.tx.Exec("DELETE FROM post_tags WHERE post_id = ?", existing).Error; err != nil {
+					return err
+				}
+				im.posts[rec.ID] = existing
+				if err := im.linkTags(existing, tagsOf[rec.ID]); err != nil {
+					return err
+				}
+				count.Updated++
+				continue
+			case ConflictRename:
+				if post.Slug, err = uniqueValue(im.tx, &model.Post{}, "slug", rec.Slug, "-%d"); err != nil {
+					return err
+				}
+				count.Renamed++
+			}
+		}
+
+		if err := im.tx.Omit("Tags").Create(post).Error; err != nil {
+			return fmt.Errorf("post %q: %w", rec.Slug, err)
+		}
+		im.posts[rec.ID] = post.ID
+		if err := im.linkTags(post.ID, tagsOf[rec.ID]); err != nil {
+			return err
+		}
+		count.Created++
+	}
+	return nil
+}
+
+// normalizeImportedPost 推导发布状态并重新渲染 HTML (不信任归档中的 HTML)
+func normalizeImportedPost(post *model.Post, views uint) error {
+	// 未记录发布时间的已发布文章以创建时间作为发布时间
+	if post.Status == model.PostStatusPublished && post.PublishAt == nil {
+		post.PublishAt = &post.CreatedAt
+	}
+	if err := normalizePostStatus(post, time.Now()); err != nil {
+		return err
+	}
+	post.Views = &views
+	return renderContent(post)
+}
+
+// linkTags 建立文章与标签的关联 (标签 ID 为归档中的旧 ID)
+func (im *importer) linkTags(postID string, oldTagIDs []string) error {
+	seen := make(map[string]bool)
+	for _, old := range oldTagIDs {
+		tagID, ok := im.tags[old]
+		if !ok || seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		if err := im.tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tagID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importLinks 导入友链，按 URL 匹配已有记录
+func (im *importer) importLinks() error {
+	var records []backupLink
+	if err := readJSON(im.zr, backupLinksFile, &records); err != nil {
+		return err
+	}
+	count := im.report.count("links")
+	for _, rec := range records {
+		existing, err := im.findExisting(&model.Link{}, "url = ?", rec.URL)
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			if im.strategy != ConflictOverwrite {
+				count.Skipped++
+				continue
+			}
+			if err := im.tx.Unscoped().Model(&model.Link{}).Where("id = ?", existing).Updates(map[string]any{
+				"name": rec.Name, "description": rec.Description, "sort": rec.Sort, "deleted_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+			count.Updated++
+			continue
+		}
+		link := &model.Link{Name: rec.Name, URL: rec.URL, Description: rec.Description, Sort: rec.Sort, CreatedAt: rec.CreatedAt}
+		if err := im.tx.Create(link).Error; err != nil {
+			return fmt.Errorf("link %q: %w", rec.URL, err)
+		}
+		count.Created++
+	}
+	return nil
+}
+
+// importSiteConfig 导入站点配置 (已存在时仅在覆盖策略下更新)
+func (im *importer) importSiteConfig() error {
+	var rec *backupSiteConfig
+	if err := readJSON(im.zr, backupSiteFile, &rec); err != nil || rec == nil {
+		return err
+	}
+	count := im.report.count("site_config")
+	var site model.SiteConfig
+	if im.tx.Limit(1).Find(&site).RowsAffected > 0 {
+		if im.strategy != ConflictOverwrite {
+			count.Skipped++
+			return nil
+		}
+		if err := im.tx.Model(&site).Updates(map[string]any{
+			"title": rec.Title, "subtitle": rec.Subtitle, "description": rec.Description, "keywords": rec.Keywords,
+			"author": rec.Author, "email": rec.Email, "github_url": rec.GithubURL,
+		}).Error; err != nil {
+			return err
+		}
+		count.Updated++
+		return nil
+	}
+	if err := im.tx.Create(&model.SiteConfig{
+		Title: rec.Title, Subtitle: rec.Subtitle, Description: rec.Description, Keywords: rec.Keywords,
+		Author: rec.Author, Email: rec.Email, GithubURL: rec.GithubURL,
+	}).Error; err != nil {
+		return err
+	}
+	count.Created++
+	return nil
+}
+
+// importMedia 导入媒体记录，按存储路径匹配已有记录；文件在事务提交后写入存储
+func (im *importer) importMedia() error {
+	var records []backupMedia
+	if err := readJSON(im.zr, backupMediaFile, &records); err != nil {
+		return err
+	}
+	count := im.report.count("media")
+	for _, rec := range records {
+		keys := mediaKeys(&rec)
+		missing := false
+		for _, key := range keys {
+			if _, err := im.zr.Open(backupFilesDir + key); err != nil {
+				missing = true
+			}
+		}
+		if missing {
+			im.report.warn("media %s: file missing from archive, skipped", rec.Key)
+			count.Skipped++
+			continue
+		}
+
+		media := &model.Media{
+			FileName: rec.FileName, MimeType: rec.MimeType, Size: rec.Size, Width: rec.Width, Height: rec.Height,
+			Key: rec.Key, URL: im.storage.URL(rec.Key), ThumbnailKey: rec.ThumbnailKey, WebPKey: rec.WebPKey,
+			UploaderID: im.author(rec.UploaderID), CreatedAt: rec.CreatedAt,
+		}
+		if rec.ThumbnailKey != "" {
+			media.ThumbnailURL = im.storage.URL(rec.ThumbnailKey)
+		}
+		if rec.WebPKey != "" {
+			media.WebPURL = im.storage.URL(rec.WebPKey)
+		}
+
+		existing, err := im.findExisting(&model.Media{}, &model.Media{Key: rec.Key})
+		if err != nil {
+			return err
+		}
+		if existing != "" {
+			if im.strategy != ConflictOverwrite {
+				count.Skipped++
+				continue
+			}
+			if err := im.tx.Model(&model.Media{}).Where("id = ?", existing).Select(
+				"file_name", "mime_type", "size", "width", "height", "url", "thumbnail_key", "thumbnail_url", "web_p_key", "web_p_url",
+			).Updates(media).Error; err != nil {
+				return err
+			}
+			count.Updated++
+		} else {
+			if err := im.tx.Create(media).Error; err != nil {
+				return fmt.Errorf("media %q: %w", rec.Key, err)
+			}
+			count.Created++
+		}
+		for _, key := range keys {
+			contentType := rec.MimeType
+			if key != rec.Key {
+				contentType = ""
+			}
+			im.files = append(im.files, importFile{key: key, contentType: contentType})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/rbac"
+	"go-blog/pkg/storage"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化数据库与本地存储 (每个站点使用独立的数据库文件与存储目录)
+func setupBackupTestSite(t *testing.T) *BackupService {
+	dir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "blog.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Link{}, &model.SiteConfig{}, &model.Media{})
+	svc := NewBackupService(db)
+	svc.Storage = storage.NewLocalStorage(filepath.Join(dir, "uploads"), "/uploads")
+	return svc
+}
+
+// 准备导出站点的数据
+func prepareBackupData(t *testing.T, svc *BackupService) *model.Post {
+	db := svc.DB
+	admin := &model.User{Username: "admin", Password: "hash", Email: "admin@example.com", Role: "admin"}
+	db.Create(admin)
+	cat := &model.Category{Name: "Tech", Slug: "tech"}
+	db.Create(cat)
+	tag := &model.Tag{Name: "Go", Slug: "go"}
+	db.Create(tag)
+	post := &model.Post{Title: "Hello", Content: "# Hello", Slug: "hello", CategoryID: cat.ID, AuthorID: admin.ID}
+	assert.NoError(t, NewPostService(db).CreatePost(post, []string{tag.ID}))
+	db.Create(&model.Link{Name: "Go", URL: "https://go.dev"})
+	db.Create(&model.SiteConfig{Title: "My Blog"})
+
+	assert.NoError(t, svc.Storage.Put("2026/10/a.png", []byte("png"), "image/png"))
+	assert.NoError(t, svc.Storage.Put("2026/10/a_thumb.png", []byte("thumb"), "image/png"))
+	db.Create(&model.Media{FileName: "a.png", MimeType: "image/png", Size: 3, Key: "2026/10/a.png", URL: "/uploads/2026/10/a.png",
+		ThumbnailKey: "2026/10/a_thumb.png", UploaderID: admin.ID})
+
+	// 回收站中的记录不导出
+	trashed := &model.Tag{Name: "Old", Slug: "old"}
+	db.Create(trashed)
+	db.Delete(trashed)
+	return post
+}
+
+func exportBackup(t *testing.T, svc *BackupService, opts *ExportOptions) *bytes.Reader {
+	var buf bytes.Buffer
+	assert.NoError(t, svc.Export(&buf, opts))
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestBackupService_Export(t *testing.T) {
+	src := setupBackupTestSite(t)
+	prepareBackupData(t, src)
+
+	// Case 1: 归档包含清单与各类数据，默认不含密码哈希
+	archive := exportBackup(t, src, nil)
+	zr, err := zip.NewReader(archive, archive.Size())
+	assert.NoError(t, err)
+	var manifest BackupManifest
+	assert.NoError(t, readJSON(zr, backupManifestFile, &manifest))
+	assert.Equal(t, BackupFormat, manifest.Format)
+	assert.Equal(t, BackupVersion, manifest.Version)
+	assert.Equal(t, 1, manifest.Counts["users"])
+	assert.Equal(t, 1, manifest.Counts["tags"]) // 不含回收站中的标签
+	assert.Equal(t, 1, manifest.Counts["post_tags"])
+	assert.Equal(t, 1, manifest.Counts["media"])
+	assert.Empty(t, manifest.Warnings)
+
+	var users []backupUser
+	assert.NoError(t, readJSON(zr, backupUsersFile, &users))
+	assert.Empty(t, users[0].Password)
+	data, err := readFile(zr, backupFilesDir+"2026/10/a_thumb.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "thumb", string(data))
+
+	// Case 2: 指定包含密码
+	archive = exportBackup(t, src, &ExportOptions{IncludePasswords: true})
+	zr, _ = zip.NewReader(archive, archive.Size())
+	assert.NoError(t, readJSON(zr, backupUsersFile, &users))
+	assert.Equal(t, "hash", users[0].Password)
+}
+
+func TestBackupService_Import(t *testing.T) {
+	src := setupBackupTestSite(t)
+	srcPost := prepareBackupData(t, src)
+	archive := exportBackup(t, src, &ExportOptions{IncludePasswords: true})
+
+	dst := setupBackupTestSite(t)
+
+	// Case 1: 试运行只统计，不写入任何数据
+	report, err := dst.Import(archive, archive.Size(), &ImportOptions{DryRun: true})
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Resources["posts"].Created)
+	var count int64
+	dst.DB.Model(&model.Post{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	_, err = dst.Storage.Get("2026/10/a.png")
+	assert.Error(t, err)
+
+	// Case 2: 导入到空站点，ID 重新生成并保持关联
+	report, err = dst.Import(archive, archive.Size(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictSkip, report.Strategy)
+	for _, name := range []string{"users", "categories", "tags", "posts", "links", "site_config", "media"} {
+		assert.Equal(t, 1, report.Resources[name].Created, name)
+	}
+	var post model.Post
+	assert.NoError(t, dst.DB.Preload("Category").Preload("Author").Preload("Tags").First(&post, "slug = ?", "hello").Error)
+	assert.NotEqual(t, srcPost.ID, post.ID)
+	assert.Equal(t, "tech", post.Category.Slug)
+	assert.Equal(t, "admin", post.Author.Username)
+	assert.Equal(t, "hash", post.Author.Password)
+	assert.Len(t, post.Tags, 1)
+	assert.Contains(t, post.ContentHTML, "<h1")
+	assert.True(t, *post.IsPublished)
+
+	var media model.Media
+	assert.NoError(t, dst.DB.First(&media).Error)
+	assert.Equal(t, post.AuthorID, media.UploaderID)
+	assert.Equal(t, "/uploads/2026/10/a_thumb.png", media.ThumbnailURL)
+	data, err := dst.Storage.Get("2026/10/a_thumb.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "thumb", string(data))
+
+	// Case 3: 再次导入，默认跳过已存在的记录
+	report, err = dst.Import(archive, archive.Size(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Resources["posts"].Skipped)
+	assert.Equal(t, 1, report.Resources["users"].Skipped)
+	dst.DB.Model(&model.Post{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Case 4: 覆盖策略更新已有文章，恢复回收站中的记录
+	dst.DB.Model(&model.Post{}).Where("id = ?", post.ID).Update("title", "Changed")
+	dst.DB.Delete(&model.Link{}, "url = ?", "https://go.dev")
+	report, err = dst.Import(archive, archive.Size(), &ImportOptions{Strategy: ConflictOverwrite})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Resources["posts"].Updated)
+	assert.Equal(t, 1, report.Resources["links"].Updated)
+	assert.NoError(t, dst.DB.Preload("Tags").First(&post, "id = ?", post.ID).Error)
+	assert.Equal(t, "Hello", post.Title)
+	assert.Len(t, post.Tags, 1)
+	dst.DB.Model(&model.Link{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Case 5: 重命名策略以新的 slug 创建文章、分类与标签
+	report, err = dst.Import(archive, archive.Size(), &ImportOptions{Strategy: ConflictRename})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Resources["posts"].Renamed)
+	var renamed model.Post
+	assert.NoError(t, dst.DB.Preload("Category").Preload("Tags").First(&renamed, "slug = ?", "hello-2").Error)
+	assert.Equal(t, "tech-2", renamed.Category.Slug)
+	assert.Equal(t, "Tech (2)", renamed.Category.Name)
+	assert.Equal(t, "go-2", renamed.Tags[0].Slug)
+	assert.Equal(t, 1, report.Resources["media"].Skipped)
+
+	// Case 6: 无效的冲突策略
+	_, err = dst.Import(archive, archive.Size(), &ImportOptions{Strategy: "merge"})
+	assert.Error(t, err)
+}
+
+func TestBackupService_ImportWithoutPasswords(t *testing.T) {
+	src := setupBackupTestSite(t)
+	prepareBackupData(t, src)
+	archive := exportBackup(t, src, nil)
+
+	// Case 1: 归档不含密码时设置随机密码并要求修改
+	dst := setupBackupTestSite(t)
+	report, err := dst.Import(archive, archive.Size(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, report.Warnings, 1)
+	var user model.User
+	assert.NoError(t, dst.DB.First(&user, "username = ?", "admin").Error)
+	assert.True(t, user.MustChangePassword)
+	assert.NotEqual(t, "hash", user.Password)
+}
+
+func TestBackupService_ImportInvalidRole(t *testing.T) {
+	src := setupBackupTestSite(t)
+	prepareBackupData(t, src)
+	src.DB.Create(&model.User{Username: "mallory", Password: "hash", Email: "mallory@example.com", Role: "superuser"})
+	archive := exportBackup(t, src, &ExportOptions{IncludePasswords: true})
+
+	// Case 1: 未知角色降级为 viewer 并给出警告，合法角色保持不变
+	dst := setupBackupTestSite(t)
+	report, err := dst.Import(archive, archive.Size(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], `unknown role "superuser"`)
+	var user model.User
+	assert.NoError(t, dst.DB.First(&user, "username = ?", "mallory").Error)
+	assert.Equal(t, rbac.RoleViewer, user.Role)
+	var admin model.User
+	assert.NoError(t, dst.DB.First(&admin, "username = ?", "admin").Error)
+	assert.Equal(t, rbac.RoleAdmin, admin.Role)
+}
+
+func TestBackupService_ImportInvalidArchive(t *testing.T) {
+	dst := setupBackupTestSite(t)
+
+	// Case 1: 非 zip 文件
+	_, err := dst.Import(bytes.NewReader([]byte("not a zip")), 9, nil)
+	assert.Error(t, err)
+
+	// Case 2: 不支持的版本
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create(backupManifestFile)
+	json.NewEncoder(f).Encode(BackupManifest{Format: BackupFormat, Version: BackupVersion + 1})
+	zw.Close()
+	_, err = dst.Import(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	assert.ErrorContains(t, err, "unsupported archive version")
+}
+
+func TestBackupService_ExportBatches(t *testing.T) {
+	src := setupBackupTestSite(t)
+
+	// Case 1: 记录数超过一批时不重复、不遗漏 (主键为随机 UUID，与创建时间的顺序无关)
+	tags := make([]model.Tag, 450)
+	now := time.Now()
+	for i := range tags {
+		tags[i] = model.Tag{Name: fmt.Sprintf("tag-%d", i), Slug: fmt.Sprintf("tag-%d", i), CreatedAt: now.Add(time.Duration(i) * time.Second)}
+	}
+	assert.NoError(t, src.DB.Create(&tags).Error)
+
+	archive := exportBackup(t, src, nil)
+	zr, err := zip.NewReader(archive, archive.Size())
+	assert.NoError(t, err)
+	var records []backupTerm
+	assert.NoError(t, readJSON(zr, backupTagsFile, &records))
+	assert.Len(t, records, 450)
+	ids := make(map[string]bool)
+	for _, rec := range records {
+		ids[rec.ID] = true
+	}
+	assert.Len(t, ids, 450)
+}
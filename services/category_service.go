@@ -3,6 +3,8 @@ package service
 import (
 	"errors"
 	"go-blog/model"
+	"go-blog/pkg/database"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -12,6 +14,10 @@ type ICategoryService interface {
 	GetCategoryList() ([]model.Category, error)
 	UpdateCategory(id, name, slug string) error
 	DeleteCategory(id string) error
+	GetTrash() ([]model.Category, error)
+	RestoreCategory(id string) error
+	PurgeCategory(id string) error
+	PurgeTrash(before time.Time) (int64, error)
 }
 
 type CategoryService struct {
@@ -26,6 +32,9 @@ var _ ICategoryService = (*CategoryService)(nil)
 
 // CreateCategory 创建分类
 func (cs *CategoryService) CreateCategory(name, slug string) (*model.Category, error) {
+	if err := cs.checkTrashConflict(name, slug); err != nil {
+		return nil, err
+	}
 	category := &model.Category{
 		Name: name,
 		Slug: slug,
@@ -39,7 +48,7 @@ func (cs *CategoryService) CreateCategory(name, slug string) (*model.Category, e
 // GetCategoryList 获取全部分类
 func (cs *CategoryService) GetCategoryList() ([]model.Category, error) {
 	categories := make([]model.Category, 0)
-	if err := cs.DB.Order("created_at desc").Find(&categories).Error; err != nil {
+	if err := database.Reader(cs.DB).Order("created_at desc").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -47,13 +56,16 @@ func (cs *CategoryService) GetCategoryList() ([]model.Category, error) {
 
 // UpdateCategory 更新分类
 func (cs *CategoryService) UpdateCategory(id, name, slug string) error {
+	if err := cs.checkTrashConflict(name, slug); err != nil {
+		return err
+	}
 	return cs.DB.Model(&model.Category{}).Where("id = ?", id).Updates(map[string]any{
 		"name": name,
 		"slug": slug,
 	}).Error
 }
 
-// DeleteCategory 删除分类
+// DeleteCategory 删除分类 (移入回收站，仍有文章时拒绝删除)
 func (cs *CategoryService) DeleteCategory(id string) error {
 	var count int64
 	if err := cs.DB.Model(&model.Post{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
@@ -64,3 +76,43 @@ func (cs *CategoryService) DeleteCategory(id string) error {
 	}
 	return cs.DB.Delete(&model.Category{}, "id = ?", id).Error
 }
+
+// GetTrash 获取回收站中的分类
+func (cs *CategoryService) GetTrash() ([]model.Category, error) {
+	return listTrash[model.Category](cs.DB)
+}
+
+// RestoreCategory 从回收站恢复分类
+func (cs *CategoryService) RestoreCategory(id string) error {
+	return restoreTrashed[model.Category](cs.DB, id)
+}
+
+// PurgeCategory 彻底删除回收站中的分类 (仍被回收站中的文章引用时拒绝)
+func (cs *CategoryService) PurgeCategory(id string) error {
+	return cs.DB.Transaction(func(tx *gorm.DB) error {
+		category, err := findTrashed[model.Category](tx, id)
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Unscoped().Model(&model.Post{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("category is still used by posts in trash")
+		}
+		return tx.Unscoped().Delete(category).Error
+	})
+}
+
+// PurgeTrash 彻底删除删除时间早于 before 的分类
+func (cs *CategoryService) PurgeTrash(before time.Time) (int64, error) {
+	return purgeExpired[model.Category](cs.DB, before, cs.PurgeCategory)
+}
+
+func (cs *CategoryService) checkTrashConflict(name, slug string) error {
+	if err := checkTrashConflict(cs.DB, &model.Category{}, "name", name); err != nil {
+		return err
+	}
+	return checkTrashConflict(cs.DB, &model.Category{}, "slug", slug)
+}
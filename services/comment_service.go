@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"go-blog/model"
+
+	"gorm.io/gorm"
+)
+
+type CommentListReq struct {
+	Page     int
+	PageSize int
+	PostID   string
+	Status   string // 为空表示不筛选
+}
+
+type ICommentService interface {
+	CreateComment(slug string, comment *model.Comment) error
+	GetApprovedComments(slug string) ([]model.Comment, error)
+	GetCommentList(req *CommentListReq) ([]model.Comment, int64, error)
+	UpdateCommentStatus(id, status string) error
+	DeleteComment(id string) error
+}
+
+type CommentService struct {
+	DB *gorm.DB
+}
+
+func NewCommentService(db *gorm.DB) *CommentService {
+	return &CommentService{DB: db}
+}
+
+var _ ICommentService = (*CommentService)(nil)
+
+// CreateComment 发表评论 (新评论默认进入待审核队列)
+func (cs *CommentService) CreateComment(slug string, comment *model.Comment) error {
+	var post model.Post
+	if err := cs.DB.Select("id", "is_published").First(&post, "slug = ?", slug).Error; err != nil {
+		return errors.New("post not found")
+	}
+	if post.IsPublished != nil && !*post.IsPublished {
+		return errors.New("post not found")
+	}
+
+	// 回复的父评论必须属于同一篇文章
+	if comment.ParentID != nil && *comment.ParentID != "" {
+		var parent model.Comment
+		if err := cs.DB.Select("id", "post_id").First(&parent, "id = ?", *comment.ParentID).Error; err != nil {
+			return errors.New("parent comment not found")
+		}
+		if parent.PostID != post.ID {
+			return errors.New("parent comment belongs to another post")
+		}
+	} else {
+		comment.ParentID = nil
+	}
+
+	comment.PostID = post.ID
+	comment.Status = model.CommentStatusPending
+	return cs.DB.Create(comment).Error
+}
+
+// GetApprovedComments 获取文章下已通过审核的评论 (树形结构)
+func (cs *CommentService) GetApprovedComments(slug string) ([]model.Comment, error) {
+	var post model.Post
+	if err := cs.DB.Select("id").First(&post, "slug = ?", slug).Error; err != nil {
+		return nil, errors.New("post not found")
+	}
+
+	comments := make([]model.Comment, 0)
+	// 公开接口不返回邮箱、IP 等隐私字段
+	err := cs.DB.Omit("author_email", "ip", "user_agent").
+		Where("post_id = ? and status = ?", post.ID, model.CommentStatusApproved).
+		Order("created_at asc").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCommentTree(comments), nil
+}
+
+// buildCommentTree 将平铺的评论组装为树，父评论不可见的回复会被丢弃
+func buildCommentTree(comments []model.Comment) []model.Comment {
+	children := make(map[string][]int)
+	roots := make([]int, 0)
+	for i, c := range comments {
+		if c.ParentID == nil {
+			roots = append(roots, i)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], i)
+	}
+
+	var build func(i int) model.Comment
+	build = func(i int) model.Comment {
+		c := comments[i]
+		for _, child := range children[c.ID] {
+			c.Replies = append(c.Replies, build(child))
+		}
+		return c
+	}
+
+	tree := make([]model.Comment, 0, len(roots))
+	for _, i := range roots {
+		tree = append(tree, build(i))
+	}
+	return tree
+}
+
+// GetCommentList 获取评论列表 (后台审核用，支持分页与状态筛选)
+func (cs *CommentService) GetCommentList(req *CommentListReq) ([]model.Comment, int64, error) {
+	comments := make([]model.Comment, 0)
+	var total int64
+
+	db := cs.DB.Model(&model.Comment{})
+	if req.PostID != "" {
+		db = db.Where("post_id = ?", req.PostID)
+	}
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := db.Offset(offset).Limit(req.PageSize).Order("created_at DESC").Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// UpdateCommentStatus 修改评论状态 (通过 / 拒绝 / 标记垃圾)
+func (cs *CommentService) UpdateCommentStatus(id, status string) error {
+	switch status {
+	case model.CommentStatusPending, model.CommentStatusApproved, model.CommentStatusRejected, model.CommentStatusSpam:
+	default:
+		return errors.New("invalid comment status")
+	}
+
+	result := cs.DB.Model(&model.Comment{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("comment not found")
+	}
+	return nil
+}
+
+// DeleteComment 删除评论及其全部回复
+func (cs *CommentService) DeleteComment(id string) error {
+	return cs.DB.Transaction(func(tx *gorm.DB) error {
+		ids := []string{id}
+		parents := []string{id}
+		// 逐层查找子评论
+		for len(parents) > 0 {
+			var childIDs []string
+			if err := tx.Model(&model.Comment{}).Where("parent_id in ?", parents).Pluck("id", &childIDs).Error; err != nil {
+				return err
+			}
+			ids = append(ids, childIDs...)
+			parents = childIDs
+		}
+		return tx.Delete(&model.Comment{}, "id in ?", ids).Error
+	})
+}
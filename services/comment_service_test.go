@@ -0,0 +1,144 @@
+package service
+
+import (
+	"go-blog/model"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupCommentTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	// 迁移 Post 和 Comment 表
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Comment{})
+	return db
+}
+
+// 准备一篇文章，返回文章
+func prepareCommentPost(db *gorm.DB, slug string, published bool) *model.Post {
+	post := &model.Post{Title: "Post", Content: "C", Slug: slug, IsPublished: &published}
+	db.Create(post)
+	return post
+}
+
+func TestCommentService_Create(t *testing.T) {
+	db := setupCommentTestDB()
+	svc := NewCommentService(db)
+	post := prepareCommentPost(db, "hello", true)
+	other := prepareCommentPost(db, "other", true)
+	prepareCommentPost(db, "draft", false)
+
+	// Case 1: 正常发表，默认待审核
+	c := &model.Comment{AuthorName: "Tom", AuthorEmail: "tom@example.com", Content: "Nice", Status: model.CommentStatusApproved}
+	err := svc.CreateComment("hello", c)
+	assert.NoError(t, err)
+	assert.Equal(t, post.ID, c.PostID)
+	assert.Equal(t, model.CommentStatusPending, c.Status)
+
+	// Case 2: 文章不存在
+	err = svc.CreateComment("404", &model.Comment{AuthorName: "A", AuthorEmail: "a@a.com", Content: "x"})
+	assert.Error(t, err)
+
+	// Case 3: 草稿文章不允许评论
+	err = svc.CreateComment("draft", &model.Comment{AuthorName: "A", AuthorEmail: "a@a.com", Content: "x"})
+	assert.Error(t, err)
+
+	// Case 4: 父评论属于其他文章
+	foreign := &model.Comment{AuthorName: "B", AuthorEmail: "b@b.com", Content: "y"}
+	svc.CreateComment("other", foreign)
+	assert.Equal(t, other.ID, foreign.PostID)
+	err = svc.CreateComment("hello", &model.Comment{ParentID: &foreign.ID, AuthorName: "A", AuthorEmail: "a@a.com", Content: "x"})
+	assert.Error(t, err)
+	assert.Equal(t, "parent comment belongs to another post", err.Error())
+}
+
+func TestCommentService_GetApprovedComments(t *testing.T) {
+	db := setupCommentTestDB()
+	svc := NewCommentService(db)
+	prepareCommentPost(db, "hello", true)
+
+	root := &model.Comment{AuthorName: "A", AuthorEmail: "a@a.com", Content: "root"}
+	svc.CreateComment("hello", root)
+	reply := &model.Comment{ParentID: &root.ID, AuthorName: "B", AuthorEmail: "b@b.com", Content: "reply"}
+	svc.CreateComment("hello", reply)
+	pending := &model.Comment{AuthorName: "C", AuthorEmail: "c@c.com", Content: "pending"}
+	svc.CreateComment("hello", pending)
+
+	// 待审核评论不可见
+	list, err := svc.GetApprovedComments("hello")
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+
+	// 通过审核后组装为树
+	assert.NoError(t, svc.UpdateCommentStatus(root.ID, model.CommentStatusApproved))
+	assert.NoError(t, svc.UpdateCommentStatus(reply.ID, model.CommentStatusApproved))
+	list, err = svc.GetApprovedComments("hello")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "root", list[0].Content)
+	assert.Empty(t, list[0].AuthorEmail) // 不泄露邮箱
+	assert.Len(t, list[0].Replies, 1)
+	assert.Equal(t, "reply", list[0].Replies[0].Content)
+
+	// 父评论被标记为垃圾后，回复也不再展示
+	assert.NoError(t, svc.UpdateCommentStatus(root.ID, model.CommentStatusSpam))
+	list, err = svc.GetApprovedComments("hello")
+	assert.NoError(t, err)
+	assert.Len(t, list, 0)
+}
+
+func TestCommentService_UpdateStatus(t *testing.T) {
+	db := setupCommentTestDB()
+	svc := NewCommentService(db)
+	prepareCommentPost(db, "hello", true)
+
+	c := &model.Comment{AuthorName: "A", AuthorEmail: "a@a.com", Content: "x"}
+	svc.CreateComment("hello", c)
+
+	// Case 1: 非法状态
+	err := svc.UpdateCommentStatus(c.ID, "unknown")
+	assert.Error(t, err)
+
+	// Case 2: 评论不存在
+	err = svc.UpdateCommentStatus("fake-id", model.CommentStatusApproved)
+	assert.Error(t, err)
+
+	// Case 3: 拒绝
+	err = svc.UpdateCommentStatus(c.ID, model.CommentStatusRejected)
+	assert.NoError(t, err)
+
+	list, total, err := svc.GetCommentList(&CommentListReq{Page: 1, PageSize: 10, Status: model.CommentStatusRejected})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, c.ID, list[0].ID)
+}
+
+func TestCommentService_Delete(t *testing.T) {
+	db := setupCommentTestDB()
+	svc := NewCommentService(db)
+	prepareCommentPost(db, "hello", true)
+
+	root := &model.Comment{AuthorName: "A", AuthorEmail: "a@a.com", Content: "root"}
+	svc.CreateComment("hello", root)
+	reply := &model.Comment{ParentID: &root.ID, AuthorName: "B", AuthorEmail: "b@b.com", Content: "reply"}
+	svc.CreateComment("hello", reply)
+	nested := &model.Comment{ParentID: &reply.ID, AuthorName: "C", AuthorEmail: "c@c.com", Content: "nested"}
+	svc.CreateComment("hello", nested)
+
+	// 删除根评论会连同所有回复一起删除
+	err := svc.DeleteComment(root.ID)
+	assert.NoError(t, err)
+
+	var count int64
+	db.Model(&model.Comment{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
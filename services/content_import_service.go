@@ -0,0 +1,416 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/content"
+	"go-blog/pkg/rbac"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 单篇文章的导入结果
+const (
+	ImportResultCreated = "created"
+	ImportResultUpdated = "updated"
+	ImportResultRenamed = "renamed" // 以新的 slug 创建
+	ImportResultSkipped = "skipped"
+	ImportResultFailed  = "failed"
+)
+
+// ContentFile 待导入的文件：Markdown (Hexo / Hugo)、WordPress 导出文件 (.xml) 或包含上述文件的 zip
+type ContentFile struct {
+	Name string
+	Data []byte
+}
+
+// ContentImportOptions 导入选项
+type ContentImportOptions struct {
+	Strategy string // 与已有文章 slug 冲突时的处理策略 (skip / overwrite / rename)，默认 skip
+	AuthorID string // 作者不存在时使用的用户
+	Author   string // 同 AuthorID，按用户名指定；均为空时使用最早创建的管理员
+}
+
+// ContentImportItem 单篇文章 (或无法解析的文件) 的导入结果
+type ContentImportItem struct {
+	Source   string   `json:"source"`
+	Title    string   `json:"title,omitempty"`
+	Slug     string   `json:"slug,omitempty"`
+	Result   string   `json:"result"`
+	PostID   string   `json:"post_id,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ContentImportReport 导入结果
+type ContentImportReport struct {
+	Strategy          string              `json:"strategy"`
+	Counts            map[string]int      `json:"counts"` // 结果 -> 数量
+	CategoriesCreated []string            `json:"categories_created"`
+	TagsCreated       []string            `json:"tags_created"`
+	Items             []ContentImportItem `json:"items"`
+}
+
+type IContentImportService interface {
+	ImportFiles(files []ContentFile, opts *ContentImportOptions) (*ContentImportReport, error)
+	ExportHugo(write func(name string, data []byte) error) (int, error)
+}
+
+type ContentImportService struct {
+	DB              *gorm.DB
+	PostService     *PostService
+	CategoryService *CategoryService
+	TagService      *TagService
+}
+
+func NewContentImportService(db *gorm.DB) *ContentImportService {
+	return &ContentImportService{
+		DB:              db,
+		PostService:     NewPostService(db),
+		CategoryService: NewCategoryService(db),
+		TagService:      NewTagService(db),
+	}
+}
+
+var _ IContentImportService = (*ContentImportService)(nil)
+
+// ImportFiles 解析文件并逐篇导入文章，缺失的分类与标签自动创建
+// 每篇文章单独保存，某一篇失败不影响其他文章
+func (cs *ContentImportService) ImportFiles(files []ContentFile, opts *ContentImportOptions) (*ContentImportReport, error) {
+	if opts == nil {
+		opts = &ContentImportOptions{}
+	}
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = ConflictSkip
+	}
+	if strategy != ConflictSkip && strategy != ConflictOverwrite && strategy != ConflictRename {
+		return nil, fmt.Errorf("invalid conflict strategy %q", opts.Strategy)
+	}
+	authorID, err := cs.defaultAuthor(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ci := &contentImporter{
+		ContentImportService: cs,
+		strategy:             strategy,
+		authorID:             authorID,
+		report: &ContentImportReport{
+			Strategy:          strategy,
+			Counts:            make(map[string]int),
+			CategoriesCreated: make([]string, 0),
+			TagsCreated:       make([]string, 0),
+			Items:             make([]ContentImportItem, 0),
+		},
+		categories: make(map[string]string),
+		tags:       make(map[string]string),
+		authors:    make(map[string]string),
+	}
+	for _, f := range files {
+		ci.importFile(f.Name, f.Data)
+	}
+	return ci.report, nil
+}
+
+// defaultAuthor 作者不存在时使用的用户 ID
+func (cs *ContentImportService) defaultAuthor(opts *ContentImportOptions) (string, error) {
+	var user model.User
+	db := cs.DB.Order("created_at")
+	switch {
+	case opts.AuthorID != "":
+		db = db.Where("id = ?", opts.AuthorID)
+	case opts.Author != "":
+		db = db.Where("username = ?", opts.Author)
+	default:
+		db = db.Where("role = ?", rbac.RoleAdmin)
+	}
+	if db.Limit(1).Find(&user).RowsAffected == 0 {
+		return "", errors.New("default author not found")
+	}
+	return user.ID, nil
+}
+
+// contentImporter 单次导入的状态：已解析的分类、标签与作者 (名称 / slug -> ID)
+type contentImporter struct {
+	*ContentImportService
+	strategy   string
+	authorID   string
+	report     *ContentImportReport
+	categories map[string]string
+	tags       map[string]string
+	authors    map[string]string
+}
+
+// importFile 按扩展名解析文件
+func (ci *contentImporter) importFile(name string, data []byte) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		// Hugo 的分区页面不是文章
+		if path.Base(name) == "_index.md" {
+			return
+		}
+		e, err := content.ParseMarkdown(name, data)
+		if err != nil {
+			ci.fail(name, err)
+			return
+		}
+		ci.importEntry(e)
+	case ".xml":
+		entries, err := content.ParseWXR(bytes.NewReader(data))
+		if err != nil {
+			ci.fail(name, err)
+			return
+		}
+		for _, e := range entries {
+			ci.importEntry(e)
+		}
+	case ".zip":
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			ci.fail(name, err)
+			return
+		}
+		for _, f := range zr.File {
+			ext := strings.ToLower(path.Ext(f.Name))
+			if f.FileInfo().IsDir() || (ext != ".md" && ext != ".markdown" && ext != ".xml") {
+				continue
+			}
+			data, err := readZipFile(f)
+			if err != nil {
+				ci.fail(f.Name, err)
+				continue
+			}
+			ci.importFile(f.Name, data)
+		}
+	default:
+		ci.fail(name, errors.New("unsupported file type"))
+	}
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (ci *contentImporter) fail(source string, err error) {
+	ci.add(ContentImportItem{Source: source, Result: ImportResultFailed, Error: err.Error()})
+}
+
+func (ci *contentImporter) add(item ContentImportItem) {
+	ci.report.Items = append(ci.report.Items, item)
+	ci.report.Counts[item.Result]++
+}
+
+// importEntry 导入单篇文章
+func (ci *contentImporter) importEntry(e *content.Entry) {
+	item := ContentImportItem{Source: e.Source, Title: e.Title, Slug: e.Slug}
+	if item.Slug == "" {
+		item.Slug = content.Slugify(e.Title)
+	}
+	if item.Slug == "" {
+		item.Result, item.Error = ImportResultFailed, "slug is required"
+		ci.add(item)
+		return
+	}
+
+	post := &model.Post{
+		Title:     e.Title,
+		Content:   e.Content,
+		Summary:   e.Summary,
+		Slug:      item.Slug,
+		Cover:     e.Cover,
+		AuthorID:  ci.author(e.Author, &item),
+		Status:    model.PostStatusPublished,
+		CreatedAt: e.Date,
+		UpdatedAt: e.Updated,
+	}
+	if e.Draft {
+		post.Status = model.PostStatusDraft
+	}
+	if !e.Date.IsZero() {
+		post.PublishAt = &e.Date
+	}
+	if post.UpdatedAt.IsZero() {
+		post.UpdatedAt = e.Date
+	}
+
+	// 文章只能属于一个分类，使用第一个 (Hexo 多级分类为顶级分类)
+	if len(e.Categories) > 0 {
+		post.CategoryID = ci.resolveTerm(e.Categories[0], ci.categories, &model.Category{}, &item, func(t content.Term) (string, error) {
+			c, err := ci.CategoryService.CreateCategory(t.Name, t.Slug)
+			if err != nil {
+				return "", err
+			}
+			ci.report.CategoriesCreated = append(ci.report.CategoriesCreated, c.Name)
+			return c.ID, nil
+		})
+		if len(e.Categories) > 1 {
+			item.Warnings = append(item.Warnings, fmt.Sprintf("only the first category %q is used", e.Categories[0].Name))
+		}
+	}
+	tagIDs := make([]string, 0, len(e.Tags))
+	for _, t := range e.Tags {
+		id := ci.resolveTerm(t, ci.tags, &model.Tag{}, &item, func(t content.Term) (string, error) {
+			tag, err := ci.TagService.CreateTag(t.Name, t.Slug)
+			if err != nil {
+				return "", err
+			}
+			ci.report.TagsCreated = append(ci.report.TagsCreated, tag.Name)
+			return tag.ID, nil
+		})
+		if id != "" && !slices.Contains(tagIDs, id) {
+			tagIDs = append(tagIDs, id)
+		}
+	}
+
+	var existing model.Post
+	found := ci.DB.Where("slug = ?", post.Slug).Limit(1).Find(&existing).RowsAffected > 0
+	var err error
+	switch {
+	case found && ci.strategy == ConflictSkip:
+		item.Result, item.PostID = ImportResultSkipped, existing.ID
+		ci.add(item)
+		return
+	case found && ci.strategy == ConflictOverwrite:
+		post.ID = existing.ID
+		// 与 BackupService 覆盖导入相同的列，文件中没有的摘要、封面、分类同样清空
+		err = ci.PostService.UpdatePost(post, tagIDs, ci.authorID, "title", "content", "content_html", "summary", "cover",
+			"category_id", "author_id", "status", "is_published", "publish_at", "updated_at")
+		item.Result = ImportResultUpdated
+	case found && ci.strategy == ConflictRename:
+		if post.Slug, err = uniqueValue(ci.DB, &model.Post{}, "slug", post.Slug, "-%d"); err == nil {
+			item.Slug = post.Slug
+			err = ci.PostService.CreatePost(post, tagIDs)
+		}
+		item.Result = ImportResultRenamed
+	default:
+		err = ci.PostService.CreatePost(post, tagIDs)
+		item.Result = ImportResultCreated
+	}
+	if err != nil {
+		item.Result, item.Error = ImportResultFailed, err.Error()
+	} else {
+		item.PostID = post.ID
+	}
+	ci.add(item)
+}
+
+// author 按用户名匹配作者，不存在时使用默认作者
+func (ci *contentImporter) author(username string, item *ContentImportItem) string {
+	if username == "" {
+		return ci.authorID
+	}
+	id, ok := ci.authors[username]
+	if !ok {
+		var user model.User
+		if ci.DB.Where("username = ?", username).Limit(1).Find(&user).RowsAffected > 0 {
+			id = user.ID
+		}
+		ci.authors[username] = id
+	}
+	if id == "" {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("author %q not found, using the default author", username))
+		return ci.authorID
+	}
+	return id
+}
+
+// resolveTerm 按 slug 或名称匹配已有分类 / 标签，不存在时创建；失败时记录警告并返回空
+func (ci *contentImporter) resolveTerm(t content.Term, cache map[string]string, m any, item *ContentImportItem, create func(content.Term) (string, error)) string {
+	if t.Slug == "" {
+		t.Slug = content.Slugify(t.Name)
+	}
+	if t.Name == "" || t.Slug == "" {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("invalid category or tag %q", t.Name))
+		return ""
+	}
+	key := strings.ToLower(t.Slug)
+	if id, ok := cache[key]; ok {
+		return id
+	}
+
+	var ids []string
+	err := ci.DB.Model(m).Where("slug = ? or name = ?", t.Slug, t.Name).Limit(1).Pluck("id", &ids).Error
+	if err == nil && len(ids) == 0 {
+		var id string
+		if id, err = create(t); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if err != nil {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("%q: %v", t.Name, err))
+		return ""
+	}
+	cache[key] = ids[0]
+	return ids[0]
+}
+
+// ExportHugo 将全部文章导出为 Hugo 兼容的 Markdown 文件 (content/posts/<slug>.md)，返回导出的文章数
+// 草稿与已归档的文章标记为 draft，定时发布的文章保留未来的发布时间
+func (cs *ContentImportService) ExportHugo(write func(name string, data []byte) error) (int, error) {
+	count := 0
+	seen := make(map[string]bool) // 清理后的 slug 可能与其他文章重名
+	var posts []model.Post
+	db := cs.DB.Preload("Category").Preload("Author").Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	})
+	// FindInBatches 按主键分页，不能再指定其他排序
+	err := db.Omit("content_html").
+		FindInBatches(&posts, 100, func(tx *gorm.DB, _ int) error {
+			for i := range posts {
+				var buf bytes.Buffer
+				e := hugoEntry(&posts[i])
+				if err := content.WriteHugo(&buf, e); err != nil {
+					return err
+				}
+				name := content.HugoPath(e)
+				for n := 2; seen[name]; n++ {
+					name = fmt.Sprintf("%s-%d.md", strings.TrimSuffix(content.HugoPath(e), ".md"), n)
+				}
+				seen[name] = true
+				if err := write(name, buf.Bytes()); err != nil {
+					return err
+				}
+				count++
+			}
+			return nil
+		}).Error
+	return count, err
+}
+
+// hugoEntry 将文章转换为导出条目
+func hugoEntry(p *model.Post) *content.Entry {
+	e := &content.Entry{
+		Title:   p.Title,
+		Slug:    p.Slug,
+		Content: p.Content,
+		Summary: p.Summary,
+		Cover:   p.Cover,
+		Author:  p.Author.Username,
+		Date:    p.CreatedAt,
+		Updated: p.UpdatedAt,
+		Draft:   p.Status == model.PostStatusDraft || p.Status == model.PostStatusArchived,
+	}
+	if p.PublishAt != nil {
+		e.Date = *p.PublishAt
+	}
+	if p.Category.ID != "" {
+		e.Categories = append(e.Categories, content.Term{Name: p.Category.Name, Slug: p.Category.Slug})
+	}
+	for _, t := range p.Tags {
+		e.Tags = append(e.Tags, content.Term{Name: t.Name, Slug: t.Slug})
+	}
+	return e
+}
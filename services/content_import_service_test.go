@@ -0,0 +1,299 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go-blog/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库与默认管理员
+func setupContentImportTestDB() (*gorm.DB, *model.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.PostRevision{})
+	admin := &model.User{Username: "admin", Password: "hash", Email: "admin@example.com", Role: "admin"}
+	db.Create(admin)
+	return db, admin
+}
+
+const hexoPost = `---
+title: Hello Hexo
+date: 2020-01-02 10:30:00
+updated: 2020-01-03 08:00:00
+tags:
+  - Go
+  - 博客
+categories:
+  - [Tech, Backend]
+---
+Hexo **content**
+`
+
+const hugoPost = `+++
+title = "Hugo Post"
+date = 2021-05-06T07:08:09Z
+slug = "hugo-slug"
+draft = true
+tags = ["go"]
+categories = "Notes"
+summary = "short"
++++
+
+Hugo content
+`
+
+const wxrExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<item>
+		<title>WordPress Post</title>
+		<link>https://example.com/?p=1</link>
+		<dc:creator><![CDATA[admin]]></dc:creator>
+		<content:encoded><![CDATA[<p>Hello <strong>WordPress</strong></p>]]></content:encoded>
+		<excerpt:encoded><![CDATA[Excerpt]]></excerpt:encoded>
+		<wp:post_date><![CDATA[2019-03-04 05:06:07]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[2019-03-04 05:06:07]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[%e4%bd%a0%e5%a5%bd]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="life"><![CDATA[Life]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+	</item>
+	<item>
+		<title>About</title>
+		<wp:post_name><![CDATA[about]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[page]]></wp:post_type>
+	</item>
+	<item>
+		<title>Draft</title>
+		<dc:creator><![CDATA[nobody]]></dc:creator>
+		<wp:post_date><![CDATA[2019-03-05 00:00:00]]></wp:post_date>
+		<wp:post_date_gmt><![CDATA[0000-00-00 00:00:00]]></wp:post_date_gmt>
+		<wp:post_name><![CDATA[]]></wp:post_name>
+		<wp:status><![CDATA[draft]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+	</item>
+</channel>
+</rss>
+`
+
+func TestContentImportService_Markdown(t *testing.T) {
+	db, admin := setupContentImportTestDB()
+	svc := NewContentImportService(db)
+	svc.PostService.Searcher = nil
+
+	// Case 1: Hexo (YAML) 与 Hugo (TOML) 文章，自动创建分类与标签
+	report, err := svc.ImportFiles([]ContentFile{
+		{Name: "source/_posts/hello-hexo.md", Data: []byte(hexoPost)},
+		{Name: "content/posts/hugo/index.md", Data: []byte(hugoPost)},
+		{Name: "content/posts/_index.md", Data: []byte("---\ntitle: Posts\n---\n")},
+		{Name: "broken.md", Data: []byte("no front matter")},
+		{Name: "image.png", Data: []byte("png")},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Counts[ImportResultCreated])
+	assert.Equal(t, 2, report.Counts[ImportResultFailed])
+	assert.ElementsMatch(t, []string{"Tech", "Notes"}, report.CategoriesCreated)
+	assert.ElementsMatch(t, []string{"Go", "博客"}, report.TagsCreated) // "go" 与 "Go" 为同一标签
+	assert.Contains(t, report.Items[0].Warnings[0], "only the first category")
+
+	var hexo model.Post
+	assert.NoError(t, db.Preload("Category").Preload("Tags").First(&hexo, "slug = ?", "hello-hexo").Error)
+	assert.Equal(t, "Hello Hexo", hexo.Title)
+	assert.Equal(t, "Tech", hexo.Category.Name)
+	assert.Len(t, hexo.Tags, 2)
+	assert.Equal(t, admin.ID, hexo.AuthorID)
+	assert.Equal(t, model.PostStatusPublished, hexo.Status)
+	assert.Equal(t, time.Date(2020, 1, 2, 10, 30, 0, 0, time.UTC), hexo.CreatedAt.UTC())
+	assert.Contains(t, hexo.ContentHTML, "<strong>content</strong>")
+
+	var hugo model.Post
+	assert.NoError(t, db.First(&hugo, "slug = ?", "hugo-slug").Error)
+	assert.Equal(t, model.PostStatusDraft, hugo.Status)
+	assert.Equal(t, "short", hugo.Summary)
+
+	// Case 2: 默认跳过已存在的文章
+	report, err = svc.ImportFiles([]ContentFile{{Name: "hello-hexo.md", Data: []byte(hexoPost)}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResultSkipped, report.Items[0].Result)
+	assert.Equal(t, hexo.ID, report.Items[0].PostID)
+	assert.Empty(t, report.TagsCreated)
+
+	// Case 3: 重命名策略以新的 slug 创建
+	report, err = svc.ImportFiles([]ContentFile{{Name: "hello-hexo.md", Data: []byte(hexoPost)}}, &ContentImportOptions{Strategy: ConflictRename})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResultRenamed, report.Items[0].Result)
+	assert.Equal(t, "hello-hexo-2", report.Items[0].Slug)
+
+	// Case 4: 覆盖策略更新已有文章
+	updated := strings.Replace(hexoPost, "Hexo **content**", "New content", 1)
+	report, err = svc.ImportFiles([]ContentFile{{Name: "hello-hexo.md", Data: []byte(updated)}}, &ContentImportOptions{Strategy: ConflictOverwrite})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResultUpdated, report.Items[0].Result)
+	db.First(&hexo, "id = ?", hexo.ID)
+	assert.Equal(t, "New content\n", hexo.Content)
+
+	// Case 5: 覆盖时文件中没有的摘要、封面与分类被清空
+	db.Model(&hexo).Updates(map[string]any{"summary": "old summary", "cover": "/old.png"})
+	bare := "---\ntitle: Bare\n---\nBare content\n"
+	report, err = svc.ImportFiles([]ContentFile{{Name: "hello-hexo.md", Data: []byte(bare)}}, &ContentImportOptions{Strategy: ConflictOverwrite})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResultUpdated, report.Items[0].Result)
+	hexo = model.Post{}
+	assert.NoError(t, db.Preload("Tags").First(&hexo, "slug = ?", "hello-hexo").Error)
+	assert.Equal(t, "Bare", hexo.Title)
+	assert.Empty(t, hexo.Summary)
+	assert.Empty(t, hexo.Cover)
+	assert.Empty(t, hexo.CategoryID)
+	assert.Empty(t, hexo.Tags)
+
+	// Case 6: 无效的策略与不存在的默认作者
+	_, err = svc.ImportFiles(nil, &ContentImportOptions{Strategy: "merge"})
+	assert.Error(t, err)
+	_, err = svc.ImportFiles(nil, &ContentImportOptions{Author: "ghost"})
+	assert.Error(t, err)
+}
+
+func TestContentImportService_WXR(t *testing.T) {
+	db, admin := setupContentImportTestDB()
+	svc := NewContentImportService(db)
+	svc.PostService.Searcher = nil
+
+	// Case 1: 只导入文章，跳过页面；草稿的作者不存在时使用默认作者
+	report, err := svc.ImportFiles([]ContentFile{{Name: "wordpress.xml", Data: []byte(wxrExport)}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, report.Items, 2)
+	assert.Equal(t, 2, report.Counts[ImportResultCreated])
+
+	var post model.Post
+	assert.NoError(t, db.Preload("Category").Preload("Tags").First(&post, "slug = ?", "你好").Error)
+	assert.Equal(t, "WordPress Post", post.Title)
+	assert.Equal(t, "Excerpt", post.Summary)
+	assert.Equal(t, "life", post.Category.Slug)
+	assert.Equal(t, "go", post.Tags[0].Slug)
+	assert.Equal(t, time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC), post.PublishAt.UTC())
+	assert.Contains(t, post.ContentHTML, "<strong>WordPress</strong>")
+
+	var draft model.Post
+	assert.NoError(t, db.First(&draft, "slug = ?", "draft").Error)
+	assert.Equal(t, model.PostStatusDraft, draft.Status)
+	assert.Equal(t, admin.ID, draft.AuthorID)
+	assert.Contains(t, report.Items[1].Warnings[0], `author "nobody" not found`)
+}
+
+func TestContentImportService_ExportHugo(t *testing.T) {
+	db, _ := setupContentImportTestDB()
+	svc := NewContentImportService(db)
+	svc.PostService.Searcher = nil
+	_, err := svc.ImportFiles([]ContentFile{
+		{Name: "hello-hexo.md", Data: []byte(hexoPost)},
+		{Name: "hugo.md", Data: []byte(hugoPost)},
+	}, nil)
+	assert.NoError(t, err)
+
+	// Case 1: 导出为 Hugo 文件
+	files := make(map[string][]byte)
+	n, err := svc.ExportHugo(func(name string, data []byte) error {
+		files[name] = data
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	data := string(files["content/posts/hello-hexo.md"])
+	assert.True(t, strings.HasPrefix(data, "---\ntitle: Hello Hexo\n"))
+	assert.Contains(t, data, "draft: false")
+	assert.Contains(t, data, "author: admin")
+	assert.Contains(t, data, "categories:\n  - Tech\n")
+	assert.Contains(t, data, "\n---\n\nHexo **content**\n")
+	assert.Contains(t, string(files["content/posts/hugo-slug.md"]), "draft: true")
+
+	// Case 2: 导出的文件 (zip) 可重新导入到新站点
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		f, _ := zw.Create(name)
+		f.Write(data)
+	}
+	zw.Close()
+	target, _ := setupContentImportTestDB()
+	targetSvc := NewContentImportService(target)
+	targetSvc.PostService.Searcher = nil
+	report, err := targetSvc.ImportFiles([]ContentFile{{Name: "hugo.zip", Data: buf.Bytes()}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Counts[ImportResultCreated])
+	var post model.Post
+	assert.NoError(t, target.Preload("Tags").First(&post, "slug = ?", "hello-hexo").Error)
+	assert.Equal(t, "Hexo **content**\n", post.Content)
+	assert.Len(t, post.Tags, 2)
+}
+
+func TestContentImportService_ExportHugoUnsafeSlug(t *testing.T) {
+	db, admin := setupContentImportTestDB()
+	svc := NewContentImportService(db)
+
+	// Case 1: slug 含路径分隔符或 ".." 时文件名被清理，不会写出导出目录
+	for i, slug := range []string{"../../etc/evil", `..\win`, "etc-evil", ".."} {
+		db.Create(&model.Post{
+			Title: fmt.Sprintf("Post %d", i), Content: "content", Slug: slug,
+			AuthorID: admin.ID, Status: model.PostStatusPublished,
+		})
+	}
+	var names []string
+	n, err := svc.ExportHugo(func(name string, data []byte) error {
+		names = append(names, name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Len(t, names, 4)
+	for _, name := range names {
+		assert.True(t, strings.HasPrefix(name, "content/posts/"), name)
+		assert.NotContains(t, name, "..")
+		assert.NotContains(t, strings.TrimPrefix(name, "content/posts/"), "/")
+	}
+	assert.Contains(t, names, "content/posts/post.md")
+	assert.Contains(t, names, "content/posts/win.md")
+	// 清理后与已有 slug 重名时追加序号
+	assert.Contains(t, names, "content/posts/etc-evil.md")
+	assert.Contains(t, names, "content/posts/etc-evil-2.md")
+}
+
+func TestContentImportService_ExportHugoBatches(t *testing.T) {
+	db, admin := setupContentImportTestDB()
+	svc := NewContentImportService(db)
+
+	// Case 1: 文章数超过一批时不重复、不遗漏 (主键为随机 UUID，与创建时间的顺序无关)
+	now := time.Now()
+	for i := 0; i < 250; i++ {
+		db.Create(&model.Post{
+			Title: fmt.Sprintf("Post %d", i), Content: "content", Slug: fmt.Sprintf("post-%d", i),
+			AuthorID: admin.ID, Status: model.PostStatusPublished, CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+	}
+	files := make(map[string]int)
+	n, err := svc.ExportHugo(func(name string, data []byte) error {
+		files[name]++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 250, n)
+	assert.Len(t, files, 250)
+}
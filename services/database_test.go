@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"go-blog/config"
+	"go-blog/model"
+	"go-blog/pkg/database"
+	"go-blog/pkg/logger"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestDatabase_OpenSQLite(t *testing.T) {
+	cfg := &config.DatabaseConfig{
+		Driver: database.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "data", "blog.db"),
+		Pool:   config.DatabasePoolConfig{MaxOpenConns: 7, MaxIdleConns: 3},
+	}
+
+	// Case 1: 自动创建数据目录，启用 WAL 并应用连接池配置
+	db, err := database.Open(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", db.Dialector.Name())
+	var mode string
+	db.Raw("PRAGMA journal_mode").Scan(&mode)
+	assert.Equal(t, "wal", mode)
+	sqlDB, _ := db.DB()
+	assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+
+	// Case 2: 与 MySQL 一致使用单数表名
+	assert.NoError(t, db.AutoMigrate(&model.Link{}))
+	assert.True(t, db.Migrator().HasTable("link"))
+	sqlDB.Close()
+
+	// Case 3: 不支持的驱动
+	_, err = database.Open(&config.DatabaseConfig{Driver: "oracle"})
+	assert.ErrorContains(t, err, "unsupported")
+}
+
+func TestDatabase_PostgresTypes(t *testing.T) {
+	dialector, err := database.NewDialector(&config.DatabaseConfig{Driver: database.DriverPostgres, Host: "localhost", Port: 5432, Password: "it's secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", dialector.Name())
+
+	s, err := schema.Parse(&model.Post{}, &sync.Map{}, schema.NamingStrategy{SingularTable: true})
+	assert.NoError(t, err)
+
+	// Case 1: MySQL 专用的 longtext 转换为 text，其余类型保持不变
+	assert.Equal(t, "text", dialector.DataTypeOf(s.LookUpField("Content")))
+	assert.Equal(t, "text", dialector.DataTypeOf(s.LookUpField("ContentHTML")))
+	assert.Equal(t, "char(36)", dialector.DataTypeOf(s.LookUpField("ID")))
+	assert.Equal(t, "varchar(255)", dialector.DataTypeOf(s.LookUpField("Title")))
+}
+
+func TestDatabase_Replicas(t *testing.T) {
+	logger.Log = zap.NewNop().Sugar()
+	dir := t.TempDir()
+	primaryPath, replicaPath := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+
+	// 准备主库与副本中不同的数据，便于区分查询走向
+	for path, name := range map[string]string{primaryPath: "primary", replicaPath: "replica"} {
+		db, err := database.Open(&config.DatabaseConfig{Driver: database.DriverSQLite, Path: path})
+		assert.NoError(t, err)
+		db.AutoMigrate(&model.Link{})
+		db.Create(&model.Link{Name: name, URL: "https://" + name})
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}
+
+	db, err := database.Open(&config.DatabaseConfig{
+		Driver: database.DriverSQLite,
+		Path:   primaryPath,
+		Replicas: []config.DatabaseReplicaConfig{
+			{Name: "r1", Path: replicaPath},
+			{Name: "broken", Path: dir}, // 目录无法作为数据库打开
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, database.HasReplicas(db))
+	linkSvc := NewLinkService(db)
+
+	// Case 1: 首次健康检查前副本视为不可用，读取主库
+	links, _ := linkSvc.GetLinkList()
+	assert.Equal(t, "primary", links[0].Name)
+
+	// Case 2: 分别报告每个连接的状态
+	health := database.Check(context.Background(), db)
+	assert.Len(t, health, 3)
+	assert.Equal(t, database.RolePrimary, health[0].Role)
+	assert.Equal(t, database.StatusUp, health[0].Status)
+	assert.Equal(t, database.StatusUp, health[1].Status)
+	assert.Equal(t, "broken", health[2].Name)
+	assert.Equal(t, database.StatusDown, health[2].Status)
+	assert.NotEmpty(t, health[2].Error)
+
+	// Case 3: 公开读接口只使用健康的副本，写入与事务使用主库
+	for i := 0; i < 4; i++ {
+		links, err = linkSvc.GetLinkList()
+		assert.NoError(t, err)
+		assert.Equal(t, "replica", links[0].Name)
+	}
+	assert.NoError(t, linkSvc.CreateLink(&model.Link{Name: "new", URL: "https://new", Sort: 10}))
+	var count int64
+	db.Model(&model.Link{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+	db.Transaction(func(tx *gorm.DB) error {
+		assert.Same(t, tx, database.Reader(tx))
+		return nil
+	})
+
+	// Case 4: 未注册副本时 Reader 返回原连接
+	plain := setupAuditTestDB()
+	assert.Same(t, plain, database.Reader(plain))
+	assert.Len(t, database.Check(context.Background(), plain), 1)
+
+	// Case 5: 主库不可用且不重试时直接返回错误
+	_, err = database.Open(&config.DatabaseConfig{Driver: database.DriverSQLite, Path: dir})
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"go-blog/model"
+	"go-blog/pkg/database"
+	"go-blog/pkg/feed"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 订阅源默认条目数
+const defaultFeedSize = 20
+
+type FeedReq struct {
+	BaseURL      string // 站点根地址，用于拼接文章链接
+	FeedURL      string // 订阅源自身地址
+	CategorySlug string
+	TagSlug      string
+}
+
+type IFeedService interface {
+	GetFeed(req *FeedReq) (*feed.Channel, error)
+}
+
+type FeedService struct {
+	DB            *gorm.DB
+	PostService   IPostService
+	ConfigService IConfigService
+}
+
+func NewFeedService(db *gorm.DB) *FeedService {
+	return &FeedService{
+		DB:            db,
+		PostService:   NewPostService(db),
+		ConfigService: NewConfigService(db),
+	}
+}
+
+var _ IFeedService = (*FeedService)(nil)
+
+// GetFeed 获取订阅源 (可按分类 / 标签筛选)
+func (fs *FeedService) GetFeed(req *FeedReq) (*feed.Channel, error) {
+	site, err := fs.ConfigService.GetSiteConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimRight(req.BaseURL, "/")
+	published := true
+	listReq := &PostListReq{Page: 1, PageSize: defaultFeedSize, IsPublished: &published}
+	title := site.Title
+
+	if req.CategorySlug != "" {
+		var category model.Category
+		if err := database.Reader(fs.DB).First(&category, "slug = ?", req.CategorySlug).Error; err != nil {
+			return nil, errors.New("category not found")
+		}
+		listReq.CategoryID = category.ID
+		title = joinFeedTitle(site.Title, category.Name)
+	}
+	if req.TagSlug != "" {
+		var tag model.Tag
+		if err := database.Reader(fs.DB).First(&tag, "slug = ?", req.TagSlug).Error; err != nil {
+			return nil, errors.New("tag not found")
+		}
+		listReq.TagID = tag.ID
+		title = joinFeedTitle(site.Title, tag.Name)
+	}
+
+	posts, _, err := fs.PostService.GetPostList(listReq)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &feed.Channel{
+		Title:       title,
+		Link:        baseURL + "/",
+		FeedURL:     req.FeedURL,
+		Description: site.Description,
+		Author:      site.Author,
+		Email:       site.Email,
+		Items:       make([]feed.Item, 0, len(posts)),
+	}
+	for _, p := range posts {
+		item := feed.Item{
+			Title:     p.Title,
+			Link:      baseURL + "/posts/" + p.Slug,
+			Summary:   p.Summary,
+			Author:    p.Author.Username,
+			Published: p.CreatedAt,
+			Updated:   p.UpdatedAt,
+		}
+		if p.PublishAt != nil {
+			item.Published = *p.PublishAt
+		}
+		if p.Category.Name != "" {
+			item.Categories = append(item.Categories, p.Category.Name)
+		}
+		for _, t := range p.Tags {
+			item.Categories = append(item.Categories, t.Name)
+		}
+		ch.Items = append(ch.Items, item)
+
+		// 以最新文章的更新时间作为订阅源的修改时间
+		if p.UpdatedAt.After(ch.Updated) {
+			ch.Updated = p.UpdatedAt
+		}
+	}
+
+	return ch, nil
+}
+
+func joinFeedTitle(site, sub string) string {
+	if site == "" {
+		return sub
+	}
+	return site + " - " + sub
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"go-blog/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupFeedTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.SiteConfig{})
+	return db
+}
+
+func TestFeedService_GetFeed(t *testing.T) {
+	db := setupFeedTestDB()
+	svc := NewFeedService(db)
+	postSvc := NewPostService(db)
+	catID, tagID := prepareData(db)
+	db.Create(&model.SiteConfig{Title: "My Blog", Description: "Desc", Author: "Bread", Email: "me@example.com"})
+
+	newest := time.Now().Add(-time.Minute).Truncate(time.Second)
+	postSvc.CreatePost(&model.Post{Title: "Go", Content: "C", Slug: "go-post", CategoryID: catID}, []string{tagID})
+	postSvc.CreatePost(&model.Post{Title: "Other", Content: "C", Slug: "other-post"}, nil)
+	isPub := false
+	postSvc.CreatePost(&model.Post{Title: "Draft", Content: "C", Slug: "draft", CategoryID: catID, IsPublished: &isPub}, nil)
+	db.Model(&model.Post{}).Where("slug = ?", "go-post").UpdateColumn("updated_at", newest)
+	db.Model(&model.Post{}).Where("slug = ?", "other-post").UpdateColumn("updated_at", newest.Add(-time.Hour))
+
+	// Case 1: 全站订阅，不包含草稿
+	ch, err := svc.GetFeed(&FeedReq{BaseURL: "https://example.com/", FeedURL: "https://example.com/feed.xml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "My Blog", ch.Title)
+	assert.Len(t, ch.Items, 2)
+	assert.True(t, newest.Equal(ch.Updated))
+
+	rss, err := ch.RSS()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rss), "<link>https://example.com/posts/go-post</link>")
+	assert.Contains(t, string(rss), "<managingEditor>me@example.com (Bread)</managingEditor>")
+	assert.NotContains(t, string(rss), "draft")
+
+	atom, err := ch.Atom()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(atom), `<feed xmlns="http://www.w3.org/2005/Atom">`))
+
+	// Case 2: 分类订阅
+	ch, err = svc.GetFeed(&FeedReq{BaseURL: "https://example.com", CategorySlug: "tech"})
+	assert.NoError(t, err)
+	assert.Equal(t, "My Blog - Tech", ch.Title)
+	assert.Len(t, ch.Items, 1)
+	assert.Equal(t, []string{"Tech", "Go"}, ch.Items[0].Categories)
+
+	// Case 3: 标签订阅
+	ch, err = svc.GetFeed(&FeedReq{BaseURL: "https://example.com", TagSlug: "go"})
+	assert.NoError(t, err)
+	assert.Len(t, ch.Items, 1)
+
+	// Case 4: 分类不存在
+	_, err = svc.GetFeed(&FeedReq{CategorySlug: "404"})
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"go-blog/model"
+	"go-blog/pkg/database"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +13,10 @@ type ILinkService interface {
 	GetLinkList() ([]model.Link, error)
 	UpdateLink(id string, link *model.Link) error
 	DeleteLink(id string) error
+	GetTrash() ([]model.Link, error)
+	RestoreLink(id string) error
+	PurgeLink(id string) error
+	PurgeTrash(before time.Time) (int64, error)
 }
 
 type LinkService struct {
@@ -31,7 +37,7 @@ func (ls *LinkService) CreateLink(link *model.Link) error {
 // GetLinkList 获取链接列表
 func (ls *LinkService) GetLinkList() ([]model.Link, error) {
 	links := make([]model.Link, 0)
-	if err := ls.DB.Order("sort desc, created_at desc").Find(&links).Error; err != nil {
+	if err := database.Reader(ls.DB).Order("sort desc, created_at desc").Find(&links).Error; err != nil {
 		return nil, err
 	}
 	return links, nil
@@ -42,7 +48,31 @@ func (ls *LinkService) UpdateLink(id string, link *model.Link) error {
 	return ls.DB.Model(&model.Link{}).Where("id = ?", id).Updates(link).Error
 }
 
-// DeleteLink 删除链接
+// DeleteLink 删除链接 (移入回收站)
 func (ls *LinkService) DeleteLink(id string) error {
 	return ls.DB.Delete(&model.Link{}, "id = ?", id).Error
 }
+
+// GetTrash 获取回收站中的链接
+func (ls *LinkService) GetTrash() ([]model.Link, error) {
+	return listTrash[model.Link](ls.DB)
+}
+
+// RestoreLink 从回收站恢复链接
+func (ls *LinkService) RestoreLink(id string) error {
+	return restoreTrashed[model.Link](ls.DB, id)
+}
+
+// PurgeLink 彻底删除回收站中的链接
+func (ls *LinkService) PurgeLink(id string) error {
+	link, err := findTrashed[model.Link](ls.DB, id)
+	if err != nil {
+		return err
+	}
+	return ls.DB.Unscoped().Delete(link).Error
+}
+
+// PurgeTrash 彻底删除删除时间早于 before 的链接
+func (ls *LinkService) PurgeTrash(before time.Time) (int64, error) {
+	return purgeExpired[model.Link](ls.DB, before, ls.PurgeLink)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"go-blog/model"
+	"go-blog/pkg/throttle"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 登录失败记录默认保留天数
+const loginAttemptRetention = 30 * 24 * time.Hour
+
+type LoginAttemptListReq struct {
+	Page     int
+	PageSize int
+	Username string
+	IP       string
+}
+
+type ILoginGuardService interface {
+	Check(username, ip string) (time.Duration, error)
+	RecordFailure(username, ip, userAgent, reason string) (time.Duration, error)
+	RecordSuccess(username string) error
+	GetLoginAttempts(req *LoginAttemptListReq) ([]model.LoginAttempt, int64, error)
+	PurgeLoginAttempts() (int64, error)
+}
+
+// LoginGuardService 登录防爆破：按用户名与客户端 IP 分别计数，失败后指数退避并临时锁定
+type LoginGuardService struct {
+	DB      *gorm.DB
+	Tracker throttle.Tracker
+}
+
+func NewLoginGuardService(db *gorm.DB) *LoginGuardService {
+	return &LoginGuardService{DB: db, Tracker: throttle.Default}
+}
+
+var _ ILoginGuardService = (*LoginGuardService)(nil)
+
+// Check 返回需等待的时长，0 表示允许登录
+func (gs *LoginGuardService) Check(username, ip string) (time.Duration, error) {
+	var wait time.Duration
+	for _, key := range loginKeys(username, ip) {
+		d, err := gs.Tracker.Blocked(key)
+		if err != nil {
+			return 0, err
+		}
+		wait = max(wait, d)
+	}
+	return wait, nil
+}
+
+// RecordFailure 记录一次登录失败并写入审计，返回此后需等待的时长
+func (gs *LoginGuardService) RecordFailure(username, ip, userAgent, reason string) (time.Duration, error) {
+	attempt := &model.LoginAttempt{
+		Username:  truncate(username, 50),
+		IP:        ip,
+		UserAgent: truncate(userAgent, 255),
+		Reason:    truncate(reason, 100),
+	}
+	if err := gs.DB.Create(attempt).Error; err != nil {
+		return 0, err
+	}
+
+	var wait time.Duration
+	for _, key := range loginKeys(username, ip) {
+		d, err := gs.Tracker.Fail(key)
+		if err != nil {
+			return 0, err
+		}
+		wait = max(wait, d)
+	}
+	return wait, nil
+}
+
+// RecordSuccess 登录成功后清除该用户名的失败计数
+// (不清除 IP 计数，避免攻击者用自己的账号登录来重置计数)
+func (gs *LoginGuardService) RecordSuccess(username string) error {
+	return gs.Tracker.Reset(userKey(username))
+}
+
+// GetLoginAttempts 获取登录失败记录 (分页)
+func (gs *LoginGuardService) GetLoginAttempts(req *LoginAttemptListReq) ([]model.LoginAttempt, int64, error) {
+	list := make([]model.LoginAttempt, 0)
+	var total int64
+
+	db := gs.DB.Model(&model.LoginAttempt{})
+	if req.Username != "" {
+		db = db.Where("username = ?", req.Username)
+	}
+	if req.IP != "" {
+		db = db.Where("ip = ?", req.IP)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := db.Offset(offset).Limit(req.PageSize).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// PurgeLoginAttempts 清理过期的登录失败记录
+func (gs *LoginGuardService) PurgeLoginAttempts() (int64, error) {
+	result := gs.DB.Where("created_at < ?", time.Now().Add(-loginAttemptRetention)).Delete(&model.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+func userKey(username string) string {
+	return "login:user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func loginKeys(username, ip string) []string {
+	return []string{userKey(username), "login:ip:" + ip}
+}
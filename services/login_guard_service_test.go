@@ -0,0 +1,94 @@
+package service
+
+import (
+	"go-blog/model"
+	"go-blog/pkg/throttle"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库，并返回使用独立计数器的防爆破服务
+func setupLoginGuardTestDB(policy throttle.Policy) (*gorm.DB, *LoginGuardService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.LoginAttempt{})
+
+	svc := NewLoginGuardService(db)
+	svc.Tracker = throttle.NewMemoryTracker(policy)
+	return db, svc
+}
+
+func TestLoginGuardService_Backoff(t *testing.T) {
+	policy := throttle.Policy{
+		FreeAttempts: 2,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		MaxFailures:  5,
+		LockDuration: 15 * time.Minute,
+		Window:       time.Hour,
+	}
+
+	// 指数退避与锁定
+	assert.Equal(t, time.Duration(0), policy.Backoff(2))
+	assert.Equal(t, time.Second, policy.Backoff(3))
+	assert.Equal(t, 2*time.Second, policy.Backoff(4))
+	assert.Equal(t, 15*time.Minute, policy.Backoff(5))
+
+	db, svc := setupLoginGuardTestDB(policy)
+
+	// Case 1: 未超出免退避次数
+	for i := 0; i < 2; i++ {
+		wait, err := svc.RecordFailure("admin", "1.1.1.1", "ua", "invalid credentials")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), wait)
+	}
+	wait, _ := svc.Check("admin", "1.1.1.1")
+	assert.Equal(t, time.Duration(0), wait)
+
+	// Case 2: 超出后开始退避
+	wait, _ = svc.RecordFailure("admin", "1.1.1.1", "ua", "invalid credentials")
+	assert.Equal(t, time.Second, wait)
+	wait, _ = svc.Check("admin", "1.1.1.1")
+	assert.Greater(t, wait, time.Duration(0))
+
+	// Case 3: 按用户名计数，换 IP 同样被限制 (用户名不区分大小写)
+	wait, _ = svc.Check("ADMIN", "2.2.2.2")
+	assert.Greater(t, wait, time.Duration(0))
+
+	// Case 4: 按 IP 计数，换用户名同样被限制
+	wait, _ = svc.Check("other", "1.1.1.1")
+	assert.Greater(t, wait, time.Duration(0))
+
+	// Case 5: 达到最大失败次数后锁定
+	svc.RecordFailure("admin", "3.3.3.3", "ua", "invalid credentials")
+	wait, _ = svc.RecordFailure("admin", "4.4.4.4", "ua", "invalid credentials")
+	assert.Equal(t, 15*time.Minute, wait)
+
+	// Case 6: 登录成功只清除用户名计数，不清除 IP 计数
+	assert.NoError(t, svc.RecordSuccess("admin"))
+	wait, _ = svc.Check("admin", "5.5.5.5")
+	assert.Equal(t, time.Duration(0), wait)
+	wait, _ = svc.Check("admin", "1.1.1.1")
+	assert.Greater(t, wait, time.Duration(0))
+
+	// 失败记录写入审计
+	list, total, err := svc.GetLoginAttempts(&LoginAttemptListReq{Page: 1, PageSize: 10, IP: "1.1.1.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, "invalid credentials", list[0].Reason)
+
+	// 清理过期记录
+	db.Model(&model.LoginAttempt{}).Where("ip = ?", "4.4.4.4").Update("created_at", time.Now().Add(-60*24*time.Hour))
+	n, err := svc.PurgeLoginAttempts()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
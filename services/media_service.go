@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"go-blog/model"
+	"go-blog/pkg/imaging"
+	"go-blog/pkg/storage"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxUploadSize  = 10 << 20 // 10MB
+	defaultThumbnailWidth = 400
+	defaultMaxPixels      = 40_000_000 // 4000 万像素
+)
+
+// allowedMimeTypes 允许上传的图片类型 -> 文件扩展名
+var allowedMimeTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type IMediaService interface {
+	UploadMedia(fileName string, r io.Reader, uploaderID string) (*model.Media, error)
+	GetMediaList(page, pageSize int) ([]model.Media, int64, error)
+	DeleteMedia(id string) error
+}
+
+type MediaService struct {
+	DB             *gorm.DB
+	Storage        storage.Storage
+	MaxSize        int64 // 单个文件最大字节数
+	ThumbnailWidth int   // 缩略图宽度
+	MaxPixels      int64 // 单张图片最大像素数 (宽 x 高)，防止解压炸弹
+}
+
+func NewMediaService(db *gorm.DB) *MediaService {
+	return &MediaService{
+		DB:             db,
+		Storage:        storage.Default,
+		MaxSize:        defaultMaxUploadSize,
+		ThumbnailWidth: defaultThumbnailWidth,
+		MaxPixels:      defaultMaxPixels,
+	}
+}
+
+var _ IMediaService = (*MediaService)(nil)
+
+// UploadMedia 上传图片，同时生成缩略图与 WebP 版本
+func (ms *MediaService) UploadMedia(fileName string, r io.Reader, uploaderID string) (*model.Media, error) {
+	// 1. 读取并校验大小
+	data, err := io.ReadAll(io.LimitReader(r, ms.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > ms.MaxSize {
+		return nil, errors.New("file too large")
+	}
+
+	// 2. 根据文件内容嗅探类型，不信任客户端传入的扩展名
+	mimeType := http.DetectContentType(data)
+	ext, ok := allowedMimeTypes[mimeType]
+	if !ok {
+		return nil, errors.New("unsupported file type: " + mimeType)
+	}
+	// 解码前先读取尺寸，拒绝体积小但像素极多的图片
+	cfg, _, err := imaging.DecodeConfig(data)
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > ms.MaxPixels {
+		return nil, errors.New("image dimensions too large")
+	}
+	img, format, err := imaging.Decode(data)
+	if err != nil {
+		return nil, errors.New("invalid image")
+	}
+
+	// 3. 生成存储路径：年/月/uuid
+	base := path.Join(time.Now().Format("2006/01"), uuid.NewString())
+	media := &model.Media{
+		FileName:   path.Base(fileName),
+		MimeType:   mimeType,
+		Size:       int64(len(data)),
+		Width:      img.Bounds().Dx(),
+		Height:     img.Bounds().Dy(),
+		Key:        base + ext,
+		UploaderID: uploaderID,
+	}
+
+	// 上传失败时清理已写入的文件
+	uploaded := make([]string, 0, 3)
+	put := func(key string, data []byte, contentType string) error {
+		if err := ms.Storage.Put(key, data, contentType); err != nil {
+			return err
+		}
+		uploaded = append(uploaded, key)
+		return nil
+	}
+	cleanup := func() {
+		for _, key := range uploaded {
+			_ = ms.Storage.Delete(key)
+		}
+	}
+
+	// 4. 原图
+	if err := put(media.Key, data, mimeType); err != nil {
+		return nil, err
+	}
+	media.URL = ms.Storage.URL(media.Key)
+
+	// 5. 缩略图 (gif 缩略图输出为 png，只保留首帧)
+	thumbFormat, thumbExt, thumbMime := format, ext, mimeType
+	if format == "gif" {
+		thumbFormat, thumbExt, thumbMime = "png", ".png", "image/png"
+	}
+	thumb, err := imaging.Encode(imaging.Resize(img, ms.ThumbnailWidth), thumbFormat)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	media.ThumbnailKey = base + "_thumb" + thumbExt
+	if err := put(media.ThumbnailKey, thumb, thumbMime); err != nil {
+		cleanup()
+		return nil, err
+	}
+	media.ThumbnailURL = ms.Storage.URL(media.ThumbnailKey)
+
+	// 6. WebP 版本 (原图已是 WebP 时直接复用)
+	if format == "webp" {
+		media.WebPKey, media.WebPURL = media.Key, media.URL
+	} else {
+		webp, err := imaging.EncodeWebP(img)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		media.WebPKey = base + ".webp"
+		if err := put(media.WebPKey, webp, "image/webp"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		media.WebPURL = ms.Storage.URL(media.WebPKey)
+	}
+
+	if err := ms.DB.Create(media).Error; err != nil {
+		cleanup()
+		return nil, err
+	}
+	return media, nil
+}
+
+// GetMediaList 获取媒体列表 (分页)
+func (ms *MediaService) GetMediaList(page, pageSize int) ([]model.Media, int64, error) {
+	list := make([]model.Media, 0)
+	var total int64
+
+	db := ms.DB.Model(&model.Media{})
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// DeleteMedia 删除媒体文件 (仍被文章引用时拒绝删除)
+func (ms *MediaService) DeleteMedia(id string) error {
+	var media model.Media
+	if err := ms.DB.First(&media, "id = ?", id).Error; err != nil {
+		return errors.New("media not found")
+	}
+
+	// 检查文章封面与正文中是否引用了原图 / 缩略图 / WebP (包括回收站中的文章，恢复后仍需可用)
+	urls := []string{media.URL}
+	keys := []string{media.Key}
+	if media.ThumbnailKey != "" {
+		urls = append(urls, media.ThumbnailURL)
+		keys = append(keys, media.ThumbnailKey)
+	}
+	if media.WebPKey != "" && media.WebPKey != media.Key {
+		urls = append(urls, media.WebPURL)
+		keys = append(keys, media.WebPKey)
+	}
+
+	refQuery := ms.DB.Where("cover in ?", urls)
+	for _, u := range urls {
+		refQuery = refQuery.Or("content like ?", "%"+u+"%")
+	}
+	var count int64
+	if err := ms.DB.Unscoped().Model(&model.Post{}).Where(refQuery).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("media is still referenced by posts")
+	}
+
+	if err := ms.DB.Delete(&model.Media{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := ms.Storage.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
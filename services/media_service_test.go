@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-blog/model"
+	"go-blog/pkg/storage"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupMediaTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Media{})
+	return db
+}
+
+// 生成一张指定尺寸的 PNG 图片
+func makePNG(width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestMediaService_Upload(t *testing.T) {
+	db := setupMediaTestDB()
+	dir := t.TempDir()
+	svc := NewMediaService(db)
+	svc.Storage = storage.NewLocalStorage(dir, "/uploads")
+	svc.ThumbnailWidth = 100
+
+	// Case 1: 正常上传
+	media, err := svc.UploadMedia("../photo.png", bytes.NewReader(makePNG(300, 150)), "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "photo.png", media.FileName)
+	assert.Equal(t, "image/png", media.MimeType)
+	assert.Equal(t, 300, media.Width)
+	assert.True(t, strings.HasPrefix(media.URL, "/uploads/"))
+	assert.True(t, strings.HasSuffix(media.WebPURL, ".webp"))
+
+	// 验证原图、缩略图、WebP 均已写入
+	for _, key := range []string{media.Key, media.ThumbnailKey, media.WebPKey} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key)))
+		assert.NoError(t, err)
+	}
+	thumb, _ := os.Open(filepath.Join(dir, filepath.FromSlash(media.ThumbnailKey)))
+	cfg, _, err := image.DecodeConfig(thumb)
+	thumb.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, 100, cfg.Width)
+	assert.Equal(t, 50, cfg.Height)
+
+	// Case 2: 伪装成图片的文本文件
+	_, err = svc.UploadMedia("evil.png", strings.NewReader("<html><script>alert(1)</script></html>"), "admin")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported file type")
+
+	// Case 3: 像素数超出限制 (解码前拒绝)
+	svc.MaxPixels = 300*150 - 1
+	_, err = svc.UploadMedia("huge.png", bytes.NewReader(makePNG(300, 150)), "admin")
+	assert.Error(t, err)
+	assert.Equal(t, "image dimensions too large", err.Error())
+
+	// Case 4: 超出大小限制
+	svc.MaxSize = 100
+	_, err = svc.UploadMedia("big.png", bytes.NewReader(makePNG(300, 150)), "admin")
+	assert.Error(t, err)
+	assert.Equal(t, "file too large", err.Error())
+
+	list, total, err := svc.GetMediaList(1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, list, 1)
+}
+
+func TestMediaService_Delete(t *testing.T) {
+	db := setupMediaTestDB()
+	dir := t.TempDir()
+	svc := NewMediaService(db)
+	svc.Storage = storage.NewLocalStorage(dir, "/uploads")
+
+	media, err := svc.UploadMedia("a.png", bytes.NewReader(makePNG(50, 50)), "admin")
+	assert.NoError(t, err)
+
+	// Case 1: 被文章正文引用时拒绝删除
+	post := &model.Post{Title: "P", Content: "![img](" + media.WebPURL + ")", Slug: "p"}
+	db.Create(post)
+	err = svc.DeleteMedia(media.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "media is still referenced by posts", err.Error())
+
+	// Case 2: 被文章封面引用时拒绝删除
+	db.Model(post).Updates(map[string]any{"content": "none", "cover": media.URL})
+	err = svc.DeleteMedia(media.ID)
+	assert.Error(t, err)
+
+	// Case 3: 引用的文章在回收站中时同样拒绝删除
+	db.Delete(post)
+	err = svc.DeleteMedia(media.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "media is still referenced by posts", err.Error())
+
+	// Case 4: 无引用后可删除，文件一并清理
+	db.Unscoped().Model(post).Update("cover", "")
+	err = svc.DeleteMedia(media.ID)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(media.Key)))
+	assert.True(t, os.IsNotExist(err))
+
+	// Case 5: 不存在的媒体
+	err = svc.DeleteMedia(media.ID)
+	assert.Error(t, err)
+}
+
+// fakeS3 模拟 MinIO 的最小实现：校验签名头与负载哈希，并在内存中保存对象
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestMediaService_S3Storage(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "blog",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+		PathStyle: true,
+	})
+	assert.NoError(t, err)
+
+	db := setupMediaTestDB()
+	svc := NewMediaService(db)
+	svc.Storage = s3
+
+	media, err := svc.UploadMedia("a.png", bytes.NewReader(makePNG(50, 50)), "admin")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/blog/"+media.Key, media.URL)
+	assert.Len(t, fake.objects, 3)
+	assert.Contains(t, fake.objects, "/blog/"+media.Key)
+
+	err = svc.DeleteMedia(media.ID)
+	assert.NoError(t, err)
+	assert.Len(t, fake.objects, 0)
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"go-blog/migrations"
+	"go-blog/model"
+	"go-blog/pkg/migrate"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化文件数据库 (迁移会使用多个连接，不能使用内存数据库)
+func setupMigrationTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "blog.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	return db
+}
+
+func TestMigrate_UpDownStatus(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	m := migrate.New(db, migrations.All())
+
+	// Case 1: 空数据库执行全部迁移，表结构与当前模型一致 (包括友情链接表)
+	n, err := m.Pending()
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations.All()), n)
+	done, err := m.Up()
+	assert.NoError(t, err)
+	assert.Len(t, done, n)
+	models := []any{
+		&model.User{}, &model.Session{}, &model.LoginAttempt{}, &model.RecoveryCode{}, &model.UserIdentity{},
+		&model.OAuthState{}, &model.APIToken{}, &model.AuditLog{}, &model.Category{}, &model.Tag{},
+		&model.Post{}, &model.PostRevision{}, &model.SiteConfig{}, &model.Comment{}, &model.Media{}, &model.Link{},
+	}
+	for _, mdl := range models {
+		stmt := &gorm.Statement{DB: db}
+		assert.NoError(t, stmt.Parse(mdl))
+		assert.True(t, db.Migrator().HasTable(mdl), stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(mdl, field.DBName), stmt.Schema.Table+"."+field.DBName)
+			}
+		}
+	}
+	assert.True(t, db.Migrator().HasTable("post_tags"))
+	assert.NoError(t, db.Create(&model.Link{Name: "Go", URL: "https://go.dev"}).Error)
+
+	// Case 2: 重复执行不会再次迁移
+	done, err = m.Up()
+	assert.NoError(t, err)
+	assert.Len(t, done, 0)
+	list, err := m.Status()
+	assert.NoError(t, err)
+	for _, s := range list {
+		assert.Equal(t, migrate.StateApplied, s.State)
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	// Case 3: 已执行的迁移被修改时拒绝继续执行
+	first := list[0].Version
+	db.Table("schema_migrations").Where("version = ?", first).Update("checksum", "modified")
+	_, err = m.Up()
+	assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+	list, _ = m.Status()
+	assert.Equal(t, migrate.StateChanged, list[0].State)
+	db.Table("schema_migrations").Where("version = ?", first).Update("checksum", migrations.All()[0].Checksum)
+
+	// Case 4: 数据库中存在当前程序未知的迁移
+	db.Table("schema_migrations").Create(map[string]any{"version": int64(99990101000000), "name": "future", "checksum": "x", "applied_at": time.Now()})
+	list, _ = m.Status()
+	assert.Equal(t, migrate.StateMissing, list[len(list)-1].State)
+	_, err = m.Down(1)
+	assert.ErrorContains(t, err, "not known")
+	db.Table("schema_migrations").Where("version = ?", int64(99990101000000)).Delete(nil)
+
+	// Case 5: 回滚全部迁移后删除所有表
+	done, err = m.Down(len(migrations.All()))
+	assert.NoError(t, err)
+	assert.Len(t, done, len(migrations.All()))
+	assert.False(t, db.Migrator().HasTable(&model.Post{}))
+	assert.False(t, db.Migrator().HasTable("post_tags"))
+	n, _ = m.Pending()
+	assert.Equal(t, len(migrations.All()), n)
+}
+
+func TestMigrate_ExistingDatabase(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	// 引入迁移前由 AutoMigrate 创建的数据库 (缺少友情链接表)
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Comment{})
+	db.Create(&model.Category{Name: "Go", Slug: "go"})
+
+	// Case 1: 基线迁移只补充缺少的表，保留已有数据
+	_, err := migrate.New(db, migrations.All()).Up()
+	assert.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable(&model.Link{}))
+	var count int64
+	db.Model(&model.Category{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrate_Lock(t *testing.T) {
+	db := setupMigrationTestDB(t)
+	m := migrate.New(db, migrations.All())
+	m.LockTimeout = 0
+	_, err := m.Status()
+	assert.NoError(t, err)
+
+	// Case 1: 其他实例持有锁时等待超时后返回错误
+	db.Table("schema_migrations_lock").Create(map[string]any{"id": 1, "owner": "other", "locked_at": time.Now()})
+	_, err = m.Up()
+	assert.ErrorIs(t, err, migrate.ErrLocked)
+	n, _ := m.Pending()
+	assert.Equal(t, len(migrations.All()), n)
+
+	// Case 2: 超过有效期的锁视为持有者已崩溃，可以接管；执行完成后释放锁
+	db.Table("schema_migrations_lock").Where("id = ?", 1).Update("locked_at", time.Now().Add(-time.Hour))
+	_, err = m.Up()
+	assert.NoError(t, err)
+	var locks int64
+	db.Table("schema_migrations_lock").Count(&locks)
+	assert.Equal(t, int64(0), locks)
+}
+
+func TestMigrate_Create(t *testing.T) {
+	dir := t.TempDir()
+
+	// Case 1: 根据名称与时间生成迁移文件
+	path, err := migrations.Create(dir, "Add link sort!", time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20261018123000_add_link_sort.go"), path)
+	src, _ := os.ReadFile(path)
+	assert.Contains(t, string(src), "register(upAddLinkSort, downAddLinkSort)")
+
+	// Case 2: 非法名称或文件已存在
+	_, err = migrations.Create(dir, "!!!", time.Now())
+	assert.Error(t, err)
+	_, err = migrations.Create(dir, "add_link_sort", time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC))
+	assert.Error(t, err)
+}
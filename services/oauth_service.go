@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/oauth"
+	"go-blog/pkg/rbac"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// 授权请求有效期
+	oauthStateTTL = 10 * time.Minute
+	// 换取令牌与获取身份的总超时
+	oauthExchangeTimeout = 30 * time.Second
+)
+
+// 自动创建用户时用户名允许的字符
+var usernameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// OAuthProviderInfo 可用的第三方登录提供方
+type OAuthProviderInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// OAuthStart 发起授权返回的授权地址与 state
+type OAuthStart struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"-"`
+}
+
+type IOAuthService interface {
+	GetProviders() []OAuthProviderInfo
+	StartLogin(provider, callbackURL, redirect, linkUserID string) (*OAuthStart, error)
+	Callback(provider, state, code string) (*model.User, string, error)
+	GetIdentities(userID string) ([]model.UserIdentity, error)
+	UnlinkIdentity(userID, id string) error
+	PurgeExpiredStates() (int64, error)
+}
+
+// OAuthService 第三方登录 (授权码 + PKCE)
+type OAuthService struct {
+	DB        *gorm.DB
+	Providers map[string]*oauth.Provider
+}
+
+func NewOAuthService(db *gorm.DB) *OAuthService {
+	return &OAuthService{DB: db, Providers: oauth.Default}
+}
+
+var _ IOAuthService = (*OAuthService)(nil)
+
+// GetProviders 获取已配置的提供方 (按名称排序)
+func (oa *OAuthService) GetProviders() []OAuthProviderInfo {
+	list := make([]OAuthProviderInfo, 0, len(oa.Providers))
+	for _, p := range oa.Providers {
+		list = append(list, OAuthProviderInfo{Name: p.Name, Type: p.Type})
+	}
+	slices.SortFunc(list, func(a, b OAuthProviderInfo) int { return strings.Compare(a.Name, b.Name) })
+	return list
+}
+
+// StartLogin 生成 state、PKCE verifier 与 nonce 并保存，返回提供方授权地址；linkUserID 不为空时为该用户绑定身份
+func (oa *OAuthService) StartLogin(provider, callbackURL, redirect, linkUserID string) (*OAuthStart, error) {
+	p, ok := oa.Providers[provider]
+	if !ok {
+		return nil, errors.New("unknown provider")
+	}
+	if !safeRedirect(redirect) {
+		return nil, errors.New("redirect must be a relative path")
+	}
+	if p.RedirectURL != "" {
+		callbackURL = p.RedirectURL
+	}
+
+	state, err := oauth.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oauth.RandomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oauth.RandomString()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oauthExchangeTimeout)
+	defer cancel()
+	authURL, err := p.AuthCodeURL(ctx, callbackURL, state, verifier, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	err = oa.DB.Create(&model.OAuthState{
+		StateHash:    hashToken(state),
+		Provider:     p.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		CallbackURL:  callbackURL,
+		Redirect:     redirect,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthStart{AuthURL: authURL, State: state}, nil
+}
+
+// Callback 校验 state，使用授权码换取令牌并获取身份，返回对应的用户与登录后跳转地址
+func (oa *OAuthService) Callback(provider, state, code string) (*model.User, string, error) {
+	p, ok := oa.Providers[provider]
+	if !ok {
+		return nil, "", errors.New("unknown provider")
+	}
+
+	var st model.OAuthState
+	if err := oa.DB.First(&st, "state_hash = ?", hashToken(state)).Error; err != nil {
+		return nil, "", errors.New("invalid or expired state")
+	}
+	// state 只能使用一次 (以删除成功为准，防止并发重放)
+	result := oa.DB.Delete(&model.OAuthState{}, "id = ?", st.ID)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 || st.Provider != p.Name || time.Now().After(st.ExpiresAt) {
+		return nil, st.Redirect, errors.New("invalid or expired state")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oauthExchangeTimeout)
+	defer cancel()
+	token, err := p.Exchange(ctx, st.CallbackURL, code, st.CodeVerifier)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	identity, err := p.Identity(ctx, token, st.Nonce)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+
+	user, err := oa.resolveUser(p, identity, st.LinkUserID)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	if user.Disabled {
+		return nil, st.Redirect, errors.New("account disabled")
+	}
+	return user, st.Redirect, nil
+}
+
+// resolveUser 查找身份绑定的用户：绑定流程中新增绑定；未绑定时按配置自动创建用户
+func (oa *OAuthService) resolveUser(p *oauth.Provider, identity *oauth.Identity, linkUserID string) (*model.User, error) {
+	var ident model.UserIdentity
+	err := oa.DB.First(&ident, "provider = ? and subject = ?", p.Name, identity.Subject).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	found := err == nil
+	now := time.Now()
+
+	if found {
+		if linkUserID != "" && ident.UserID != linkUserID {
+			return nil, errors.New("identity already linked to another user")
+		}
+		err := oa.DB.Model(&ident).Updates(map[string]any{
+			"username":      truncate(identity.Username, 100),
+			"email":         truncate(identity.Email, 100),
+			"last_login_at": now,
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+		return findUser(oa.DB, ident.UserID)
+	}
+
+	newIdentity := &model.UserIdentity{
+		Provider:    p.Name,
+		Subject:     identity.Subject,
+		Username:    truncate(identity.Username, 100),
+		Email:       truncate(identity.Email, 100),
+		LastLoginAt: &now,
+	}
+
+	// 为已登录用户绑定 (每个提供方只能绑定一个身份)
+	if linkUserID != "" {
+		user, err := findUser(oa.DB, linkUserID)
+		if err != nil {
+			return nil, err
+		}
+		var count int64
+		if err := oa.DB.Model(&model.UserIdentity{}).Where("user_id = ? and provider = ?", user.ID, p.Name).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, errors.New("provider already linked")
+		}
+		newIdentity.UserID = user.ID
+		return user, oa.DB.Create(newIdentity).Error
+	}
+
+	if !p.AutoCreate {
+		return nil, errors.New("no account linked to this identity")
+	}
+
+	// 自动创建用户：随机密码 (只能通过第三方登录，或由管理员重置密码)
+	role := p.DefaultRole
+	if role == "" {
+		role = rbac.RoleAuthor
+	}
+	if !rbac.ValidRole(role) {
+		return nil, errors.New("invalid default role")
+	}
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Password:    string(hash),
+		Role:        role,
+		DisplayName: truncate(identity.Name, 50),
+		Avatar:      truncate(identity.Avatar, 255),
+	}
+	// 仅使用已验证的邮箱
+	if identity.EmailVerified {
+		user.Email = truncate(identity.Email, 100)
+	}
+	err = oa.DB.Transaction(func(tx *gorm.DB) error {
+		username, err := uniqueUsername(tx, p.Name, identity)
+		if err != nil {
+			return err
+		}
+		user.Username = username
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		newIdentity.UserID = user.ID
+		return tx.Create(newIdentity).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetIdentities 获取用户绑定的第三方身份
+func (oa *OAuthService) GetIdentities(userID string) ([]model.UserIdentity, error) {
+	list := make([]model.UserIdentity, 0)
+	err := oa.DB.Where("user_id = ?", userID).Order("created_at").Find(&list).Error
+	return list, err
+}
+
+// UnlinkIdentity 解除绑定
+func (oa *OAuthService) UnlinkIdentity(userID, id string) error {
+	result := oa.DB.Delete(&model.UserIdentity{}, "id = ? and user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("identity not found")
+	}
+	return nil
+}
+
+// PurgeExpiredStates 清理过期的授权请求
+func (oa *OAuthService) PurgeExpiredStates() (int64, error) {
+	result := oa.DB.Where("expires_at < ?", time.Now()).Delete(&model.OAuthState{})
+	return result.RowsAffected, result.Error
+}
+
+// uniqueUsername 根据第三方用户名 / 邮箱生成不重复的用户名
+func uniqueUsername(tx *gorm.DB, provider string, identity *oauth.Identity) (string, error) {
+	base := identity.Username
+	if base == "" && identity.Email != "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = truncate(usernameUnsafe.ReplaceAllString(base, ""), 40)
+	if len(base) < 3 {
+		base = provider + "_" + truncate(identity.Subject, 20)
+		base = truncate(usernameUnsafe.ReplaceAllString(base, ""), 40)
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		var count int64
+		if err := tx.Model(&model.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", errors.New("cannot generate unique username")
+}
+
+// safeRedirect 只允许站内相对路径，防止开放重定向
+func safeRedirect(redirect string) bool {
+	if redirect == "" {
+		return true
+	}
+	return strings.HasPrefix(redirect, "/") &&
+		!strings.HasPrefix(redirect, "//") &&
+		!strings.HasPrefix(redirect, "/\\")
+}
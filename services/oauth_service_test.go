@@ -0,0 +1,259 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"go-blog/model"
+	"go-blog/pkg/oauth"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockOIDC 本地模拟的 OIDC 提供方
+type mockOIDC struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+// mockGrant 用户在授权页同意后生成的授权码信息
+type mockGrant struct {
+	Challenge   string
+	RedirectURI string
+	Nonce       string
+	Subject     string
+	Username    string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	m := &mockOIDC{key: key, codes: map[string]mockGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		m.mu.Lock()
+		grant, ok := m.codes[r.Form.Get("code")]
+		delete(m.codes, r.Form.Get("code"))
+		m.mu.Unlock()
+
+		// 校验客户端、回调地址与 PKCE
+		if !ok || r.Form.Get("client_id") != "blog" || r.Form.Get("client_secret") != "s3cret" ||
+			r.Form.Get("redirect_uri") != grant.RedirectURI ||
+			oauth.CodeChallenge(r.Form.Get("code_verifier")) != grant.Challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                m.URL,
+			"aud":                "blog",
+			"sub":                grant.Subject,
+			"iat":                now.Unix(),
+			"exp":                now.Add(time.Minute).Unix(),
+			"nonce":              grant.Nonce,
+			"email":              grant.Username + "@example.com",
+			"email_verified":     true,
+			"preferred_username": grant.Username,
+		})
+		token.Header["kid"] = "k1"
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-" + grant.Subject,
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize 模拟用户在授权页同意，返回 state 与授权码
+func (m *mockOIDC) authorize(t *testing.T, authURL, subject, username string) (string, string) {
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	q := u.Query()
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	code, _ := oauth.RandomString()
+	m.mu.Lock()
+	m.codes[code] = mockGrant{
+		Challenge:   q.Get("code_challenge"),
+		RedirectURI: q.Get("redirect_uri"),
+		Nonce:       q.Get("nonce"),
+		Subject:     subject,
+		Username:    username,
+	}
+	m.mu.Unlock()
+	return q.Get("state"), code
+}
+
+// 初始化内存数据库与连接到模拟提供方的 OAuthService
+func setupOAuthTestDB(t *testing.T) (*OAuthService, *mockOIDC, *model.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.UserIdentity{}, &model.OAuthState{})
+
+	user := &model.User{Username: "alice", Password: "x", Role: "editor"}
+	db.Create(user)
+
+	mock := newMockOIDC(t)
+	manual, err := oauth.NewProvider(oauth.Config{Name: "sso", Type: oauth.TypeOIDC, Issuer: mock.URL, ClientID: "blog", ClientSecret: "s3cret"})
+	assert.NoError(t, err)
+	auto, err := oauth.NewProvider(oauth.Config{Name: "auto", Type: oauth.TypeOIDC, Issuer: mock.URL, ClientID: "blog", ClientSecret: "s3cret", AutoCreate: true})
+	assert.NoError(t, err)
+
+	svc := &OAuthService{DB: db, Providers: map[string]*oauth.Provider{"sso": manual, "auto": auto}}
+	return svc, mock, user
+}
+
+const testCallbackURL = "http://blog.test/api/auth/oauth/sso/callback"
+
+func TestOAuthService_LinkAndLogin(t *testing.T) {
+	svc, mock, alice := setupOAuthTestDB(t)
+
+	// Case 1: 只允许站内相对路径作为跳转地址
+	_, err := svc.StartLogin("sso", testCallbackURL, "//evil.com", "")
+	assert.Error(t, err)
+	_, err = svc.StartLogin("unknown", testCallbackURL, "", "")
+	assert.Error(t, err)
+
+	// Case 2: 身份未绑定且未开启自动创建时拒绝登录
+	start, err := svc.StartLogin("sso", testCallbackURL, "/admin", "")
+	assert.NoError(t, err)
+	state, code := mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	_, redirect, err := svc.Callback("sso", state, code)
+	assert.ErrorContains(t, err, "no account linked")
+	assert.Equal(t, "/admin", redirect)
+
+	// Case 3: 已登录用户绑定身份
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", alice.ID)
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	user, _, err := svc.Callback("sso", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+	identities, _ := svc.GetIdentities(alice.ID)
+	assert.Len(t, identities, 1)
+	assert.Equal(t, "sub-1", identities[0].Subject)
+
+	// Case 4: 绑定后可通过第三方登录，state 只能使用一次
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	user, _, err = svc.Callback("sso", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+	_, _, err = svc.Callback("sso", state, code)
+	assert.ErrorContains(t, err, "state")
+
+	// Case 5: PKCE verifier 不匹配时换取令牌失败
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	mock.mu.Lock()
+	grant := mock.codes[code]
+	grant.Challenge = oauth.CodeChallenge("attacker")
+	mock.codes[code] = grant
+	mock.mu.Unlock()
+	_, _, err = svc.Callback("sso", state, code)
+	assert.Error(t, err)
+
+	// Case 6: id_token 的 nonce 不匹配时拒绝
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	mock.mu.Lock()
+	grant = mock.codes[code]
+	grant.Nonce = "other"
+	mock.codes[code] = grant
+	mock.mu.Unlock()
+	_, _, err = svc.Callback("sso", state, code)
+	assert.ErrorContains(t, err, "nonce")
+
+	// Case 7: 同一身份不能绑定到其他用户
+	bob := &model.User{Username: "bob", Password: "x", Role: "author"}
+	svc.DB.Create(bob)
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", bob.ID)
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	_, _, err = svc.Callback("sso", state, code)
+	assert.ErrorContains(t, err, "another user")
+
+	// Case 8: 解除绑定后无法再通过该身份登录
+	assert.NoError(t, svc.UnlinkIdentity(alice.ID, identities[0].ID))
+	start, _ = svc.StartLogin("sso", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-1", "alice-sso")
+	_, _, err = svc.Callback("sso", state, code)
+	assert.Error(t, err)
+}
+
+func TestOAuthService_AutoCreate(t *testing.T) {
+	svc, mock, _ := setupOAuthTestDB(t)
+
+	// Case 1: 自动创建用户，用户名冲突时追加序号，使用已验证邮箱与默认角色
+	start, err := svc.StartLogin("auto", testCallbackURL, "", "")
+	assert.NoError(t, err)
+	state, code := mock.authorize(t, start.AuthURL, "sub-2", "alice")
+	user, _, err := svc.Callback("auto", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice-2", user.Username)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, "author", user.Role)
+
+	// Case 2: 再次登录返回同一用户
+	start, _ = svc.StartLogin("auto", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-2", "alice")
+	again, _, err := svc.Callback("auto", state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+
+	// Case 3: 禁用的用户无法登录
+	svc.DB.Model(&model.User{}).Where("id = ?", user.ID).Update("disabled", true)
+	start, _ = svc.StartLogin("auto", testCallbackURL, "", "")
+	state, code = mock.authorize(t, start.AuthURL, "sub-2", "alice")
+	_, _, err = svc.Callback("auto", state, code)
+	assert.ErrorContains(t, err, "disabled")
+
+	// Case 4: 清理过期的授权请求
+	svc.StartLogin("auto", testCallbackURL, "", "")
+	svc.DB.Model(&model.OAuthState{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute))
+	n, err := svc.PurgeExpiredStates()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
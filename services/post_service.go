@@ -3,10 +3,19 @@ package service
 import (
 	"errors"
 	"go-blog/model"
+	"go-blog/pkg/database"
+	"go-blog/pkg/markdown"
+	"go-blog/pkg/search"
+	"slices"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// 每篇文章默认保留的修订数
+const defaultMaxRevisions = 20
+
 type PostListReq struct {
 	Page        int
 	PageSize    int
@@ -14,30 +23,52 @@ type PostListReq struct {
 	TagID       string
 	KeyWord     string
 	IsPublished *bool // 指针允许传递 nil (不筛选)
+	Status      string
+	AuthorID    string // 只返回该作者的文章
+	// 后台列表：包含定时发布中的文章，公开接口不设置时始终隐藏发布时间未到的文章
+	IncludeScheduled bool
 }
 
 type IPostService interface {
 	CreatePost(post *model.Post, tagIDs []string) error
-	UpdatePost(post *model.Post, tagIDs []string) error
+	UpdatePost(post *model.Post, tagIDs []string, editorID string, columns ...string) error
 	DeletePost(id string) error
+	GetTrash() ([]model.Post, error)
+	GetTrashedPost(id string) (*model.Post, error)
+	RestorePost(id string) error
+	PurgePost(id string) error
+	PurgeTrash(before time.Time) (int64, error)
 	GetPostByID(id string) (*model.Post, error)
 	GetPostBySlug(slug string) (*model.Post, error)
 	GetPostList(req *PostListReq) ([]model.Post, int64, error)
 	IncrementView(id string) error
+	PublishDuePosts() (int64, error)
 }
 
 type PostService struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	Searcher     search.Searcher // 为 nil 时关键词搜索退化为 LIKE 查询
+	MaxRevisions int             // 每篇文章保留的修订数，<= 0 时不限制
 }
 
 func NewPostService(db *gorm.DB) *PostService {
-	return &PostService{DB: db}
+	return &PostService{DB: db, Searcher: search.Default, MaxRevisions: defaultMaxRevisions}
 }
 
 var _ IPostService = (*PostService)(nil)
 
 // CreatePost 创建文章
 func (ps *PostService) CreatePost(post *model.Post, tagIDs []string) error {
+	if err := normalizePostStatus(post, time.Now()); err != nil {
+		return err
+	}
+	if err := renderContent(post); err != nil {
+		return err
+	}
+	if err := checkTrashConflict(ps.DB, &model.Post{}, "slug", post.Slug); err != nil {
+		return err
+	}
+
 	return ps.DB.Transaction(func(tx *gorm.DB) error {
 		// 1. 先创建文章 (忽略关联，避免 GORM 自动处理带来的不可控问题)
 		if err := tx.Omit("Tags").Create(post).Error; err != nil {
@@ -61,14 +92,36 @@ func (ps *PostService) CreatePost(post *model.Post, tagIDs []string) error {
 			}
 		}
 
-		return nil
+		return ps.indexPost(post)
 	})
 }
 
-// UpdatePost 更新文章
-func (ps *PostService) UpdatePost(post *model.Post, tagIDs []string) error {
+// UpdatePost 更新文章，并将修改前的内容保存为修订记录
+// 默认只更新非零值字段；指定 columns 时按列更新，空字符串同样会写入
+func (ps *PostService) UpdatePost(post *model.Post, tagIDs []string, editorID string, columns ...string) error {
+	if post.Status != "" || post.IsPublished != nil {
+		if err := normalizePostStatus(post, time.Now()); err != nil {
+			return err
+		}
+	}
+	if post.Content != "" || slices.Contains(columns, "content") {
+		if err := renderContent(post); err != nil {
+			return err
+		}
+	}
+	if err := checkTrashConflict(ps.DB, &model.Post{}, "slug", post.Slug); err != nil {
+		return err
+	}
+
 	return ps.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(post).Updates(post).Error; err != nil {
+		if err := ps.saveRevision(tx, post, tagIDs, editorID); err != nil {
+			return err
+		}
+		update := tx.Model(post)
+		if len(columns) > 0 {
+			update = update.Select(columns)
+		}
+		if err := update.Updates(post).Error; err != nil {
 			return err
 		}
 
@@ -80,17 +133,142 @@ func (ps *PostService) UpdatePost(post *model.Post, tagIDs []string) error {
 					return err
 				}
 			}
-			if err := tx.Model(post).Association("Tags").Replace(tags); err != nil {
+			// 同 CreatePost，跳过 Tag 的 BeforeCreate 避免生成新 ID
+			if err := tx.Session(&gorm.Session{SkipHooks: true}).Model(post).Association("Tags").Replace(tags); err != nil {
 				return err
 			}
 		}
-		return nil
+		return ps.indexPost(post)
 	})
 }
 
-// DeletePost 删除文章
+// normalizePostStatus 根据 Status / IsPublished / PublishAt 推导一致的发布状态
+func normalizePostStatus(post *model.Post, now time.Time) error {
+	// 未指定状态时沿用 IsPublished 语义
+	if post.Status == "" {
+		post.Status = model.PostStatusPublished
+		if post.IsPublished != nil && !*post.IsPublished {
+			post.Status = model.PostStatusDraft
+		}
+	}
+
+	switch post.Status {
+	case model.PostStatusScheduled:
+		if post.PublishAt == nil {
+			return errors.New("publish_at is required for scheduled posts")
+		}
+		if !post.PublishAt.After(now) {
+			post.Status = model.PostStatusPublished
+		}
+	case model.PostStatusPublished:
+		if post.PublishAt == nil {
+			post.PublishAt = &now
+		} else if post.PublishAt.After(now) {
+			// 发布时间在未来，转为定时发布
+			post.Status = model.PostStatusScheduled
+		}
+	case model.PostStatusDraft, model.PostStatusArchived:
+	default:
+		return errors.New("invalid post status")
+	}
+
+	isPublished := post.Status == model.PostStatusPublished
+	post.IsPublished = &isPublished
+	return nil
+}
+
+// renderContent 渲染 Markdown 正文并缓存 HTML
+func renderContent(post *model.Post) error {
+	html, err := markdown.Render(post.Content)
+	if err != nil {
+		return err
+	}
+	post.ContentHTML = html
+	return nil
+}
+
+// indexPost 更新搜索索引
+func (ps *PostService) indexPost(post *model.Post) error {
+	if ps.Searcher == nil {
+		return nil
+	}
+	return ps.Searcher.Index(PostDocument(post))
+}
+
+// PostDocument 将文章转换为搜索文档 (正文优先使用渲染后的纯文本)
+func PostDocument(post *model.Post) search.Document {
+	content := post.Content
+	if post.ContentHTML != "" {
+		content = markdown.PlainText(post.ContentHTML)
+	}
+	return search.Document{ID: post.ID, Title: post.Title, Content: content}
+}
+
+// DeletePost 删除文章 (移入回收站，保留评论与修订历史以便恢复)
 func (ps *PostService) DeletePost(id string) error {
-	return ps.DB.Delete(&model.Post{}, "id = ?", id).Error
+	return ps.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Post{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if ps.Searcher != nil {
+			return ps.Searcher.Remove(id)
+		}
+		return nil
+	})
+}
+
+// GetTrash 获取回收站中的文章 (不含正文)
+func (ps *PostService) GetTrash() ([]model.Post, error) {
+	return listTrash[model.Post](ps.DB.Preload("Author").Omit("Content", "ContentHTML"))
+}
+
+// GetTrashedPost 获取回收站中的文章
+func (ps *PostService) GetTrashedPost(id string) (*model.Post, error) {
+	return findTrashed[model.Post](ps.DB, id)
+}
+
+// RestorePost 从回收站恢复文章 (所属分类已删除时需先恢复分类)
+func (ps *PostService) RestorePost(id string) error {
+	return ps.DB.Transaction(func(tx *gorm.DB) error {
+		post, err := findTrashed[model.Post](tx, id)
+		if err != nil {
+			return err
+		}
+		if post.CategoryID != "" {
+			if err := tx.Select("id").First(&model.Category{}, "id = ?", post.CategoryID).Error; err != nil {
+				return errors.New("category is in trash, restore it first")
+			}
+		}
+		if err := restoreTrashed[model.Post](tx, id); err != nil {
+			return err
+		}
+		return ps.indexPost(post)
+	})
+}
+
+// PurgePost 彻底删除回收站中的文章及其评论、修订历史与标签关联
+func (ps *PostService) PurgePost(id string) error {
+	return ps.DB.Transaction(func(tx *gorm.DB) error {
+		post, err := findTrashed[model.Post](tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Comment{}, "post_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.PostRevision{}, "post_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(post).Error
+	})
+}
+
+// PurgeTrash 彻底删除删除时间早于 before 的文章
+func (ps *PostService) PurgeTrash(before time.Time) (int64, error) {
+	return purgeExpired[model.Post](ps.DB, before, ps.PurgePost)
 }
 
 // GetPostByID 根据 ID 获取文章
@@ -107,10 +285,19 @@ func (ps *PostService) GetPostByID(id string) (*model.Post, error) {
 // GetPostBySlug 根据 Slug 获取文章 (SEO)
 func (ps *PostService) GetPostBySlug(slug string) (*model.Post, error) {
 	var post model.Post
-	err := ps.DB.Preload("Category").Preload("Author").Preload("Tags").First(&post, "slug = ?", slug).Error
+	// 隐藏尚未到发布时间的文章 (公开接口，优先读取只读副本)
+	err := database.Reader(ps.DB).Preload("Category").Preload("Author").Preload("Tags").
+		Where("status <> ? and (publish_at is null or publish_at <= ?)", model.PostStatusScheduled, time.Now()).
+		First(&post, "slug = ?", slug).Error
 	if err != nil {
 		return nil, err
 	}
+	// 兼容尚未生成 HTML 缓存的历史文章
+	if post.ContentHTML == "" && post.Content != "" {
+		if err := renderContent(&post); err != nil {
+			return nil, err
+		}
+	}
 	return &post, nil
 }
 
@@ -119,7 +306,11 @@ func (ps *PostService) GetPostList(req *PostListReq) ([]model.Post, int64, error
 	posts := make([]model.Post, 0)
 	var total int64
 
-	db := ps.DB.Model(&model.Post{})
+	// 公开接口优先读取只读副本，后台列表读主库避免复制延迟
+	db := database.Reader(ps.DB).Model(&model.Post{})
+	if req.IncludeScheduled {
+		db = ps.DB.Model(&model.Post{})
+	}
 
 	// 1. 动态构建查询条件
 	if req.CategoryID != "" {
@@ -128,9 +319,35 @@ func (ps *PostService) GetPostList(req *PostListReq) ([]model.Post, int64, error
 	if req.IsPublished != nil {
 		db = db.Where("is_published = ?", req.IsPublished)
 	}
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+	if req.AuthorID != "" {
+		db = db.Where("author_id = ?", req.AuthorID)
+	}
+	if !req.IncludeScheduled {
+		db = db.Where("status <> ? and (publish_at is null or publish_at <= ?)", model.PostStatusScheduled, time.Now())
+	}
+
+	// 关键词搜索：优先使用搜索引擎，结果按相关度排序
+	var rank map[string]int
 	if req.KeyWord != "" {
-		// 模糊搜索标题或内容
-		db = db.Where("title like ? or content like ?", "%"+req.KeyWord+"%", "%"+req.KeyWord+"%")
+		if ps.Searcher != nil {
+			hits, err := ps.Searcher.Search(req.KeyWord, 0)
+			if err != nil {
+				return nil, 0, err
+			}
+			rank = make(map[string]int, len(hits))
+			ids := make([]string, 0, len(hits))
+			for i, h := range hits {
+				rank[h.ID] = i
+				ids = append(ids, h.ID)
+			}
+			db = db.Where("id in ?", ids)
+		} else {
+			// 模糊搜索标题或内容
+			db = db.Where("title like ? or content like ?", "%"+req.KeyWord+"%", "%"+req.KeyWord+"%")
+		}
 	}
 
 	// 2. 标签筛选 (需要联表)
@@ -147,15 +364,67 @@ func (ps *PostService) GetPostList(req *PostListReq) ([]model.Post, int64, error
 	// 4. 分页与排序
 	offset := (req.Page - 1) * req.PageSize
 
-	// Omit("Content")：列表页通常无需加载长文本，提升性能
-	err := db.Offset(offset).Limit(req.PageSize).Order("created_at DESC").Preload("Category").Preload("Author").Preload("Tags").Omit("Content").Find(&posts).Error
+	if rank != nil {
+		return ps.getRankedPage(db, rank, offset, req.PageSize, total)
+	}
+
+	// Omit("Content", "ContentHTML")：列表页通常无需加载长文本，提升性能
+	err := db.Offset(offset).Limit(req.PageSize).Order("created_at DESC").Preload("Category").Preload("Author").Preload("Tags").Omit("Content", "ContentHTML").Find(&posts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// getRankedPage 按搜索相关度分页
+func (ps *PostService) getRankedPage(db *gorm.DB, rank map[string]int, offset, limit int, total int64) ([]model.Post, int64, error) {
+	posts := make([]model.Post, 0)
+
+	// 取出满足筛选条件的全部 ID，按相关度排序后再分页
+	var ids []string
+	if err := db.Pluck("id", &ids).Error; err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return rank[ids[i]] < rank[ids[j]] })
+	if offset >= len(ids) {
+		return posts, total, nil
+	}
+	ids = ids[offset:min(offset+limit, len(ids))]
+
+	err := ps.DB.Where("id in ?", ids).Preload("Category").Preload("Author").Preload("Tags").Omit("Content", "ContentHTML").Find(&posts).Error
 	if err != nil {
 		return nil, 0, err
 	}
+	sort.Slice(posts, func(i, j int) bool { return rank[posts[i].ID] < rank[posts[j].ID] })
 
 	return posts, total, nil
 }
 
+// PublishDuePosts 发布已到发布时间的定时文章，返回发布数量
+func (ps *PostService) PublishDuePosts() (int64, error) {
+	result := ps.DB.Model(&model.Post{}).
+		Where("status = ? and publish_at <= ?", model.PostStatusScheduled, time.Now()).
+		Updates(map[string]any{
+			"status":       model.PostStatusPublished,
+			"is_published": true,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// SyncLegacyStatus 兼容旧数据：根据 is_published 回填 status 与 publish_at
+func (ps *PostService) SyncLegacyStatus() error {
+	err := ps.DB.Model(&model.Post{}).
+		Where("is_published = ? and status = ?", false, model.PostStatusPublished).
+		UpdateColumn("status", model.PostStatusDraft).Error
+	if err != nil {
+		return err
+	}
+	return ps.DB.Model(&model.Post{}).
+		Where("status = ? and publish_at is null", model.PostStatusPublished).
+		UpdateColumn("publish_at", gorm.Expr("created_at")).Error
+}
+
 // IncrementView 增加浏览量
 func (ps *PostService) IncrementView(id string) error {
 	return ps.DB.Model(&model.Post{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("views + ?", 1)).Error
@@ -29,7 +29,7 @@ func setupPostTestDB() *gorm.DB {
 		panic("Failed to open sqlite db: " + err.Error())
 	}
 	// 迁移 Post 表
-	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{})
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Comment{}, &model.PostRevision{})
 	return db
 }
 
@@ -97,7 +97,7 @@ func TestPostService_Update(t *testing.T) {
 
 	// 修改标题，移出所有标签 (tags 传空数组)
 	post.Title = "New Title"
-	err := svc.UpdatePost(post, []string{})
+	err := svc.UpdatePost(post, []string{}, "")
 	assert.NoError(t, err)
 
 	// 验证
@@ -231,3 +231,130 @@ func TestPostService_IncrementView(t *testing.T) {
 	db.First(&p, "id = ?", post.ID)
 	assert.Equal(t, uint(11), *p.Views)
 }
+
+func TestPostService_ContentHTML(t *testing.T) {
+	db := setupPostTestDB()
+	svc := NewPostService(db)
+	catID, _ := prepareData(db)
+
+	content := "# Hello World\n\n" +
+		"| A | B |\n|---|---|\n| 1 | 2 |\n\n" +
+		"```go\nfunc main() {}\n```\n\n" +
+		"Note[^1]\n\n[^1]: footnote\n\n" +
+		"<script>alert(1)</script><a href=\"javascript:alert(1)\">x</a>\n"
+	post := &model.Post{Title: "MD", Content: content, Slug: "md", CategoryID: catID}
+	err := svc.CreatePost(post, nil)
+	assert.NoError(t, err)
+
+	// 验证渲染结果
+	p, err := svc.GetPostBySlug("md")
+	assert.NoError(t, err)
+	assert.Contains(t, p.ContentHTML, `<h1 id="hello-world">Hello World</h1>`)
+	assert.Contains(t, p.ContentHTML, "<table>")
+	assert.Contains(t, p.ContentHTML, `<pre class="chroma">`)
+	assert.Contains(t, p.ContentHTML, `class="footnotes"`)
+	// 验证 XSS 过滤
+	assert.NotContains(t, p.ContentHTML, "<script>")
+	assert.NotContains(t, p.ContentHTML, "javascript:")
+
+	// 更新正文后重新渲染
+	post.Content = "## Updated"
+	err = svc.UpdatePost(post, nil, "")
+	assert.NoError(t, err)
+	p, _ = svc.GetPostBySlug("md")
+	assert.Equal(t, "<h2 id=\"updated\">Updated</h2>\n", p.ContentHTML)
+
+	// 列表页不返回 HTML
+	list, _, _ := svc.GetPostList(&PostListReq{Page: 1, PageSize: 10})
+	assert.Empty(t, list[0].ContentHTML)
+}
+
+func TestPostService_ScheduledPublish(t *testing.T) {
+	db := setupPostTestDB()
+	svc := NewPostService(db)
+	catID, _ := prepareData(db)
+
+	future := time.Now().Add(time.Hour)
+	post := &model.Post{
+		Title:      "Scheduled",
+		Slug:       "scheduled",
+		CategoryID: catID,
+		Status:     model.PostStatusScheduled,
+		PublishAt:  &future,
+	}
+	err := svc.CreatePost(post, nil)
+	assert.NoError(t, err)
+	assert.False(t, *post.IsPublished)
+
+	// Case 1: 未到发布时间，详情与列表均不可见
+	_, err = svc.GetPostBySlug("scheduled")
+	assert.Error(t, err)
+	_, total, _ := svc.GetPostList(&PostListReq{Page: 1, PageSize: 10})
+	assert.Equal(t, int64(0), total)
+
+	// Case 2: 后台列表按状态筛选可见，公开列表指定状态时仍不可见
+	_, total, _ = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, Status: model.PostStatusScheduled, IncludeScheduled: true})
+	assert.Equal(t, int64(1), total)
+	_, total, _ = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, Status: model.PostStatusScheduled})
+	assert.Equal(t, int64(0), total)
+
+	// Case 3: 未到期时不会发布
+	n, err := svc.PublishDuePosts()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// Case 4: 到期后自动发布
+	db.Model(post).UpdateColumn("publish_at", time.Now().Add(-time.Minute))
+	n, err = svc.PublishDuePosts()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	p, err := svc.GetPostBySlug("scheduled")
+	assert.NoError(t, err)
+	assert.Equal(t, model.PostStatusPublished, p.Status)
+	assert.True(t, *p.IsPublished)
+
+	// Case 5: 定时发布缺少发布时间
+	err = svc.CreatePost(&model.Post{Title: "X", Slug: "x", CategoryID: catID, Status: model.PostStatusScheduled}, nil)
+	assert.Error(t, err)
+
+	// Case 6: 非法状态
+	err = svc.CreatePost(&model.Post{Title: "Y", Slug: "y", CategoryID: catID, Status: "unknown"}, nil)
+	assert.Error(t, err)
+}
+
+func TestPostService_ManagedList(t *testing.T) {
+	db := setupPostTestDB()
+	svc := NewPostService(db)
+	catID, _ := prepareData(db)
+
+	future := time.Now().Add(time.Hour)
+	posts := []*model.Post{
+		{Title: "A draft", Slug: "a-draft", CategoryID: catID, AuthorID: "author-a", Status: model.PostStatusDraft},
+		{Title: "A scheduled", Slug: "a-scheduled", CategoryID: catID, AuthorID: "author-a", Status: model.PostStatusScheduled, PublishAt: &future},
+		{Title: "B scheduled", Slug: "b-scheduled", CategoryID: catID, AuthorID: "author-b", Status: model.PostStatusScheduled, PublishAt: &future},
+		{Title: "B published", Slug: "b-published", CategoryID: catID, AuthorID: "author-b", Status: model.PostStatusPublished},
+	}
+	for _, p := range posts {
+		assert.NoError(t, svc.CreatePost(p, nil))
+	}
+
+	// Case 1: 公开列表 (status=published) 只返回已发布的文章
+	list, total, err := svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, Status: model.PostStatusPublished})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "b-published", list[0].Slug)
+
+	// Case 2: 后台列表包含定时发布的文章，可按状态筛选
+	_, total, err = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, IncludeScheduled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	_, total, _ = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, Status: model.PostStatusScheduled, IncludeScheduled: true})
+	assert.Equal(t, int64(2), total)
+
+	// Case 3: 限定作者时只返回该作者的文章
+	list, total, _ = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, Status: model.PostStatusScheduled, AuthorID: "author-a", IncludeScheduled: true})
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "a-scheduled", list[0].Slug)
+	_, total, _ = svc.GetPostList(&PostListReq{Page: 1, PageSize: 10, AuthorID: "author-a", IncludeScheduled: true})
+	assert.Equal(t, int64(2), total)
+}
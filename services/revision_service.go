@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"go-blog/model"
+	"go-blog/pkg/diff"
+	"slices"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// RevisionDiff 两个修订之间的差异 (版本号 0 表示文章当前内容)
+type RevisionDiff struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Title   []diff.Line `json:"title"`
+	Summary []diff.Line `json:"summary"`
+	Content []diff.Line `json:"content"`
+	Unified string      `json:"unified"` // 正文的 unified diff
+}
+
+type IRevisionService interface {
+	GetRevisions(postID string) ([]model.PostRevision, error)
+	GetRevision(postID string, version int) (*model.PostRevision, error)
+	DiffRevisions(postID string, from, to int) (*RevisionDiff, error)
+	RestoreRevision(postID string, version int, editorID string) error
+}
+
+type RevisionService struct {
+	DB          *gorm.DB
+	PostService IPostService
+}
+
+func NewRevisionService(db *gorm.DB) *RevisionService {
+	return &RevisionService{DB: db, PostService: NewPostService(db)}
+}
+
+var _ IRevisionService = (*RevisionService)(nil)
+
+// GetRevisions 获取文章修订列表 (不含正文，按版本倒序)
+func (rs *RevisionService) GetRevisions(postID string) ([]model.PostRevision, error) {
+	if err := rs.DB.First(&model.Post{}, "id = ?", postID).Error; err != nil {
+		return nil, errors.New("post not found")
+	}
+	list := make([]model.PostRevision, 0)
+	err := rs.DB.Omit("content").Where("post_id = ?", postID).Order("version DESC").Find(&list).Error
+	return list, err
+}
+
+// GetRevision 获取指定版本的修订
+func (rs *RevisionService) GetRevision(postID string, version int) (*model.PostRevision, error) {
+	var rev model.PostRevision
+	if err := rs.DB.First(&rev, "post_id = ? and version = ?", postID, version).Error; err != nil {
+		return nil, errors.New("revision not found")
+	}
+	return &rev, nil
+}
+
+// DiffRevisions 按行比较两个修订，版本号为 0 时与文章当前内容比较
+func (rs *RevisionService) DiffRevisions(postID string, from, to int) (*RevisionDiff, error) {
+	a, err := rs.snapshot(postID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := rs.snapshot(postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	content := diff.Lines(diff.SplitLines(a.Content), diff.SplitLines(b.Content))
+	return &RevisionDiff{
+		From:    from,
+		To:      to,
+		Title:   diff.Lines(diff.SplitLines(a.Title), diff.SplitLines(b.Title)),
+		Summary: diff.Lines(diff.SplitLines(a.Summary), diff.SplitLines(b.Summary)),
+		Content: content,
+		Unified: diff.Unified(revisionName(from), revisionName(to), content, 3),
+	}, nil
+}
+
+// RestoreRevision 将文章恢复到指定修订 (恢复前的内容同样会保存为新修订)
+func (rs *RevisionService) RestoreRevision(postID string, version int, editorID string) error {
+	rev, err := rs.GetRevision(postID, version)
+	if err != nil {
+		return err
+	}
+	post, err := rs.PostService.GetPostByID(postID)
+	if err != nil {
+		return errors.New("post not found")
+	}
+
+	post.Title = rev.Title
+	post.Content = rev.Content
+	post.Summary = rev.Summary
+	tagIDs := rev.TagIDs
+	if tagIDs == nil {
+		tagIDs = []string{}
+	}
+	// 显式指定列，修订中的空摘要 / 空正文也要写回
+	return rs.PostService.UpdatePost(post, tagIDs, editorID, "title", "content", "summary", "content_html", "updated_at")
+}
+
+// snapshot 获取指定版本的内容，版本号为 0 时返回文章当前内容
+func (rs *RevisionService) snapshot(postID string, version int) (*model.PostRevision, error) {
+	if version != 0 {
+		return rs.GetRevision(postID, version)
+	}
+	var post model.Post
+	if err := rs.DB.First(&post, "id = ?", postID).Error; err != nil {
+		return nil, errors.New("post not found")
+	}
+	return &model.PostRevision{Title: post.Title, Content: post.Content, Summary: post.Summary}, nil
+}
+
+func revisionName(version int) string {
+	if version == 0 {
+		return "current"
+	}
+	return "v" + strconv.Itoa(version)
+}
+
+// saveRevision 在更新事务中保存修改前的内容，并清理超出保留数量的旧修订
+func (ps *PostService) saveRevision(tx *gorm.DB, post *model.Post, tagIDs []string, editorID string) error {
+	var old model.Post
+	if err := tx.Preload("Tags").First(&old, "id = ?", post.ID).Error; err != nil {
+		return err
+	}
+	oldTagIDs := make([]string, 0, len(old.Tags))
+	for _, tag := range old.Tags {
+		oldTagIDs = append(oldTagIDs, tag.ID)
+	}
+
+	// 标题、正文、摘要与标签均未变化时 (如仅切换发布状态) 不记录
+	changed := (post.Title != "" && post.Title != old.Title) ||
+		(post.Content != "" && post.Content != old.Content) ||
+		(post.Summary != "" && post.Summary != old.Summary) ||
+		(tagIDs != nil && !sameIDs(tagIDs, oldTagIDs))
+	if !changed {
+		return nil
+	}
+
+	var version int
+	if err := tx.Model(&model.PostRevision{}).Where("post_id = ?", post.ID).
+		Select("coalesce(max(version), 0)").Scan(&version).Error; err != nil {
+		return err
+	}
+	rev := &model.PostRevision{
+		PostID:   post.ID,
+		Version:  version + 1,
+		Title:    old.Title,
+		Content:  old.Content,
+		Summary:  old.Summary,
+		TagIDs:   oldTagIDs,
+		EditorID: editorID,
+	}
+	if err := tx.Create(rev).Error; err != nil {
+		return err
+	}
+
+	if ps.MaxRevisions <= 0 {
+		return nil
+	}
+	var ids []string
+	if err := tx.Model(&model.PostRevision{}).Where("post_id = ?", post.ID).
+		Order("version DESC").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) <= ps.MaxRevisions {
+		return nil
+	}
+	return tx.Delete(&model.PostRevision{}, "id in ?", ids[ps.MaxRevisions:]).Error
+}
+
+// sameIDs 判断两组 ID 是否相同 (忽略顺序)
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"go-blog/model"
+	"go-blog/pkg/diff"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupRevisionTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Comment{}, &model.PostRevision{})
+	return db
+}
+
+func TestRevisionService_History(t *testing.T) {
+	db := setupRevisionTestDB()
+	postSvc := NewPostService(db)
+	postSvc.Searcher = nil
+	svc := NewRevisionService(db)
+	svc.PostService = postSvc
+	catID, tagID := prepareData(db)
+
+	post := &model.Post{Title: "V1", Content: "line1\nline2\nline3", Slug: "rev", CategoryID: catID}
+	assert.NoError(t, postSvc.CreatePost(post, []string{tagID}))
+
+	// Case 1: 更新正文生成修订，保存修改前的内容与操作者
+	post.Title = "V2"
+	post.Content = "line1\nline2 changed\nline3"
+	assert.NoError(t, postSvc.UpdatePost(post, []string{}, "editor-1"))
+	list, err := svc.GetRevisions(post.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, 1, list[0].Version)
+	assert.Equal(t, "V1", list[0].Title)
+	assert.Equal(t, "editor-1", list[0].EditorID)
+	assert.Equal(t, []string{tagID}, list[0].TagIDs)
+
+	// Case 2: 内容未变化 (如仅切换发布状态) 时不生成修订
+	isPub := false
+	post.IsPublished = &isPub
+	post.Status = ""
+	assert.NoError(t, postSvc.UpdatePost(post, nil, "editor-1"))
+	list, _ = svc.GetRevisions(post.ID)
+	assert.Len(t, list, 1)
+
+	// Case 3: 修订与当前内容的行级差异
+	d, err := svc.DiffRevisions(post.ID, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []diff.Line{
+		{Op: diff.Equal, Text: "line1"},
+		{Op: diff.Delete, Text: "line2"},
+		{Op: diff.Insert, Text: "line2 changed"},
+		{Op: diff.Equal, Text: "line3"},
+	}, d.Content)
+	assert.Contains(t, d.Unified, "--- v1\n+++ current\n@@ -1,3 +1,3 @@")
+
+	// Case 4: 恢复修订，恢复前的内容同样被记录
+	assert.NoError(t, svc.RestoreRevision(post.ID, 1, "editor-2"))
+	p, _ := postSvc.GetPostByID(post.ID)
+	assert.Equal(t, "V1", p.Title)
+	assert.Equal(t, "line1\nline2\nline3", p.Content)
+	assert.Len(t, p.Tags, 1)
+	list, _ = svc.GetRevisions(post.ID)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "V2", list[0].Title)
+
+	// Case 5: 修订中的空摘要同样会恢复
+	post.Summary = "new summary"
+	assert.NoError(t, postSvc.UpdatePost(post, nil, "editor-1"))
+	list, _ = svc.GetRevisions(post.ID)
+	assert.Empty(t, list[0].Summary)
+	assert.NoError(t, svc.RestoreRevision(post.ID, list[0].Version, "editor-2"))
+	p, _ = postSvc.GetPostByID(post.ID)
+	assert.Empty(t, p.Summary)
+	assert.Equal(t, "V1", p.Title)
+
+	// Case 6: 不存在的修订
+	assert.Error(t, svc.RestoreRevision(post.ID, 99, "editor-2"))
+	_, err = svc.DiffRevisions(post.ID, 99, 0)
+	assert.Error(t, err)
+}
+
+func TestRevisionService_Limit(t *testing.T) {
+	db := setupRevisionTestDB()
+	postSvc := NewPostService(db)
+	postSvc.Searcher = nil
+	postSvc.MaxRevisions = 3
+	svc := NewRevisionService(db)
+	svc.PostService = postSvc
+	catID, _ := prepareData(db)
+
+	post := &model.Post{Title: "T", Content: "0", Slug: "limit", CategoryID: catID}
+	postSvc.CreatePost(post, nil)
+	for _, content := range []string{"1", "2", "3", "4", "5"} {
+		post.Content = content
+		assert.NoError(t, postSvc.UpdatePost(post, nil, ""))
+	}
+
+	// 仅保留最近 3 条修订
+	list, err := svc.GetRevisions(post.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list, 3)
+	assert.Equal(t, 5, list[0].Version)
+	assert.Equal(t, 3, list[2].Version)
+
+	// 删除文章 (移入回收站) 时保留修订，彻底删除时一并删除
+	assert.NoError(t, postSvc.DeletePost(post.ID))
+	var count int64
+	db.Model(&model.PostRevision{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, postSvc.PurgePost(post.ID))
+	db.Model(&model.PostRevision{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/search"
+
+	"gorm.io/gorm"
+)
+
+// 搜索后端
+const (
+	SearchBackendMemory = "memory"
+	SearchBackendMySQL  = "mysql"
+)
+
+type SearchReq struct {
+	Query    string
+	Page     int
+	PageSize int
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Post    model.Post `json:"post"`
+	Score   float64    `json:"score"`
+	Title   string     `json:"title_highlight"` // 高亮后的标题 (HTML)
+	Snippet string     `json:"snippet"`         // 高亮后的正文摘要 (HTML)
+}
+
+type ISearchService interface {
+	Search(req *SearchReq) ([]SearchResult, int64, error)
+	RebuildIndex() error
+}
+
+type SearchService struct {
+	DB       *gorm.DB
+	Searcher search.Searcher
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{DB: db, Searcher: search.Default}
+}
+
+var _ ISearchService = (*SearchService)(nil)
+
+// InitSearch 根据配置初始化全局搜索引擎
+func InitSearch(db *gorm.DB, backend string) error {
+	if backend == SearchBackendMySQL {
+		if db.Dialector.Name() != "mysql" {
+			return fmt.Errorf("search backend %q requires the mysql database driver", backend)
+		}
+		searcher := search.NewMySQLSearcher(db, db.NamingStrategy.TableName("Post"))
+		if err := searcher.Init(); err != nil {
+			return err
+		}
+		search.Default = searcher
+		return nil
+	}
+
+	// 内存索引需在启动时全量构建
+	return NewSearchService(db).RebuildIndex()
+}
+
+// RebuildIndex 全量重建搜索索引
+func (ss *SearchService) RebuildIndex() error {
+	var posts []model.Post
+	return ss.DB.Select("id", "title", "content", "content_html").FindInBatches(&posts, 100, func(tx *gorm.DB, batch int) error {
+		for i := range posts {
+			if err := ss.Searcher.Index(PostDocument(&posts[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// Search 全文搜索已发布文章，按相关度排序
+func (ss *SearchService) Search(req *SearchReq) ([]SearchResult, int64, error) {
+	results := make([]SearchResult, 0)
+
+	hits, err := ss.Searcher.Search(req.Query, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(hits) == 0 {
+		return results, 0, nil
+	}
+
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+
+	// 过滤掉未发布的文章
+	var posts []model.Post
+	err = ss.DB.Where("id in ? and is_published = ?", ids, true).
+		Preload("Category").Preload("Author").Preload("Tags").Omit("Content", "ContentHTML").Find(&posts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	postMap := make(map[string]model.Post, len(posts))
+	for _, p := range posts {
+		postMap[p.ID] = p
+	}
+
+	for _, h := range hits {
+		if p, ok := postMap[h.ID]; ok {
+			results = append(results, SearchResult{Post: p, Score: h.Score, Title: h.Title, Snippet: h.Snippet})
+		}
+	}
+
+	total := int64(len(results))
+	offset := (req.Page - 1) * req.PageSize
+	if offset >= len(results) {
+		return make([]SearchResult, 0), total, nil
+	}
+	return results[offset:min(offset+req.PageSize, len(results))], total, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"go-blog/model"
+	"go-blog/pkg/search"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库，并返回使用独立索引的文章服务与搜索服务
+func setupSearchTestDB() (*gorm.DB, *PostService, *SearchService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{}, &model.Comment{}, &model.PostRevision{})
+
+	searcher := search.NewMemorySearcher()
+	postSvc := NewPostService(db)
+	postSvc.Searcher = searcher
+	searchSvc := NewSearchService(db)
+	searchSvc.Searcher = searcher
+	return db, postSvc, searchSvc
+}
+
+func TestSearchService_Search(t *testing.T) {
+	db, postSvc, svc := setupSearchTestDB()
+	catID, _ := prepareData(db)
+
+	postSvc.CreatePost(&model.Post{Title: "Go 并发编程", Content: "goroutine 与 channel 是 Go 语言并发的核心", Slug: "go", CategoryID: catID}, nil)
+	postSvc.CreatePost(&model.Post{Title: "全文搜索引擎", Content: "倒排索引是搜索引擎的基础，Go 也可以实现", Slug: "search", CategoryID: catID}, nil)
+	isPub := false
+	postSvc.CreatePost(&model.Post{Title: "搜索引擎草稿", Content: "草稿", Slug: "draft", CategoryID: catID, IsPublished: &isPub}, nil)
+
+	// Case 1: 中文检索 (bigram)，草稿不可见
+	list, total, err := svc.Search(&SearchReq{Query: "搜索引擎", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "search", list[0].Post.Slug)
+	assert.Equal(t, "全文<mark>搜索引擎</mark>", list[0].Title)
+	assert.Contains(t, list[0].Snippet, "<mark>搜索引擎</mark>")
+
+	// Case 2: 相关度排序 (标题命中优先)
+	list, total, err = svc.Search(&SearchReq{Query: "go", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "go", list[0].Post.Slug)
+
+	// Case 3: 分页
+	list, _, err = svc.Search(&SearchReq{Query: "go", Page: 2, PageSize: 1})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "search", list[0].Post.Slug)
+
+	// Case 4: 无结果
+	list, total, err = svc.Search(&SearchReq{Query: "rust", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Len(t, list, 0)
+}
+
+func TestSearchService_IndexLifecycle(t *testing.T) {
+	db, postSvc, svc := setupSearchTestDB()
+	catID, _ := prepareData(db)
+
+	post := &model.Post{Title: "Docker 入门", Content: "容器", Slug: "docker", CategoryID: catID}
+	postSvc.CreatePost(post, nil)
+
+	// 更新后旧内容不再命中
+	post.Title = "Kubernetes 入门"
+	assert.NoError(t, postSvc.UpdatePost(post, nil, ""))
+	_, total, _ := svc.Search(&SearchReq{Query: "docker", Page: 1, PageSize: 10})
+	assert.Equal(t, int64(0), total)
+	_, total, _ = svc.Search(&SearchReq{Query: "kubernetes", Page: 1, PageSize: 10})
+	assert.Equal(t, int64(1), total)
+
+	// GetPostList 关键词搜索同样走搜索引擎
+	list, total, err := postSvc.GetPostList(&PostListReq{Page: 1, PageSize: 10, KeyWord: "入门"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "docker", list[0].Slug)
+
+	// 删除后不再命中
+	assert.NoError(t, postSvc.DeletePost(post.ID))
+	_, total, _ = svc.Search(&SearchReq{Query: "kubernetes", Page: 1, PageSize: 10})
+	assert.Equal(t, int64(0), total)
+
+	// 重建索引
+	postSvc.Searcher = nil
+	postSvc.CreatePost(&model.Post{Title: "Rebuild", Content: "x", Slug: "rebuild", CategoryID: catID}, nil)
+	_, total, _ = svc.Search(&SearchReq{Query: "rebuild", Page: 1, PageSize: 10})
+	assert.Equal(t, int64(0), total)
+	assert.NoError(t, svc.RebuildIndex())
+	_, total, _ = svc.Search(&SearchReq{Query: "rebuild", Page: 1, PageSize: 10})
+	assert.Equal(t, int64(1), total)
+}
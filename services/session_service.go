@@ -0,0 +1,213 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go-blog/model"
+	jwtpkg "go-blog/pkg/jwt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrPasswordChangeRequired 会话有效，但用户必须先修改密码
+var ErrPasswordChangeRequired = errors.New("password change required")
+
+// TokenPair 登录 / 刷新返回的令牌对
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // 访问令牌有效期 (秒)
+}
+
+type ISessionService interface {
+	CreateSession(user *model.User, ip, userAgent string) (*TokenPair, error)
+	RefreshSession(refreshToken, ip, userAgent string) (*TokenPair, error)
+	RevokeSession(userID, sessionID string) error
+	RevokeAllSessions(userID string) error
+	ValidateToken(claims *jwtpkg.Claims) error
+	PurgeExpiredSessions() (int64, error)
+}
+
+type SessionService struct {
+	DB *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{DB: db}
+}
+
+var _ ISessionService = (*SessionService)(nil)
+
+// CreateSession 登录成功后创建会话，签发访问令牌与刷新令牌
+func (ss *SessionService) CreateSession(user *model.User, ip, userAgent string) (*TokenPair, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		UserID:     user.ID,
+		TokenHash:  hashToken(refreshToken),
+		IP:         ip,
+		UserAgent:  truncate(userAgent, 255),
+		ExpiresAt:  now.Add(jwtpkg.RefreshTokenTTL()),
+		LastUsedAt: now,
+	}
+	if err := ss.DB.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return issueTokens(user, session.ID, refreshToken)
+}
+
+// RefreshSession 使用刷新令牌换取新的令牌对 (刷新令牌每次使用后轮换)
+func (ss *SessionService) RefreshSession(refreshToken, ip, userAgent string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+	now := time.Now()
+
+	var session model.Session
+	if err := ss.DB.First(&session, "token_hash = ?", hash).Error; err != nil {
+		// 已轮换的旧令牌被再次使用，说明令牌可能已泄露，吊销整个会话
+		var reused model.Session
+		if ss.DB.First(&reused, "prev_token_hash = ?", hash).Error == nil {
+			_ = ss.DB.Model(&reused).Where("revoked_at is null").Update("revoked_at", now).Error
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, errors.New("session expired or revoked")
+	}
+
+	var user model.User
+	if err := ss.DB.First(&user, "id = ?", session.UserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Disabled {
+		return nil, errors.New("account disabled")
+	}
+
+	newToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	// 以旧哈希为条件更新，防止并发刷新重复轮换
+	result := ss.DB.Model(&model.Session{}).
+		Where("id = ? and token_hash = ?", session.ID, hash).
+		Updates(map[string]any{
+			"token_hash":      hashToken(newToken),
+			"prev_token_hash": hash,
+			"ip":              ip,
+			"user_agent":      truncate(userAgent, 255),
+			"expires_at":      now.Add(jwtpkg.RefreshTokenTTL()),
+			"last_used_at":    now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid refresh token")
+	}
+	return issueTokens(&user, session.ID, newToken)
+}
+
+// RevokeSession 吊销指定会话 (退出登录)
+func (ss *SessionService) RevokeSession(userID, sessionID string) error {
+	return ss.DB.Model(&model.Session{}).
+		Where("id = ? and user_id = ? and revoked_at is null", sessionID, userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllSessions 吊销用户的全部会话 (退出所有设备)
+func (ss *SessionService) RevokeAllSessions(userID string) error {
+	return revokeUserSessions(ss.DB, userID)
+}
+
+// ValidateToken 校验访问令牌对应的会话仍然有效，签发时间不早于最近一次修改密码，且角色未变更
+func (ss *SessionService) ValidateToken(claims *jwtpkg.Claims) error {
+	if claims.SessionID == "" {
+		return errors.New("session required")
+	}
+
+	var session model.Session
+	if err := ss.DB.First(&session, "id = ? and user_id = ?", claims.SessionID, claims.UserID).Error; err != nil {
+		return errors.New("session not found")
+	}
+	if session.RevokedAt != nil {
+		return errors.New("session revoked")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return errors.New("session expired")
+	}
+
+	var user model.User
+	if err := ss.DB.Select("id", "role", "disabled", "must_change_password", "password_changed_at").First(&user, "id = ?", claims.UserID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if user.Disabled {
+		return errors.New("account disabled")
+	}
+	// JWT 的 iat 精度为秒
+	if user.PasswordChangedAt != nil && claims.IssuedAt != nil &&
+		claims.IssuedAt.Time.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+		return errors.New("token issued before password change")
+	}
+	// 角色变更后需刷新令牌以获取新角色
+	if claims.Role != user.Role {
+		return errors.New("role changed")
+	}
+	if user.MustChangePassword {
+		return ErrPasswordChangeRequired
+	}
+	return nil
+}
+
+// PurgeExpiredSessions 清理已过期或已吊销的会话，返回清理数量
+func (ss *SessionService) PurgeExpiredSessions() (int64, error) {
+	result := ss.DB.Where("expires_at < ? or revoked_at is not null", time.Now()).Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
+
+// revokeUserSessions 吊销用户的全部会话 (可在事务中调用)
+func revokeUserSessions(db *gorm.DB, userID string) error {
+	return db.Model(&model.Session{}).
+		Where("user_id = ? and revoked_at is null", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func issueTokens(user *model.User, sessionID, refreshToken string) (*TokenPair, error) {
+	accessToken, err := jwtpkg.GenerateToken(user.ID, user.Username, user.Role, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(jwtpkg.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// newRefreshToken 生成 256 位随机刷新令牌
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 数据库中只保存令牌的 SHA-256
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
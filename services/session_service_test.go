@@ -0,0 +1,230 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"go-blog/model"
+	jwtpkg "go-blog/pkg/jwt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库与 JWT，并创建一个测试用户
+func setupSessionTestDB() (*gorm.DB, *model.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Session{})
+	jwtpkg.Init(&jwtpkg.Config{Algorithm: "HS256", Secret: "test-secret"})
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	user := &model.User{Username: "alice", Password: string(hashed), Role: "admin"}
+	db.Create(user)
+	return db, user
+}
+
+func TestSessionService_CreateAndRefresh(t *testing.T) {
+	db, user := setupSessionTestDB()
+	svc := NewSessionService(db)
+
+	pair, err := svc.CreateSession(user, "127.0.0.1", "test-agent")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15*60), pair.ExpiresIn)
+
+	// 数据库中只保存刷新令牌的哈希
+	var session model.Session
+	db.First(&session)
+	assert.Equal(t, hashToken(pair.RefreshToken), session.TokenHash)
+	assert.NotEqual(t, pair.RefreshToken, session.TokenHash)
+
+	// Case 1: 访问令牌绑定会话且有效
+	claims, err := jwtpkg.ParseToken(pair.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, session.ID, claims.SessionID)
+	assert.NoError(t, svc.ValidateToken(claims))
+
+	// Case 2: 刷新后轮换刷新令牌
+	next, err := svc.RefreshSession(pair.RefreshToken, "127.0.0.1", "test-agent")
+	assert.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, next.RefreshToken)
+
+	// Case 3: 旧刷新令牌重放，整个会话被吊销
+	_, err = svc.RefreshSession(pair.RefreshToken, "127.0.0.1", "test-agent")
+	assert.Error(t, err)
+	_, err = svc.RefreshSession(next.RefreshToken, "127.0.0.1", "test-agent")
+	assert.Error(t, err)
+	assert.Error(t, svc.ValidateToken(claims))
+
+	// Case 4: 无效的刷新令牌
+	_, err = svc.RefreshSession("not-a-token", "", "")
+	assert.Error(t, err)
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	db, user := setupSessionTestDB()
+	svc := NewSessionService(db)
+
+	a, _ := svc.CreateSession(user, "", "device-a")
+	b, _ := svc.CreateSession(user, "", "device-b")
+	claimsA, _ := jwtpkg.ParseToken(a.AccessToken)
+	claimsB, _ := jwtpkg.ParseToken(b.AccessToken)
+
+	// Case 1: 退出登录只影响当前会话
+	assert.NoError(t, svc.RevokeSession(user.ID, claimsA.SessionID))
+	assert.Error(t, svc.ValidateToken(claimsA))
+	assert.NoError(t, svc.ValidateToken(claimsB))
+	_, err := svc.RefreshSession(a.RefreshToken, "", "")
+	assert.Error(t, err)
+
+	// Case 2: 退出所有设备
+	assert.NoError(t, svc.RevokeAllSessions(user.ID))
+	assert.Error(t, svc.ValidateToken(claimsB))
+
+	// Case 3: 缺少会话的令牌 (旧版本签发) 被拒绝
+	token, _ := jwtpkg.GenerateToken(user.ID, user.Username, user.Role, "")
+	claims, _ := jwtpkg.ParseToken(token)
+	assert.Error(t, svc.ValidateToken(claims))
+
+	// Case 4: 角色变更后旧令牌失效，刷新后携带新角色
+	c, _ := svc.CreateSession(user, "", "device-c")
+	claimsC, _ := jwtpkg.ParseToken(c.AccessToken)
+	db.Model(user).Update("role", "author")
+	err = svc.ValidateToken(claimsC)
+	assert.Error(t, err)
+	assert.Equal(t, "role changed", err.Error())
+	c, err = svc.RefreshSession(c.RefreshToken, "", "")
+	assert.NoError(t, err)
+	claimsC, _ = jwtpkg.ParseToken(c.AccessToken)
+	assert.Equal(t, "author", claimsC.Role)
+	assert.NoError(t, svc.ValidateToken(claimsC))
+
+	// Case 5: 禁用账号后令牌与刷新令牌均失效
+	db.Model(user).Update("disabled", true)
+	err = svc.ValidateToken(claimsC)
+	assert.Error(t, err)
+	assert.Equal(t, "account disabled", err.Error())
+	_, err = svc.RefreshSession(c.RefreshToken, "", "")
+	assert.Error(t, err)
+
+	// Case 6: 清理已吊销的会话
+	n, err := svc.PurgeExpiredSessions()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestSessionService_PasswordChange(t *testing.T) {
+	db, user := setupSessionTestDB()
+	svc := NewSessionService(db)
+	userSvc := NewUserService(db)
+
+	pair, _ := svc.CreateSession(user, "", "")
+	claims, _ := jwtpkg.ParseToken(pair.AccessToken)
+
+	// Case 1: 修改密码后会话被吊销
+	assert.NoError(t, userSvc.ChangePassword(user.ID, "secret123", "newsecret"))
+	assert.Error(t, svc.ValidateToken(claims))
+	_, err := svc.RefreshSession(pair.RefreshToken, "", "")
+	assert.Error(t, err)
+
+	// Case 2: 管理员重置密码后，新会话仅能用于修改密码
+	assert.NoError(t, userSvc.ResetPassword(user.ID, "temp-secret"))
+	pair, _ = svc.CreateSession(user, "", "")
+	claims, _ = jwtpkg.ParseToken(pair.AccessToken)
+	assert.ErrorIs(t, svc.ValidateToken(claims), ErrPasswordChangeRequired)
+	assert.NoError(t, userSvc.ChangePassword(user.ID, "temp-secret", "final-secret"))
+
+	// Case 3: 签发时间早于修改密码时间的令牌被拒绝
+	pair, _ = svc.CreateSession(user, "", "")
+	claims, _ = jwtpkg.ParseToken(pair.AccessToken)
+	assert.NoError(t, svc.ValidateToken(claims))
+	db.Model(&model.User{}).Where("id = ?", user.ID).Update("password_changed_at", time.Now().Add(time.Minute))
+	err = svc.ValidateToken(claims)
+	assert.Error(t, err)
+	assert.Equal(t, "token issued before password change", err.Error())
+}
+
+func TestSessionService_SigningKeys(t *testing.T) {
+	db, user := setupSessionTestDB()
+	svc := NewSessionService(db)
+	dir := t.TempDir()
+
+	// 旧令牌使用 HS256 密钥 "old" 签发
+	assert.NoError(t, jwtpkg.Init(&jwtpkg.Config{Keys: []jwtpkg.KeyConfig{{ID: "old", Secret: "test-secret"}}}))
+	oldPair, err := svc.CreateSession(user, "127.0.0.1", "test-agent")
+	assert.NoError(t, err)
+
+	// Case 1: 新增 EdDSA 密钥后，新令牌使用新密钥签名，旧令牌仍可验证
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	keyPath := filepath.Join(dir, "ed25519.pem")
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	assert.NoError(t, jwtpkg.Init(&jwtpkg.Config{Keys: []jwtpkg.KeyConfig{
+		{ID: "old", Secret: "test-secret"},
+		{ID: "new", Algorithm: jwtpkg.EdDSA, PrivateKeyPath: keyPath},
+	}}))
+	newPair, err := svc.CreateSession(user, "127.0.0.1", "test-agent")
+	assert.NoError(t, err)
+	for _, token := range []string{oldPair.AccessToken, newPair.AccessToken} {
+		claims, err := jwtpkg.ParseToken(token)
+		assert.NoError(t, err)
+		assert.NoError(t, svc.ValidateToken(claims))
+	}
+
+	// Case 2: JWKS 只公开非对称公钥
+	set := jwtpkg.JWKS()
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, "new", set.Keys[0].Kid)
+	assert.Equal(t, "OKP", set.Keys[0].Kty)
+
+	// Case 3: 移除旧密钥后，旧令牌无法验证
+	assert.NoError(t, jwtpkg.Init(&jwtpkg.Config{Keys: []jwtpkg.KeyConfig{{ID: "new", Algorithm: jwtpkg.EdDSA, PrivateKeyPath: keyPath}}}))
+	_, err = jwtpkg.ParseToken(oldPair.AccessToken)
+	assert.Error(t, err)
+
+	// Case 4: 自动轮换，新令牌使用最新密钥，轮换前签发的令牌仍可验证
+	rotation := jwtpkg.RotationConfig{Dir: filepath.Join(dir, "rotation"), Algorithm: jwtpkg.ES256, Interval: time.Nanosecond}
+	assert.NoError(t, jwtpkg.Init(&jwtpkg.Config{Rotation: rotation}))
+	before, _ := jwtpkg.GenerateToken(user.ID, user.Username, user.Role, "sid")
+	rotated, err := jwtpkg.RotateIfDue()
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	after, _ := jwtpkg.GenerateToken(user.ID, user.Username, user.Role, "sid")
+	_, err = jwtpkg.ParseToken(before)
+	assert.NoError(t, err)
+	_, err = jwtpkg.ParseToken(after)
+	assert.NoError(t, err)
+
+	set = jwtpkg.JWKS()
+	assert.Len(t, set.Keys, 2)
+	assert.Equal(t, "EC", set.Keys[0].Kty)
+	assert.Equal(t, set.Keys[0].Kid, tokenKid(t, after))
+	assert.Len(t, set.Keys[0].X, 43) // P-256 坐标 32 字节
+
+}
+
+// tokenKid 读取令牌头部的 kid
+func tokenKid(t *testing.T, token string) string {
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	assert.NoError(t, err)
+	var h struct {
+		Kid string `json:"kid"`
+	}
+	assert.NoError(t, json.Unmarshal(header, &h))
+	return h.Kid
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-blog/model"
+	"go-blog/pkg/database"
+	"go-blog/pkg/sitemap"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ISitemapService interface {
+	GetSitemap(baseURL string, page int) ([]byte, error)
+	GetRobots(baseURL string, disallow []string) string
+}
+
+type SitemapService struct {
+	DB      *gorm.DB
+	MaxURLs int // 单个 sitemap 文件最多包含的 URL 数，超出后拆分为 sitemap index
+}
+
+func NewSitemapService(db *gorm.DB) *SitemapService {
+	return &SitemapService{DB: db, MaxURLs: sitemap.MaxURLs}
+}
+
+var _ ISitemapService = (*SitemapService)(nil)
+
+// SitemapPageURL 第 page 个子 sitemap 的地址
+func SitemapPageURL(baseURL string, page int) string {
+	return fmt.Sprintf("%s/sitemaps/%d.xml", strings.TrimRight(baseURL, "/"), page)
+}
+
+// GetSitemap 获取 sitemap
+// page 为 0 时返回入口文件：URL 数未超限时直接输出 urlset，否则输出 sitemap index；
+// page >= 1 时返回对应的子 sitemap
+func (ss *SitemapService) GetSitemap(baseURL string, page int) ([]byte, error) {
+	urls, lastMod, err := ss.collectURLs(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	size := ss.MaxURLs
+	if size <= 0 {
+		size = sitemap.MaxURLs
+	}
+	pages := (len(urls) + size - 1) / size
+
+	if page == 0 {
+		if pages <= 1 {
+			return sitemap.URLSet(urls)
+		}
+		locs := make([]string, 0, pages)
+		for i := 1; i <= pages; i++ {
+			locs = append(locs, SitemapPageURL(baseURL, i))
+		}
+		return sitemap.Index(locs, lastMod)
+	}
+
+	if page < 1 || page > pages {
+		return nil, errors.New("sitemap page not found")
+	}
+	end := min(page*size, len(urls))
+	return sitemap.URLSet(urls[(page-1)*size : end])
+}
+
+// collectURLs 收集首页、已发布文章、分类与标签地址，同时返回最近修改时间
+func (ss *SitemapService) collectURLs(baseURL string) ([]sitemap.URL, time.Time, error) {
+	db := database.Reader(ss.DB)
+	var posts []model.Post
+	if err := db.Model(&model.Post{}).Select("slug", "updated_at").
+		Where("is_published = ?", true).Order("updated_at DESC").Find(&posts).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+	var categories []model.Category
+	if err := db.Select("slug", "updated_at").Where("slug <> ''").Find(&categories).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+	var tags []model.Tag
+	if err := db.Select("slug", "updated_at").Where("slug <> ''").Find(&tags).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var lastMod time.Time
+	if len(posts) > 0 {
+		lastMod = posts[0].UpdatedAt
+	}
+
+	urls := make([]sitemap.URL, 0, 1+len(posts)+len(categories)+len(tags))
+	urls = append(urls, sitemap.NewURL(baseURL+"/", lastMod))
+	for _, p := range posts {
+		urls = append(urls, sitemap.NewURL(baseURL+"/posts/"+p.Slug, p.UpdatedAt))
+	}
+	for _, c := range categories {
+		urls = append(urls, sitemap.NewURL(baseURL+"/categories/"+c.Slug, c.UpdatedAt))
+	}
+	for _, t := range tags {
+		urls = append(urls, sitemap.NewURL(baseURL+"/tags/"+t.Slug, t.UpdatedAt))
+	}
+
+	return urls, lastMod, nil
+}
+
+// GetRobots 生成 robots.txt
+func (ss *SitemapService) GetRobots(baseURL string, disallow []string) string {
+	return sitemap.Robots(disallow, strings.TrimRight(baseURL, "/")+"/sitemap.xml")
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"go-blog/model"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库
+func setupSitemapTestDB() *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.Category{}, &model.Tag{}, &model.Post{})
+	return db
+}
+
+func TestSitemapService_GetSitemap(t *testing.T) {
+	db := setupSitemapTestDB()
+	svc := NewSitemapService(db)
+	postSvc := NewPostService(db)
+	catID, _ := prepareData(db)
+
+	postSvc.CreatePost(&model.Post{Title: "A", Content: "C", Slug: "a", CategoryID: catID}, nil)
+	isPub := false
+	postSvc.CreatePost(&model.Post{Title: "Draft", Content: "C", Slug: "draft", CategoryID: catID, IsPublished: &isPub}, nil)
+
+	// Case 1: 未超限时直接输出 urlset
+	body, err := svc.GetSitemap("https://example.com/", 0)
+	assert.NoError(t, err)
+	xml := string(body)
+	assert.Contains(t, xml, "<urlset")
+	assert.Contains(t, xml, "<loc>https://example.com/posts/a</loc>")
+	assert.Contains(t, xml, "<loc>https://example.com/categories/tech</loc>")
+	assert.Contains(t, xml, "<loc>https://example.com/tags/go</loc>")
+	assert.Contains(t, xml, "<lastmod>")
+	assert.NotContains(t, xml, "/posts/draft")
+
+	// Case 2: 超限后拆分为 sitemap index (首页 + 1 文章 + 1 分类 + 1 标签 = 4 条)
+	svc.MaxURLs = 3
+	body, err = svc.GetSitemap("https://example.com", 0)
+	assert.NoError(t, err)
+	xml = string(body)
+	assert.Contains(t, xml, "<sitemapindex")
+	assert.Contains(t, xml, "<loc>https://example.com/sitemaps/1.xml</loc>")
+	assert.Contains(t, xml, "<loc>https://example.com/sitemaps/2.xml</loc>")
+
+	body, err = svc.GetSitemap("https://example.com", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(body), "<url>"))
+
+	// Case 3: 页码越界
+	_, err = svc.GetSitemap("https://example.com", 3)
+	assert.Error(t, err)
+}
+
+func TestSitemapService_GetRobots(t *testing.T) {
+	svc := NewSitemapService(nil)
+
+	robots := svc.GetRobots("https://example.com/", []string{"/api/", "/admin"})
+	assert.Contains(t, robots, "User-agent: *\n")
+	assert.Contains(t, robots, "Disallow: /api/\n")
+	assert.Contains(t, robots, "Disallow: /admin\n")
+	assert.Contains(t, robots, "Sitemap: https://example.com/sitemap.xml\n")
+
+	// 无规则时允许全部抓取
+	robots = svc.GetRobots("https://example.com", nil)
+	assert.Contains(t, robots, "Disallow:\n")
+}
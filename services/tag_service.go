@@ -2,6 +2,8 @@ package service
 
 import (
 	"go-blog/model"
+	"go-blog/pkg/database"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +13,10 @@ type ITagService interface {
 	GetTagList() ([]model.Tag, error)
 	UpdateTag(id, name, slug string) error
 	DeleteTag(id string) error
+	GetTrash() ([]model.Tag, error)
+	RestoreTag(id string) error
+	PurgeTag(id string) error
+	PurgeTrash(before time.Time) (int64, error)
 }
 
 type TagService struct {
@@ -25,6 +31,9 @@ var _ ITagService = (*TagService)(nil)
 
 // CreateTag 创建标签
 func (ts *TagService) CreateTag(name, slug string) (*model.Tag, error) {
+	if err := ts.checkTrashConflict(name, slug); err != nil {
+		return nil, err
+	}
 	tag := &model.Tag{
 		Name: name,
 		Slug: slug,
@@ -39,7 +48,7 @@ func (ts *TagService) CreateTag(name, slug string) (*model.Tag, error) {
 func (ts *TagService) GetTagList() ([]model.Tag, error) {
 	tags := make([]model.Tag, 0)
 	// 按创建时间排序
-	if err := ts.DB.Order("created_at desc").Find(&tags).Error; err != nil {
+	if err := database.Reader(ts.DB).Order("created_at desc").Find(&tags).Error; err != nil {
 		return nil, err
 	}
 	return tags, nil
@@ -47,13 +56,52 @@ func (ts *TagService) GetTagList() ([]model.Tag, error) {
 
 // UpdateTag 更新标签
 func (ts *TagService) UpdateTag(id, name, slug string) error {
+	if err := ts.checkTrashConflict(name, slug); err != nil {
+		return err
+	}
 	return ts.DB.Model(&model.Tag{}).Where("id = ?", id).Updates(map[string]any{
 		"name": name,
 		"slug": slug,
 	}).Error
 }
 
-// DeleteTag 删除标签
+// DeleteTag 删除标签 (移入回收站，文章中不再显示该标签)
 func (ts *TagService) DeleteTag(id string) error {
 	return ts.DB.Delete(&model.Tag{}, "id = ?", id).Error
 }
+
+// GetTrash 获取回收站中的标签
+func (ts *TagService) GetTrash() ([]model.Tag, error) {
+	return listTrash[model.Tag](ts.DB)
+}
+
+// RestoreTag 从回收站恢复标签 (恢复与文章的关联)
+func (ts *TagService) RestoreTag(id string) error {
+	return restoreTrashed[model.Tag](ts.DB, id)
+}
+
+// PurgeTag 彻底删除回收站中的标签及其与文章的关联
+func (ts *TagService) PurgeTag(id string) error {
+	return ts.DB.Transaction(func(tx *gorm.DB) error {
+		tag, err := findTrashed[model.Tag](tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(tag).Error
+	})
+}
+
+// PurgeTrash 彻底删除删除时间早于 before 的标签
+func (ts *TagService) PurgeTrash(before time.Time) (int64, error) {
+	return purgeExpired[model.Tag](ts.DB, before, ts.PurgeTag)
+}
+
+func (ts *TagService) checkTrashConflict(name, slug string) error {
+	if err := checkTrashConflict(ts.DB, &model.Tag{}, "name", name); err != nil {
+		return err
+	}
+	return checkTrashConflict(ts.DB, &model.Tag{}, "slug", slug)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTrashConflict 唯一字段 (slug / name) 被回收站中的记录占用
+var ErrTrashConflict = errors.New("conflicts with an item in trash")
+
+// errNotInTrash 回收站中不存在该记录
+var errNotInTrash = errors.New("item not found in trash")
+
+// checkTrashConflict 唯一字段被回收站中的记录占用时返回明确的错误，需先恢复或彻底删除该记录
+func checkTrashConflict(db *gorm.DB, m any, column, value string) error {
+	if value == "" {
+		return nil
+	}
+	var count int64
+	err := db.Unscoped().Model(m).Where(column+" = ? and deleted_at is not null", value).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %s %q, restore or purge it first", ErrTrashConflict, column, value)
+	}
+	return nil
+}
+
+// listTrash 获取回收站中的记录 (按删除时间倒序)
+func listTrash[T any](db *gorm.DB) ([]T, error) {
+	list := make([]T, 0)
+	err := db.Unscoped().Where("deleted_at is not null").Order("deleted_at desc").Find(&list).Error
+	return list, err
+}
+
+// findTrashed 查找回收站中的记录
+func findTrashed[T any](db *gorm.DB, id string) (*T, error) {
+	var m T
+	if err := db.Unscoped().Where("deleted_at is not null").First(&m, "id = ?", id).Error; err != nil {
+		return nil, errNotInTrash
+	}
+	return &m, nil
+}
+
+// restoreTrashed 从回收站恢复记录
+func restoreTrashed[T any](db *gorm.DB, id string) error {
+	result := db.Unscoped().Model(new(T)).Where("id = ? and deleted_at is not null", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errNotInTrash
+	}
+	return nil
+}
+
+// expiredTrash 删除时间早于 before 的记录 ID
+func expiredTrash[T any](db *gorm.DB, before time.Time) ([]string, error) {
+	var ids []string
+	err := db.Unscoped().Model(new(T)).Where("deleted_at < ?", before).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// purgeExpired 逐个彻底删除过期的记录，跳过无法删除的记录 (如仍被文章引用的分类)
+func purgeExpired[T any](db *gorm.DB, before time.Time, purge func(id string) error) (int64, error) {
+	ids, err := expiredTrash[T](db, before)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	var errs []error
+	for _, id := range ids {
+		if err := purge(id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			continue
+		}
+		n++
+	}
+	return n, errors.Join(errs...)
+}
+
+// PurgeTrash 彻底删除回收站中删除时间早于 before 的文章、分类、标签与友链 (先删除文章，释放其引用的分类)
+func PurgeTrash(db *gorm.DB, before time.Time) (int64, error) {
+	var total int64
+	var errs []error
+	for _, purge := range []func(time.Time) (int64, error){
+		NewPostService(db).PurgeTrash,
+		NewCategoryService(db).PurgeTrash,
+		NewTagService(db).PurgeTrash,
+		NewLinkService(db).PurgeTrash,
+	} {
+		n, err := purge(before)
+		total += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
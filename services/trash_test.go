@@ -0,0 +1,111 @@
+package service
+
+import (
+	"go-blog/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash_TagAndCategory(t *testing.T) {
+	db := setupPostTestDB()
+	postSvc := NewPostService(db)
+	tagSvc := NewTagService(db)
+	catSvc := NewCategoryService(db)
+
+	catID, tagID := prepareData(db)
+	post := &model.Post{Title: "Hello", Content: "c", Slug: "hello", CategoryID: catID}
+	assert.NoError(t, postSvc.CreatePost(post, []string{tagID}))
+
+	// Case 1: 删除标签后移入回收站，文章中不再显示该标签
+	assert.NoError(t, tagSvc.DeleteTag(tagID))
+	tags, _ := tagSvc.GetTagList()
+	assert.Len(t, tags, 0)
+	trash, err := tagSvc.GetTrash()
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+	got, _ := postSvc.GetPostByID(post.ID)
+	assert.Len(t, got.Tags, 0)
+
+	// Case 2: 回收站中的标签占用 slug / name 时返回明确的冲突错误
+	_, err = tagSvc.CreateTag("Golang", "go")
+	assert.ErrorIs(t, err, ErrTrashConflict)
+	_, err = tagSvc.CreateTag("Go", "golang")
+	assert.ErrorIs(t, err, ErrTrashConflict)
+
+	// Case 3: 恢复标签后文章关联一并恢复
+	assert.NoError(t, tagSvc.RestoreTag(tagID))
+	assert.Error(t, tagSvc.RestoreTag(tagID))
+	got, _ = postSvc.GetPostByID(post.ID)
+	assert.Len(t, got.Tags, 1)
+
+	// Case 4: 彻底删除标签后 slug 可重新使用
+	assert.Error(t, tagSvc.PurgeTag(tagID), "未在回收站中的标签不能彻底删除")
+	assert.NoError(t, tagSvc.DeleteTag(tagID))
+	assert.NoError(t, tagSvc.PurgeTag(tagID))
+	var links int64
+	db.Table("post_tags").Where("tag_id = ?", tagID).Count(&links)
+	assert.Equal(t, int64(0), links)
+	_, err = tagSvc.CreateTag("Go", "go")
+	assert.NoError(t, err)
+
+	// Case 5: 分类仍被回收站中的文章引用时不能彻底删除，文章所属分类在回收站时不能恢复文章
+	assert.Error(t, catSvc.DeleteCategory(catID), "仍有文章时不能删除分类")
+	assert.NoError(t, postSvc.DeletePost(post.ID))
+	assert.NoError(t, catSvc.DeleteCategory(catID))
+	assert.Error(t, catSvc.PurgeCategory(catID))
+	assert.ErrorContains(t, postSvc.RestorePost(post.ID), "category")
+	assert.NoError(t, catSvc.RestoreCategory(catID))
+	assert.NoError(t, postSvc.RestorePost(post.ID))
+}
+
+func TestTrash_PostAndAutoPurge(t *testing.T) {
+	db := setupPostTestDB()
+	db.AutoMigrate(&model.Link{})
+	postSvc := NewPostService(db)
+	linkSvc := NewLinkService(db)
+
+	catID, _ := prepareData(db)
+	post := &model.Post{Title: "Hello", Content: "c", Slug: "hello", CategoryID: catID}
+	assert.NoError(t, postSvc.CreatePost(post, nil))
+	db.Create(&model.Comment{PostID: post.ID, AuthorName: "bob", AuthorEmail: "bob@example.com", Content: "hi"})
+
+	// Case 1: 删除文章后公开接口不可见，评论保留
+	assert.NoError(t, postSvc.DeletePost(post.ID))
+	_, err := postSvc.GetPostBySlug("hello")
+	assert.Error(t, err)
+	trash, err := postSvc.GetTrash()
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+	assert.Empty(t, trash[0].Content)
+	var comments int64
+	db.Model(&model.Comment{}).Where("post_id = ?", post.ID).Count(&comments)
+	assert.Equal(t, int64(1), comments)
+
+	// Case 2: slug 被回收站中的文章占用
+	err = postSvc.CreatePost(&model.Post{Title: "Again", Content: "c", Slug: "hello"}, nil)
+	assert.ErrorIs(t, err, ErrTrashConflict)
+
+	// Case 3: 恢复文章
+	assert.NoError(t, postSvc.RestorePost(post.ID))
+	_, err = postSvc.GetPostBySlug("hello")
+	assert.NoError(t, err)
+
+	// Case 4: 自动清理超过保留时长的回收站记录，未过期的保留
+	link := &model.Link{Name: "Go", URL: "https://go.dev"}
+	linkSvc.CreateLink(link)
+	assert.NoError(t, postSvc.DeletePost(post.ID))
+	assert.NoError(t, linkSvc.DeleteLink(link.ID))
+	db.Unscoped().Model(&model.Post{}).Where("id = ?", post.ID).Update("deleted_at", time.Now().Add(-31*24*time.Hour))
+
+	n, err := PurgeTrash(db, time.Now().Add(-30*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = postSvc.GetTrashedPost(post.ID)
+	assert.Error(t, err)
+	db.Model(&model.Comment{}).Where("post_id = ?", post.ID).Count(&comments)
+	assert.Equal(t, int64(0), comments)
+	links, _ := linkSvc.GetTrash()
+	assert.Len(t, links, 1)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"go-blog/model"
+	"go-blog/pkg/totp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 两步验证默认参数
+const (
+	defaultTOTPIssuer = "blog_go"
+	recoveryCodeCount = 10
+)
+
+// recoveryAlphabet 恢复码字符集 (去除易混淆的 0/1/i/l/o)
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// TwoFactorSetup 开启两步验证时返回的密钥信息
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 地址，可生成二维码供验证器扫描
+}
+
+type ITwoFactorService interface {
+	Setup(userID string) (*TwoFactorSetup, error)
+	Enable(userID, code string) ([]string, error)
+	Disable(userID, code string) error
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	Verify(userID, code string) error
+	Reset(userID string) error
+}
+
+// TwoFactorService 基于 TOTP (RFC 6238) 的两步验证
+type TwoFactorService struct {
+	DB     *gorm.DB
+	Issuer string // 验证器中显示的签发方，默认为站点标题
+}
+
+func NewTwoFactorService(db *gorm.DB) *TwoFactorService {
+	return &TwoFactorService{DB: db}
+}
+
+var _ ITwoFactorService = (*TwoFactorService)(nil)
+
+// Setup 生成新的待验证密钥 (启用前可重复调用，以最后一次为准)
+func (ts *TwoFactorService) Setup(userID string) (*TwoFactorSetup, error) {
+	user, err := findUser(ts.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.DB.Model(user).Updates(map[string]any{"totp_secret": secret, "totp_last_step": 0}).Error; err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(ts.issuer(), user.Username, secret),
+	}, nil
+}
+
+// Enable 校验验证码后启用两步验证，返回一次性恢复码 (仅此一次明文返回)
+func (ts *TwoFactorService) Enable(userID, code string) ([]string, error) {
+	user, err := findUser(ts.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup not started")
+	}
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid verification code")
+	}
+
+	var codes []string
+	err = ts.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Updates(map[string]any{"totp_enabled": true, "totp_last_step": step}).Error
+		if err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// Disable 校验验证码 (或恢复码) 后关闭两步验证
+func (ts *TwoFactorService) Disable(userID, code string) error {
+	if err := ts.Verify(userID, code); err != nil {
+		return err
+	}
+	return ts.Reset(userID)
+}
+
+// RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部失效
+func (ts *TwoFactorService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := ts.Verify(userID, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := ts.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	return codes, err
+}
+
+// Verify 校验验证码或恢复码，同一验证码 / 恢复码只能使用一次
+func (ts *TwoFactorService) Verify(userID, code string) error {
+	user, err := findUser(ts.DB, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return errors.New("invalid verification code")
+		}
+		// 以时间窗口为条件更新，已使用过的验证码 (含并发请求) 不能再次通过
+		result := ts.DB.Model(&model.User{}).
+			Where("id = ? and totp_last_step < ?", user.ID, step).
+			Update("totp_last_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("verification code already used")
+		}
+		return nil
+	}
+
+	result := ts.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? and code_hash = ? and used_at is null", user.ID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invalid verification code")
+	}
+	return nil
+}
+
+// Reset 关闭两步验证并清除密钥与恢复码 (管理员可为丢失设备的用户重置)
+func (ts *TwoFactorService) Reset(userID string) error {
+	user, err := findUser(ts.DB, userID)
+	if err != nil {
+		return err
+	}
+	return ts.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Updates(map[string]any{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.RecoveryCode{}, "user_id = ?", user.ID).Error
+	})
+}
+
+// issuer 未指定时使用站点标题
+func (ts *TwoFactorService) issuer() string {
+	if ts.Issuer != "" {
+		return ts.Issuer
+	}
+	var site model.SiteConfig
+	if err := ts.DB.Select("title").First(&site).Error; err == nil && site.Title != "" {
+		return site.Title
+	}
+	return defaultTOTPIssuer
+}
+
+// replaceRecoveryCodes 删除旧恢复码并生成新的一组，返回明文
+func replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	if err := tx.Delete(&model.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, model.RecoveryCode{UserID: userID, CodeHash: hashToken(normalizeRecoveryCode(code))})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCode 生成形如 xxxxx-xxxxx 的恢复码
+func newRecoveryCode() (string, error) {
+	// 拒绝采样，避免取模偏差
+	limit := byte(256 - 256%len(recoveryAlphabet))
+	out := make([]byte, 0, 10)
+	buf := make([]byte, 16)
+	for len(out) < 10 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, c := range buf {
+			if c < limit && len(out) < 10 {
+				out = append(out, recoveryAlphabet[int(c)%len(recoveryAlphabet)])
+			}
+		}
+	}
+	return string(out[:5]) + "-" + string(out[5:]), nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格与连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"go-blog/model"
+	jwtpkg "go-blog/pkg/jwt"
+	"go-blog/pkg/totp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 初始化内存数据库与 JWT，并创建一个测试用户
+func setupTwoFactorTestDB() (*gorm.DB, *model.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		panic("Failed to open sqlite db: " + err.Error())
+	}
+	db.AutoMigrate(&model.User{}, &model.RecoveryCode{})
+	jwtpkg.Init(&jwtpkg.Config{Algorithm: "HS256", Secret: "test-secret"})
+
+	user := &model.User{Username: "alice", Password: "x", Role: "admin"}
+	db.Create(user)
+	return db, user
+}
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量 (密钥 "12345678901234567890")
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for ts, want := range cases {
+		code, err := totp.Code(secret, totp.Step(time.Unix(ts, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+
+	// 允许前后一个时间窗口的偏差
+	now := time.Unix(1234567890, 0)
+	_, ok := totp.Validate(secret, "005924", now.Add(totp.Period*time.Second))
+	assert.True(t, ok)
+	_, ok = totp.Validate(secret, "005924", now.Add(3*totp.Period*time.Second))
+	assert.False(t, ok)
+}
+
+func TestTwoFactorService_EnableAndVerify(t *testing.T) {
+	db, user := setupTwoFactorTestDB()
+	svc := &TwoFactorService{DB: db, Issuer: "My Blog"}
+
+	// Case 1: 生成密钥与 otpauth 地址，此时尚未启用
+	setup, err := svc.Setup(user.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, setup.URI, "otpauth://totp/My%20Blog:alice?")
+	assert.Contains(t, setup.URI, "secret="+setup.Secret)
+	assert.Error(t, svc.Verify(user.ID, "000000"))
+
+	// Case 2: 错误验证码无法启用
+	_, err = svc.Enable(user.ID, "000000")
+	assert.Error(t, err)
+
+	// Case 3: 正确验证码启用，返回 10 个恢复码，数据库只保存哈希
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(setup.Secret, step)
+	codes, err := svc.Enable(user.ID, code)
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	var stored model.RecoveryCode
+	db.First(&stored)
+	assert.Equal(t, 64, len(stored.CodeHash))
+
+	// Case 4: 启用时使用的验证码不能再次使用
+	assert.Error(t, svc.Verify(user.ID, code))
+
+	// Case 5: 下一个时间窗口的验证码可用，但同一验证码只能用一次
+	next, _ := totp.Code(setup.Secret, step+1)
+	assert.NoError(t, svc.Verify(user.ID, next))
+	assert.Error(t, svc.Verify(user.ID, next))
+
+	// Case 6: 恢复码忽略大小写与连字符，且只能使用一次
+	assert.NoError(t, svc.Verify(user.ID, " "+strings.ToUpper(codes[0])+" "))
+	assert.Error(t, svc.Verify(user.ID, codes[0]))
+
+	// Case 7: 重新生成恢复码后旧恢复码失效
+	fresh, err := svc.RegenerateRecoveryCodes(user.ID, codes[1])
+	assert.NoError(t, err)
+	assert.Error(t, svc.Verify(user.ID, codes[2]))
+	assert.NoError(t, svc.Verify(user.ID, fresh[0]))
+
+	// Case 8: 关闭两步验证后密钥与恢复码被清除
+	assert.NoError(t, svc.Disable(user.ID, fresh[1]))
+	var got model.User
+	db.First(&got, "id = ?", user.ID)
+	assert.False(t, got.TOTPEnabled)
+	assert.Empty(t, got.TOTPSecret)
+	var count int64
+	db.Model(&model.RecoveryCode{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestTwoFactorService_ChallengeToken(t *testing.T) {
+	_, user := setupTwoFactorTestDB()
+
+	challenge, err := jwtpkg.GenerateChallengeToken(user.ID)
+	assert.NoError(t, err)
+
+	// Case 1: 挑战令牌可解析出用户 ID
+	id, err := jwtpkg.ParseChallengeToken(challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, id)
+
+	// Case 2: 挑战令牌不能作为访问令牌使用
+	_, err = jwtpkg.ParseToken(challenge)
+	assert.Error(t, err)
+
+	// Case 3: 访问令牌也不能作为挑战令牌使用
+	access, _ := jwtpkg.GenerateToken(user.ID, user.Username, user.Role, "sid")
+	_, err = jwtpkg.ParseChallengeToken(access)
+	assert.Error(t, err)
+}
@@ -1,21 +1,60 @@
 package service
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"go-blog/model"
 	"go-blog/pkg/crypto"
+	"go-blog/pkg/rbac"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+type UserListReq struct {
+	Page     int
+	PageSize int
+	Keyword  string // 按用户名 / 邮箱 / 昵称模糊搜索
+	Role     string
+}
+
+// AdminBootstrap 初始管理员配置，密码与密码哈希均为空时随机生成
+type AdminBootstrap struct {
+	Username     string
+	Email        string
+	Password     string
+	PasswordHash string // bcrypt 哈希，优先于 Password
+}
+
+// UserProfile 可修改的用户资料 (nil 表示不修改)
+type UserProfile struct {
+	Email       *string
+	DisplayName *string
+	Avatar      *string
+	Bio         *string
+}
+
 // IUserService 定义用户服务接口
 type IUserService interface {
 	AuthenticateUser(username, password string) (*model.User, error)
-	CreateAdminIfNotExists() error
+	CreateAdminIfNotExists(bootstrap *AdminBootstrap) (string, error)
 	ChangePassword(userID, oldPassword, newPassword string) error
-	GetPublicKey() (string, error)
+	GetPublicKey() (*crypto.PublicKeyInfo, error)
+
+	// 个人资料
+	GetUserByID(id string) (*model.User, error)
+	UpdateProfile(id string, profile *UserProfile) error
+
+	// 用户管理 (管理员)
+	CreateUser(user *model.User, password string) error
+	GetUserList(req *UserListReq) ([]model.User, int64, error)
+	SetUserRole(id, role string) error
+	ResetPassword(id, newPassword string) error
+	SetUserDisabled(id string, disabled bool) error
+	DeleteUser(id string) error
 }
 
 type UserService struct {
@@ -40,33 +79,93 @@ func (us *UserService) AuthenticateUser(username, password string) (*model.User,
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return nil, errors.New("invalid credentials")
 	}
+	if user.Disabled {
+		return nil, errors.New("account disabled")
+	}
 	return &user, nil
 }
 
-// CreateAdminIfNotExists 用于初始化默认管理员
-func (us *UserService) CreateAdminIfNotExists() error {
+// CreateAdminIfNotExists 用于初始化管理员，未配置密码时随机生成并返回 (仅在首次创建时返回)
+func (us *UserService) CreateAdminIfNotExists(bootstrap *AdminBootstrap) (string, error) {
 	var count int64
 	if err := us.DB.Model(&model.User{}).Count(&count).Error; err != nil {
-		return err
+		return "", err
 	}
-	if count == 0 {
-		pw := "admin"
-		hash, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if count > 0 {
+		return "", us.flagDefaultPassword()
+	}
+
+	admin := model.User{
+		ID:       uuid.NewString(),
+		Username: bootstrap.Username,
+		Email:    bootstrap.Email,
+		Role:     rbac.RoleAdmin,
+	}
+	if admin.Username == "" {
+		admin.Username = "admin"
+	}
+
+	var generated string
+	switch {
+	case bootstrap.PasswordHash != "":
+		// 直接使用配置的 bcrypt 哈希
+		if _, err := bcrypt.Cost([]byte(bootstrap.PasswordHash)); err != nil {
+			return "", errors.New("invalid admin password hash")
+		}
+		admin.Password = bootstrap.PasswordHash
+	case bootstrap.Password != "":
+		hash, err := bcrypt.GenerateFromPassword([]byte(bootstrap.Password), bcrypt.DefaultCost)
 		if err != nil {
-			return err
+			return "", err
+		}
+		admin.Password = string(hash)
+	default:
+		// 随机密码，首次登录后必须修改
+		pw, err := randomPassword()
+		if err != nil {
+			return "", err
 		}
-		admin := model.User{
-			ID:       uuid.NewString(),
-			Username: "admin",
-			Password: string(hash),
-			Role:     "admin",
+		hash, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+		if err != nil {
+			return "", err
 		}
-		return us.DB.Create(&admin).Error
+		admin.Password = string(hash)
+		admin.MustChangePassword = true
+		generated = pw
 	}
-	return nil
+
+	if err := us.DB.Create(&admin).Error; err != nil {
+		return "", err
+	}
+	return generated, nil
 }
 
-// ChangePassword 修改密码
+// flagDefaultPassword 兼容旧版本：仍在使用默认密码 admin/admin 的账号必须修改密码
+func (us *UserService) flagDefaultPassword() error {
+	var user model.User
+	err := us.DB.Where("username = ? and must_change_password = ?", "admin", false).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("admin")) != nil {
+		return nil
+	}
+	return us.DB.Model(&user).Update("must_change_password", true).Error
+}
+
+// randomPassword 生成 24 位随机密码
+func randomPassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ChangePassword 修改密码，并吊销该用户的全部会话
 func (us *UserService) ChangePassword(userID, oldPassword, newPassword string) error {
 	var user model.User
 	// 查找用户
@@ -78,6 +177,9 @@ func (us *UserService) ChangePassword(userID, oldPassword, newPassword string) e
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
 		return errors.New("incorrect old password")
 	}
+	if oldPassword == newPassword {
+		return errors.New("new password must differ from the old one")
+	}
 
 	// 加密新密码
 	newHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
@@ -85,11 +187,232 @@ func (us *UserService) ChangePassword(userID, oldPassword, newPassword string) e
 		return err
 	}
 
-	// 更新数据库
-	return us.DB.Model(&user).Update("password", string(newHash)).Error
+	// 更新数据库，修改密码前签发的 Token 全部失效
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]any{
+			"password":             string(newHash),
+			"password_changed_at":  time.Now(),
+			"must_change_password": false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, user.ID)
+	})
 }
 
-// GetPublicKey 获取加密公钥
-func (us *UserService) GetPublicKey() (string, error) {
+// GetPublicKey 获取当前登录加密公钥及其 kid
+func (us *UserService) GetPublicKey() (*crypto.PublicKeyInfo, error) {
 	return crypto.GetPublicKey()
 }
+
+// GetUserByID 根据 ID 获取用户
+func (us *UserService) GetUserByID(id string) (*model.User, error) {
+	var user model.User
+	if err := us.DB.First(&user, "id = ?", id).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &user, nil
+}
+
+// UpdateProfile 更新个人资料
+func (us *UserService) UpdateProfile(id string, profile *UserProfile) error {
+	updates := map[string]any{}
+	if profile.Email != nil {
+		updates["email"] = *profile.Email
+	}
+	if profile.DisplayName != nil {
+		updates["display_name"] = *profile.DisplayName
+	}
+	if profile.Avatar != nil {
+		updates["avatar"] = *profile.Avatar
+	}
+	if profile.Bio != nil {
+		updates["bio"] = *profile.Bio
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result := us.DB.Model(&model.User{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// CreateUser 创建用户
+func (us *UserService) CreateUser(user *model.User, password string) error {
+	if !rbac.ValidRole(user.Role) {
+		return errors.New("invalid role")
+	}
+	var count int64
+	if err := us.DB.Model(&model.User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("username already exists")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hash)
+	// 初始密码由管理员设置，首次登录后必须修改
+	user.MustChangePassword = true
+	return us.DB.Create(user).Error
+}
+
+// GetUserList 获取用户列表 (分页)
+func (us *UserService) GetUserList(req *UserListReq) ([]model.User, int64, error) {
+	list := make([]model.User, 0)
+	var total int64
+
+	db := us.DB.Model(&model.User{})
+	if req.Keyword != "" {
+		like := "%" + req.Keyword + "%"
+		db = db.Where("username like ? or email like ? or display_name like ?", like, like, like)
+	}
+	if req.Role != "" {
+		db = db.Where("role = ?", req.Role)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	if err := db.Offset(offset).Limit(req.PageSize).Order("created_at ASC").Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// SetUserRole 设置用户角色 (令牌中的角色随之失效，需刷新)
+func (us *UserService) SetUserRole(id, role string) error {
+	if !rbac.ValidRole(role) {
+		return errors.New("invalid role")
+	}
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, id)
+		if err != nil {
+			return err
+		}
+		if user.Role == rbac.RoleAdmin && role != rbac.RoleAdmin {
+			if err := ensureOtherAdmin(tx, id); err != nil {
+				return err
+			}
+		}
+		return tx.Model(user).Update("role", role).Error
+	})
+}
+
+// ResetPassword 管理员重置密码，吊销该用户的全部会话并要求下次登录后修改
+func (us *UserService) ResetPassword(id, newPassword string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, id)
+		if err != nil {
+			return err
+		}
+		// 管理员设置的临时密码，登录后必须修改
+		err = tx.Model(user).Updates(map[string]any{
+			"password":             string(hash),
+			"password_changed_at":  time.Now(),
+			"must_change_password": true,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, id)
+	})
+}
+
+// SetUserDisabled 禁用 / 启用用户，禁用时吊销其全部会话
+func (us *UserService) SetUserDisabled(id string, disabled bool) error {
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, id)
+		if err != nil {
+			return err
+		}
+		if !disabled {
+			return tx.Model(user).Update("disabled", false).Error
+		}
+		if user.Role == rbac.RoleAdmin {
+			if err := ensureOtherAdmin(tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(user).Update("disabled", true).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, id)
+	})
+}
+
+// DeleteUser 删除用户 (仍有文章时拒绝删除，可改为禁用)
+func (us *UserService) DeleteUser(id string) error {
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := findUser(tx, id)
+		if err != nil {
+			return err
+		}
+		if user.Role == rbac.RoleAdmin {
+			if err := ensureOtherAdmin(tx, id); err != nil {
+				return err
+			}
+		}
+
+		// 回收站中的文章也计入
+		var count int64
+		if err := tx.Unscoped().Model(&model.Post{}).Where("author_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("user still has posts, disable the account instead")
+		}
+
+		if err := tx.Delete(&model.Session{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.RecoveryCode{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.UserIdentity{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.APIToken{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+func findUser(tx *gorm.DB, id string) (*model.User, error) {
+	var user model.User
+	if err := tx.First(&user, "id = ?", id).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &user, nil
+}
+
+// ensureOtherAdmin 确保除指定用户外仍有可用的管理员
+func ensureOtherAdmin(tx *gorm.DB, id string) error {
+	var count int64
+	err := tx.Model(&model.User{}).
+		Where("role = ? and disabled = ? and id <> ?", rbac.RoleAdmin, false, id).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("at least one active admin is required")
+	}
+	return nil
+}
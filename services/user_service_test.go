@@ -1,8 +1,16 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"go-blog/model"
+	"go-blog/pkg/crypto"
 	"testing"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
@@ -19,7 +27,7 @@ func setupUserTestDB() *gorm.DB {
 	if err != nil {
 		panic("Failed to open sqlite db: " + err.Error())
 	}
-	db.AutoMigrate(&model.User{})
+	db.AutoMigrate(&model.User{}, &model.Session{}, &model.RecoveryCode{}, &model.UserIdentity{}, &model.APIToken{}, &model.Post{})
 	return db
 }
 
@@ -27,17 +35,23 @@ func TestUserService_CreateAdminIfNotExists(t *testing.T) {
 	db := setupUserTestDB()
 	svc := NewUserService(db)
 
-	err := svc.CreateAdminIfNotExists()
+	// 未配置密码时随机生成，并要求首次登录后修改
+	password, err := svc.CreateAdminIfNotExists(&AdminBootstrap{})
 	assert.NoError(t, err)
+	assert.Len(t, password, 24)
 
 	// 验证管理员是否存在
 	var admin model.User
 	err = db.Where("role = ?", "admin").First(&admin).Error
 	assert.NoError(t, err)
 	assert.Equal(t, "admin", admin.Username)
+	assert.True(t, admin.MustChangePassword)
+	_, err = svc.AuthenticateUser("admin", password)
+	assert.NoError(t, err)
 
-	err = svc.CreateAdminIfNotExists()
+	password, err = svc.CreateAdminIfNotExists(&AdminBootstrap{})
 	assert.NoError(t, err)
+	assert.Empty(t, password)
 
 	// 验证重复创建
 	var count int64
@@ -45,6 +59,48 @@ func TestUserService_CreateAdminIfNotExists(t *testing.T) {
 	assert.Equal(t, int64(1), count)
 }
 
+func TestUserService_AdminBootstrap(t *testing.T) {
+	// Case 1: 配置明文密码
+	db := setupUserTestDB()
+	svc := NewUserService(db)
+	password, err := svc.CreateAdminIfNotExists(&AdminBootstrap{Username: "root", Email: "root@example.com", Password: "configured"})
+	assert.NoError(t, err)
+	assert.Empty(t, password)
+	u, err := svc.AuthenticateUser("root", "configured")
+	assert.NoError(t, err)
+	assert.Equal(t, "root@example.com", u.Email)
+	assert.False(t, u.MustChangePassword)
+
+	// Case 2: 配置密码哈希
+	db = setupUserTestDB()
+	svc = NewUserService(db)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("hashed-secret"), bcrypt.MinCost)
+	_, err = svc.CreateAdminIfNotExists(&AdminBootstrap{PasswordHash: string(hash)})
+	assert.NoError(t, err)
+	_, err = svc.AuthenticateUser("admin", "hashed-secret")
+	assert.NoError(t, err)
+
+	// Case 3: 非法哈希
+	db = setupUserTestDB()
+	svc = NewUserService(db)
+	_, err = svc.CreateAdminIfNotExists(&AdminBootstrap{PasswordHash: "not-a-hash"})
+	assert.Error(t, err)
+
+	// Case 4: 旧版本遗留的 admin/admin 账号被标记为必须修改密码
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("admin"), bcrypt.MinCost)
+	db.Create(&model.User{Username: "admin", Password: string(legacy), Role: "admin"})
+	_, err = svc.CreateAdminIfNotExists(&AdminBootstrap{})
+	assert.NoError(t, err)
+	u, _ = svc.AuthenticateUser("admin", "admin")
+	assert.True(t, u.MustChangePassword)
+
+	// 修改密码后清除标记，且新密码不能与旧密码相同
+	assert.Error(t, svc.ChangePassword(u.ID, "admin", "admin"))
+	assert.NoError(t, svc.ChangePassword(u.ID, "admin", "new-secret"))
+	u, _ = svc.AuthenticateUser("admin", "new-secret")
+	assert.False(t, u.MustChangePassword)
+}
+
 func TestUserService_AuthenticateUser(t *testing.T) {
 	db := setupUserTestDB()
 	svc := NewUserService(db)
@@ -102,3 +158,150 @@ func TestUserService_ChangePassword(t *testing.T) {
 	err = bcrypt.CompareHashAndPassword([]byte(updatedUser.Password), []byte(newPwd))
 	assert.NoError(t, err)
 }
+
+func TestUserService_Management(t *testing.T) {
+	db := setupUserTestDB()
+	svc := NewUserService(db)
+	svc.CreateAdminIfNotExists(&AdminBootstrap{})
+
+	// Case 1: 创建用户
+	author := &model.User{Username: "writer", Email: "w@example.com", Role: "author"}
+	err := svc.CreateUser(author, "secret123")
+	assert.NoError(t, err)
+	u, err := svc.AuthenticateUser("writer", "secret123")
+	assert.NoError(t, err)
+	assert.Equal(t, "author", u.Role)
+
+	// Case 2: 用户名重复 / 角色非法
+	err = svc.CreateUser(&model.User{Username: "writer", Role: "author"}, "secret123")
+	assert.Error(t, err)
+	err = svc.CreateUser(&model.User{Username: "other", Role: "root"}, "secret123")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid role", err.Error())
+
+	// Case 3: 列表筛选
+	list, total, err := svc.GetUserList(&UserListReq{Page: 1, PageSize: 10, Role: "author"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "writer", list[0].Username)
+
+	// Case 4: 禁用后无法登录，启用后恢复
+	assert.NoError(t, svc.SetUserDisabled(author.ID, true))
+	_, err = svc.AuthenticateUser("writer", "secret123")
+	assert.Error(t, err)
+	assert.Equal(t, "account disabled", err.Error())
+	assert.NoError(t, svc.SetUserDisabled(author.ID, false))
+	_, err = svc.AuthenticateUser("writer", "secret123")
+	assert.NoError(t, err)
+
+	// Case 5: 重置密码
+	assert.NoError(t, svc.ResetPassword(author.ID, "reset456"))
+	_, err = svc.AuthenticateUser("writer", "secret123")
+	assert.Error(t, err)
+	_, err = svc.AuthenticateUser("writer", "reset456")
+	assert.NoError(t, err)
+
+	// Case 6: 不能移除最后一个可用的管理员
+	var admin model.User
+	db.First(&admin, "username = ?", "admin")
+	assert.Error(t, svc.SetUserRole(admin.ID, "editor"))
+	assert.Error(t, svc.SetUserDisabled(admin.ID, true))
+	assert.Error(t, svc.DeleteUser(admin.ID))
+	assert.NoError(t, svc.SetUserRole(author.ID, "admin"))
+	assert.NoError(t, svc.SetUserRole(admin.ID, "editor"))
+
+	// Case 7: 删除用户
+	assert.NoError(t, svc.DeleteUser(admin.ID))
+	_, err = svc.GetUserByID(admin.ID)
+	assert.Error(t, err)
+}
+
+func TestUserService_Profile(t *testing.T) {
+	db := setupUserTestDB()
+	svc := NewUserService(db)
+	user := &model.User{Username: "me", Role: "viewer"}
+	svc.CreateUser(user, "secret123")
+
+	// 只更新传入的字段
+	name, bio := "Me", "Hello"
+	err := svc.UpdateProfile(user.ID, &UserProfile{DisplayName: &name, Bio: &bio})
+	assert.NoError(t, err)
+	u, err := svc.GetUserByID(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Me", u.DisplayName)
+	assert.Equal(t, "Hello", u.Bio)
+	assert.Equal(t, "viewer", u.Role)
+
+	// 允许清空字段
+	empty := ""
+	assert.NoError(t, svc.UpdateProfile(user.ID, &UserProfile{Bio: &empty}))
+	u, _ = svc.GetUserByID(user.ID)
+	assert.Equal(t, "", u.Bio)
+
+	// 不存在的用户
+	assert.Error(t, svc.UpdateProfile("ghost", &UserProfile{Bio: &bio}))
+}
+
+// encryptPassword 模拟前端使用公钥加密密码
+func encryptPassword(t *testing.T, publicKey, password string, oaep bool) string {
+	block, _ := pem.Decode([]byte(publicKey))
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	assert.NoError(t, err)
+	pub := parsed.(*rsa.PublicKey)
+
+	var out []byte
+	if oaep {
+		out, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, []byte(password), nil)
+	} else {
+		out, err = rsa.EncryptPKCS1v15(rand.Reader, pub, []byte(password))
+	}
+	assert.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func TestUserService_LoginKey(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewUserService(setupUserTestDB())
+	assert.NoError(t, crypto.Init(&crypto.Config{Dir: dir, Bits: 1024, RotateInterval: time.Hour}))
+
+	// Case 1: 公钥带 kid，RSA-OAEP 密文可按 kid 或自动匹配解密
+	info, err := svc.GetPublicKey()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, info.KID)
+	assert.Equal(t, crypto.AlgorithmOAEP, info.Algorithm)
+	cipher := encryptPassword(t, info.PublicKey, "secret123", true)
+	plain, err := crypto.Decrypt(cipher, info.KID)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret123", plain)
+	plain, err = crypto.Decrypt(cipher, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret123", plain)
+
+	// Case 2: 未开启兼容模式时拒绝 PKCS#1 v1.5 密文
+	legacy := encryptPassword(t, info.PublicKey, "secret123", false)
+	_, err = crypto.Decrypt(legacy, "")
+	assert.Error(t, err)
+
+	// Case 3: 重启后从密钥目录加载同一密钥
+	assert.NoError(t, crypto.Init(&crypto.Config{Dir: dir, Bits: 1024, RotateInterval: time.Hour, LegacyPKCS1v15: true}))
+	again, _ := svc.GetPublicKey()
+	assert.Equal(t, info.KID, again.KID)
+	plain, err = crypto.Decrypt(legacy, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret123", plain)
+
+	// Case 4: 轮换后旧公钥加密的密文仍可解密，再次轮换后失效
+	assert.NoError(t, crypto.Init(&crypto.Config{Dir: dir, Bits: 1024, RotateInterval: time.Nanosecond}))
+	rotated, _ := svc.GetPublicKey()
+	assert.NotEqual(t, info.KID, rotated.KID)
+	_, err = crypto.Decrypt(cipher, info.KID)
+	assert.NoError(t, err)
+
+	ok, err := crypto.RotateIfDue()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, err = crypto.Decrypt(cipher, info.KID)
+	assert.Error(t, err)
+	_, err = crypto.Decrypt(cipher, "")
+	assert.Error(t, err)
+}